@@ -0,0 +1,29 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+// replicationLagVariable sets the replica lag in seconds from which the header flags replication
+const replicationLagVariable = "DB_REPLICATION_LAG"
+
+// ReplicationLag returns the replica lag in seconds from which the header flags replication, read from
+// DB_REPLICATION_LAG. It is 0, keeping the default, when not set or not a positive number
+func ReplicationLag() int64 {
+	return replicationLagFrom(os.Getenv(replicationLagVariable))
+}
+
+// replicationLagFrom reads a lag in seconds, 0 for an empty or invalid value
+func replicationLagFrom(value string) int64 {
+	if value == "" {
+		return 0
+	}
+	lag, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || lag < 1 {
+		slog.Warn("ignoring invalid replication lag, seconds of at least 1 expected", "variable", replicationLagVariable, "value", value)
+		return 0
+	}
+	return lag
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicationLagFrom(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+	}{
+		{value: "", expected: 0},
+		{value: "1", expected: 1},
+		{value: "30", expected: 30},
+		{value: "0", expected: 0},
+		{value: "5s", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, replicationLagFrom(tt.value))
+		})
+	}
+}
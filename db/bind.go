@@ -0,0 +1,273 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BindParam is a placeholder of a statement, bound to a value when the statement runs
+type BindParam struct {
+	// Name is the placeholder as written, question marks are numbered in order as ?1, ?2
+	Name string
+	// Column is the column the value is compared with or inserted into, empty when unknown
+	Column string
+}
+
+// placeholder is an occurrence of a bind parameter in the statement text,
+// index is that of its first significant token
+type placeholder struct {
+	name       string
+	start, end int
+	index      int
+}
+
+// comparisonOperators precede a value compared with the column before them
+var comparisonOperators = map[string]bool{
+	"=": true, "<>": true, "!=": true, "<": true, ">": true, "<=": true, ">=": true, "<=>": true,
+	"LIKE": true, "BETWEEN": true,
+}
+
+// FindBindParams returns the placeholders of a statement in order of first use: ? for MySQL and SQLite,
+// where Postgres uses it as a JSON operator, $1 and :name for all dialects.
+// Numbered and named placeholders used more than once are a single parameter
+func FindBindParams(dialect string, sql string) []BindParam {
+	tokens := significantTokens(Tokenize(sql))
+	var params []BindParam
+	seen := map[string]bool{}
+	for _, found := range findPlaceholders(dialect, tokens) {
+		if seen[found.name] {
+			continue
+		}
+		seen[found.name] = true
+		params = append(params, BindParam{Name: found.name, Column: bindColumn(tokens, found.index)})
+	}
+	return params
+}
+
+// BindSQL rewrites the placeholders of a statement into those of the driver, ? for MySQL and SQLite
+// and $1 for Postgres, and returns the arguments to run it with. values holds the value of every
+// parameter returned by FindBindParams, NULL in any case binds a null
+func BindSQL(dialect string, sql string, values map[string]string) (string, []any) {
+	tokens := significantTokens(Tokenize(sql))
+	var b strings.Builder
+	var args []any
+	numbers := map[string]int{}
+	last := 0
+	for _, found := range findPlaceholders(dialect, tokens) {
+		b.WriteString(sql[last:found.start])
+		last = found.end
+
+		if dialect != DialectPostgres {
+			b.WriteString("?")
+			args = append(args, BindValue(values[found.name]))
+			continue
+		}
+		number, ok := numbers[found.name]
+		if !ok {
+			args = append(args, BindValue(values[found.name]))
+			number = len(args)
+			numbers[found.name] = number
+		}
+		fmt.Fprintf(&b, "$%d", number)
+	}
+	b.WriteString(sql[last:])
+	return b.String(), args
+}
+
+// BindValue converts a typed value into a driver argument, NULL in any case is a null
+// and everything else is passed as text for the server to convert
+func BindValue(value string) any {
+	if strings.EqualFold(strings.TrimSpace(value), "NULL") {
+		return nil
+	}
+	return value
+}
+
+// ReferencedTables returns the tables named after FROM, JOIN, UPDATE and INTO, without schema qualifiers
+func ReferencedTables(sql string) []string {
+	tokens := significantTokens(Tokenize(sql))
+	var tables []string
+	seen := map[string]bool{}
+	for i, token := range tokens {
+		if token.Kind != TokenKeyword || i+1 == len(tokens) {
+			continue
+		}
+		switch strings.ToUpper(token.Text) {
+		case "FROM", "JOIN", "UPDATE", "INTO":
+		default:
+			continue
+		}
+		end := i + 1
+		for end+2 < len(tokens) && tokens[end+1].Text == "." {
+			end += 2
+		}
+		name, ok := identifierName(tokens[end])
+		if ok && !seen[name] {
+			seen[name] = true
+			tables = append(tables, name)
+		}
+	}
+	return tables
+}
+
+// significantTokens drops whitespace and comments. Operators running into a colon, such as =: in id=:id,
+// are split so the colon can start a named placeholder
+func significantTokens(tokens []Token) []Token {
+	var significant []Token
+	for _, token := range tokens {
+		switch {
+		case token.Kind == TokenWhitespace || token.Kind == TokenComment:
+		case token.Kind == TokenOperator && len(token.Text) > 1 && strings.HasSuffix(token.Text, ":") && !strings.HasSuffix(token.Text, "::"):
+			colon := token.End - 1
+			significant = append(significant,
+				Token{Kind: TokenOperator, Text: token.Text[:len(token.Text)-1], Start: token.Start, End: colon},
+				Token{Kind: TokenOperator, Text: ":", Start: colon, End: token.End})
+		default:
+			significant = append(significant, token)
+		}
+	}
+	return significant
+}
+
+// findPlaceholders returns every placeholder occurrence in significant tokens
+func findPlaceholders(dialect string, tokens []Token) []placeholder {
+	var found []placeholder
+	questionMarks := 0
+	for i, token := range tokens {
+		switch {
+		case token.Kind == TokenOperator && token.Text == "?" && dialect != DialectPostgres:
+			questionMarks++
+			found = append(found, placeholder{name: fmt.Sprintf("?%d", questionMarks), start: token.Start, end: token.End, index: i})
+		case token.Kind == TokenIdentifier && isNumberedPlaceholder(token.Text):
+			found = append(found, placeholder{name: token.Text, start: token.Start, end: token.End, index: i})
+		case isNamedPlaceholder(tokens, i):
+			found = append(found, placeholder{name: ":" + tokens[i+1].Text, start: token.Start, end: tokens[i+1].End, index: i})
+		}
+	}
+	return found
+}
+
+// isNumberedPlaceholder reports whether text is a placeholder such as $1
+func isNumberedPlaceholder(text string) bool {
+	if len(text) < 2 || text[0] != '$' {
+		return false
+	}
+	for i := 1; i < len(text); i++ {
+		if !isDigit(text[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNamedPlaceholder reports whether a :name placeholder starts at token i. The colon is directly followed
+// by the name and does not directly follow a value, which keeps slices such as a[1:n] and labels apart
+func isNamedPlaceholder(tokens []Token, i int) bool {
+	if tokens[i].Kind != TokenOperator || tokens[i].Text != ":" || i+1 == len(tokens) {
+		return false
+	}
+	name := tokens[i+1]
+	if name.Start != tokens[i].End || !isWordKind(name.Kind) {
+		return false
+	}
+	if i > 0 && tokens[i-1].End == tokens[i].Start {
+		switch tokens[i-1].Kind {
+		case TokenOperator, TokenPunctuation:
+			return tokens[i-1].Text != ")" && tokens[i-1].Text != "]"
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// bindColumn returns the column a placeholder at significant token i is compared with, such as id in id = ?,
+// or inserted into, such as b in INSERT INTO t (a, b) VALUES (?, ?)
+func bindColumn(tokens []Token, i int) string {
+	if i == 0 {
+		return ""
+	}
+	previous := strings.ToUpper(tokens[i-1].Text)
+	if comparisonOperators[previous] && i >= 2 {
+		name, _ := identifierName(tokens[i-2])
+		return name
+	}
+	if previous != "(" && previous != "," {
+		return ""
+	}
+
+	// find the opening bracket of the list and the position of the placeholder in it
+	position := 0
+	depth := 0
+	open := -1
+	for j := i - 1; j >= 0 && open < 0; j-- {
+		switch tokens[j].Text {
+		case ")":
+			depth++
+		case "(":
+			if depth == 0 {
+				open = j
+			}
+			depth--
+		case ",":
+			if depth == 0 {
+				position++
+			}
+		}
+	}
+	if open < 1 {
+		return ""
+	}
+
+	switch strings.ToUpper(tokens[open-1].Text) {
+	case "IN":
+		if open >= 2 {
+			name, _ := identifierName(tokens[open-2])
+			return name
+		}
+	case "VALUES":
+		columns := insertColumns(tokens[:open-1])
+		if position < len(columns) {
+			return columns[position]
+		}
+	}
+	return ""
+}
+
+// insertColumns returns the column list ending the tokens before VALUES
+func insertColumns(tokens []Token) []string {
+	if len(tokens) == 0 || tokens[len(tokens)-1].Text != ")" {
+		return nil
+	}
+	var columns []string
+	for j := len(tokens) - 2; j >= 0; j-- {
+		switch tokens[j].Text {
+		case "(":
+			return columns
+		case ",":
+		default:
+			name, ok := identifierName(tokens[j])
+			if !ok {
+				return nil
+			}
+			columns = append([]string{name}, columns...)
+		}
+	}
+	return nil
+}
+
+// identifierName returns the name of a word or quoted identifier token
+func identifierName(token Token) (string, bool) {
+	switch {
+	case token.Kind == TokenQuotedIdentifier || token.Kind == TokenString && strings.HasPrefix(token.Text, `"`):
+		return strings.Trim(token.Text, "`\""), true
+	case isWordKind(token.Kind):
+		return token.Text, true
+	}
+	return "", false
+}
+
+// isWordKind reports whether a token kind is a word, names such as status or date may be keywords or types
+func isWordKind(kind TokenKind) bool {
+	return kind == TokenIdentifier || kind == TokenKeyword || kind == TokenDataType || kind == TokenFunction
+}
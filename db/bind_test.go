@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindBindParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  string
+		sql      string
+		expected []BindParam
+	}{
+		{
+			name:     "question marks",
+			dialect:  DialectMysql,
+			sql:      "SELECT * FROM users WHERE id = ? AND name LIKE ?",
+			expected: []BindParam{{Name: "?1", Column: "id"}, {Name: "?2", Column: "name"}},
+		},
+		{
+			name:     "question marks are JSON operators in postgres",
+			dialect:  DialectPostgres,
+			sql:      "SELECT * FROM docs WHERE body ? 'key' AND id = $1",
+			expected: []BindParam{{Name: "$1", Column: "id"}},
+		},
+		{
+			name:     "numbered placeholders used twice",
+			dialect:  DialectPostgres,
+			sql:      "SELECT * FROM orders WHERE user_id = $2 OR seller_id = $2 OR id > $1",
+			expected: []BindParam{{Name: "$2", Column: "user_id"}, {Name: "$1", Column: "id"}},
+		},
+		{
+			name:     "named placeholders",
+			dialect:  DialectSqlite,
+			sql:      "UPDATE users SET name=:name WHERE o.`id` = :id AND :id > 0",
+			expected: []BindParam{{Name: ":name", Column: "name"}, {Name: ":id", Column: "id"}},
+		},
+		{
+			name:     "in lists and inserts",
+			dialect:  DialectMysql,
+			sql:      "INSERT INTO users (id, name, created_at) VALUES (?, UPPER(?), NOW()); -- ? in a comment",
+			expected: []BindParam{{Name: "?1", Column: "id"}, {Name: "?2"}},
+		},
+		{
+			name:     "in list",
+			dialect:  DialectMysql,
+			sql:      "DELETE FROM users WHERE status IN (?, ?)",
+			expected: []BindParam{{Name: "?1", Column: "status"}, {Name: "?2", Column: "status"}},
+		},
+		{
+			name:    "not placeholders",
+			dialect: DialectPostgres,
+			sql:     "SELECT a[1:n], '?', ':x', \"$1\", b::int, @v := 1 FROM t -- :y\nWHERE c = $$:z$$",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FindBindParams(tt.dialect, tt.sql))
+		})
+	}
+}
+
+func TestBindSQL(t *testing.T) {
+	values := map[string]string{"?1": "1", "?2": "null", "$1": "a", "$2": "b", ":id": "7", ":name": "Ann"}
+
+	tests := []struct {
+		name         string
+		dialect      string
+		sql          string
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			name:         "question marks stay",
+			dialect:      DialectMysql,
+			sql:          "SELECT * FROM t WHERE a = ? AND b = ?",
+			expectedSQL:  "SELECT * FROM t WHERE a = ? AND b = ?",
+			expectedArgs: []any{"1", nil},
+		},
+		{
+			name:         "numbers and names become question marks",
+			dialect:      DialectSqlite,
+			sql:          "SELECT * FROM t WHERE a = $2 OR b = $2 OR c=:id",
+			expectedSQL:  "SELECT * FROM t WHERE a = ? OR b = ? OR c=?",
+			expectedArgs: []any{"b", "b", "7"},
+		},
+		{
+			name:         "postgres numbers parameters in order of first use",
+			dialect:      DialectPostgres,
+			sql:          "UPDATE t SET name = :name WHERE id = :id OR parent = :id",
+			expectedSQL:  "UPDATE t SET name = $1 WHERE id = $2 OR parent = $2",
+			expectedArgs: []any{"Ann", "7"},
+		},
+		{
+			name:        "without placeholders",
+			dialect:     DialectMysql,
+			sql:         "SELECT ':id'",
+			expectedSQL: "SELECT ':id'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := BindSQL(tt.dialect, tt.sql, values)
+			assert.Equal(t, tt.expectedSQL, sql)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestReferencedTables(t *testing.T) {
+	assert.Equal(t, []string{"orders", "users"},
+		ReferencedTables("SELECT * FROM shop.orders o JOIN `users` u ON u.id = o.user_id WHERE o.id IN (SELECT id FROM orders)"))
+	assert.Equal(t, []string{"users"}, ReferencedTables("INSERT INTO users (id) VALUES (?)"))
+	assert.Equal(t, []string{"users"}, ReferencedTables("UPDATE users SET name = ?"))
+	assert.Nil(t, ReferencedTables("SELECT 1"))
+}
+
+func TestExecuteSqlWithArgs(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SELECT name FROM users WHERE id = \\?").WithArgs("42").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Ann"))
+	mock.ExpectExec("UPDATE users SET name = \\? WHERE id = \\?").WithArgs(nil, "42").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+	ctx := context.Background()
+
+	result := mysql.ExecuteSql(ctx, "SELECT name FROM users WHERE id = ?", "42")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []TableData{map[string]string{"name": "Ann"}}, result.Data)
+
+	result = mysql.ExecuteSql(ctx, "UPDATE users SET name = ? WHERE id = ?", nil, "42")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, int64(1), result.RowsAffected)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,17 +4,50 @@ import (
 	"context"
 	"database/sql"
 	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 type DatabaseServer interface {
 	Db() *sql.DB
+	Dialect() string
+	ConnectionName() string
 	FetchTableDescr(ctx context.Context, name string) string
 	FetchTableRows(ctx context.Context, name string) ([]string, []TableData)
-	FetchSqlRows(ctx context.Context, SQL string) ([]string, []TableData)
+	FetchSqlRows(ctx context.Context, SQL string, args ...any) ([]string, []TableData)
+	ExecuteSql(ctx context.Context, statement string, args ...any) StatementResult
+	ExecuteScript(ctx context.Context, statements []string, progress func(done int)) []StatementResult
+	StreamSqlRows(ctx context.Context, SQL string, onColumns func(columns []string, types []string) error, onRow func(values []*string) error, args ...any) error
+	FetchPlan(ctx context.Context, statement string, analyze bool, args ...any) (PlanNode, error)
+	FetchSchema(ctx context.Context) ([]SchemaTable, error)
+	FetchProcesses(ctx context.Context) ([]string, []TableData)
+	KillSession(ctx context.Context, id string) error
+	FetchLockWaits(ctx context.Context) ([]LockWait, error)
+	FetchStatus(ctx context.Context) ([]ServerValue, error)
+	FetchVariables(ctx context.Context) ([]ServerValue, error)
+	FetchTableSizes(ctx context.Context) ([]TableSize, error)
+	FetchUnusedIndexes(ctx context.Context) ([]UnusedIndex, error)
+	FetchStatementDigests(ctx context.Context, limit int) ([]StatementDigest, error)
+	FetchUsers(ctx context.Context) ([]DatabaseUser, error)
+	FetchGrants(ctx context.Context, user DatabaseUser) ([]string, error)
+	FetchTableAccess(ctx context.Context, table string) ([]TableAccess, error)
+	FetchReplication(ctx context.Context) ([]ReplicationLink, error)
 	FetchDatabases(ctx context.Context) ([]string, []TableData)
 	FetchTables(ctx context.Context) ([]string, []TableData)
+	FetchTableColumns(ctx context.Context, name string) ([]string, []TableData)
+	FetchTableIndexes(ctx context.Context, name string) ([]string, []TableData)
+	FetchTableForeignKeys(ctx context.Context, name string) ([]string, []TableData)
+	FetchTableChecks(ctx context.Context, name string) ([]string, []TableData)
+	FetchTableTriggers(ctx context.Context, name string) ([]string, []TableData)
+	FetchViews(ctx context.Context) ([]string, []TableData)
+	FetchRoutines(ctx context.Context, kind string) ([]string, []TableData)
+	FetchTriggers(ctx context.Context) ([]string, []TableData)
+	FetchEvents(ctx context.Context) ([]string, []TableData)
+	FetchSequences(ctx context.Context) ([]string, []TableData)
+	FetchObjectDefinition(ctx context.Context, kind string, name string) string
+	ImportRows(ctx context.Context, request ImportRequest, progress func(done int)) (int, error)
 }
 
 func Connect(connStr string, useMock bool) DatabaseServer {
@@ -39,7 +72,43 @@ func Connect(connStr string, useMock bool) DatabaseServer {
 		slog.Info("Successfully connected to database")
 	}
 
-	return &Mysql8{Mysql{DbInstance: db}}
+	return &Mysql8{Mysql{DbInstance: db, DriverName: driver, Name: connectionName(connStr)}}
+}
+
+// Open connects to another database, such as a profile compared with the current connection,
+// and fails when it can't be reached before ctx is done
+func Open(ctx context.Context, connStr string) (DatabaseServer, error) {
+	driver := determineDriver(connStr)
+	db, err := sql.Open(driver, connStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	slog.Info("Connected to database", "driver", driver, "connection", connectionName(connStr))
+	return &Mysql8{Mysql{DbInstance: db, DriverName: driver, Name: connectionName(connStr)}}, nil
+}
+
+// connectionName identifies a connection by host and database without its credentials,
+// such as localhost:3306/shop, or by file name for SQLite
+func connectionName(connStr string) string {
+	if u, err := url.Parse(connStr); err == nil && u.Host != "" {
+		return u.Host + u.Path
+	}
+	if at := strings.LastIndex(connStr, "@"); at >= 0 {
+		// user:password@tcp(host:port)/database?options
+		address, _, _ := strings.Cut(connStr[at+1:], "?")
+		if open := strings.Index(address, "("); open >= 0 {
+			if end := strings.Index(address, ")"); end > open {
+				address = address[open+1:end] + address[end+1:]
+			}
+		}
+		return address
+	}
+	path, _, _ := strings.Cut(strings.TrimPrefix(connStr, "file:"), "?")
+	return filepath.Base(path)
 }
 
 func determineDriver(connStr string) string {
@@ -0,0 +1,571 @@
+package db
+
+import (
+	"strings"
+)
+
+// FormatOptions controls how PrettyPrintSQL lays out SQL
+type FormatOptions struct {
+	// UppercaseKeywords writes keywords, data types and known functions in upper case
+	UppercaseKeywords bool
+}
+
+// formatIndent is one level of indentation
+const formatIndent = "  "
+
+// clausePhrases start a clause on a line of its own, phrases sharing a first word longest first
+var clausePhrases = [][]string{
+	{"ON", "DUPLICATE", "KEY", "UPDATE"},
+	{"INSERT", "INTO"}, {"REPLACE", "INTO"}, {"DELETE", "FROM"},
+	{"GROUP", "BY"}, {"ORDER", "BY"}, {"UNION", "ALL"},
+	{"SELECT"}, {"FROM"}, {"WHERE"}, {"HAVING"}, {"LIMIT"}, {"OFFSET"},
+	{"UNION"}, {"INTERSECT"}, {"EXCEPT"}, {"VALUES"}, {"SET"}, {"UPDATE"},
+	{"WINDOW"}, {"RETURNING"},
+}
+
+// statementClauses are clauses only as the first word of a statement,
+// elsewhere they are part of phrases such as BEFORE UPDATE or FOR UPDATE
+var statementClauses = map[string]bool{"INSERT": true, "REPLACE": true, "DELETE": true, "UPDATE": true}
+
+// selectListEnds are the words ending the column list of a SELECT
+var selectListEnds = map[string]bool{
+	"FROM": true, "INTO": true, "WHERE": true, "GROUP": true, "ORDER": true, "HAVING": true,
+	"LIMIT": true, "UNION": true, "INTERSECT": true, "EXCEPT": true, "WINDOW": true,
+}
+
+// joinWords may precede JOIN in a join clause
+var joinWords = map[string]bool{
+	"LEFT": true, "RIGHT": true, "FULL": true, "INNER": true, "OUTER": true, "CROSS": true, "NATURAL": true,
+}
+
+// compoundEnds follow END when closing a compound statement, as in END IF
+var compoundEnds = map[string]bool{"IF": true, "LOOP": true, "WHILE": true, "REPEAT": true, "CASE": true}
+
+// formatBlock is an open parenthesis, CASE expression or compound statement with the clause
+// state to restore once it closes
+type formatBlock struct {
+	kind string
+	// indentation of the line opening the block
+	indent int
+	base   int
+	clause string
+	list   bool
+	verb   string
+}
+
+// formatter lays out the significant tokens of SQL text
+type formatter struct {
+	options FormatOptions
+	tokens  []Token
+	i       int
+	out     strings.Builder
+
+	// last two tokens written, for spacing
+	prev, prevPrev Token
+	lineStart      bool
+	lineIndent     int
+	// a blank line separates top level statements
+	blank bool
+
+	blocks []formatBlock
+	// indentation of clauses at the current level
+	base int
+	// clause being written and whether its commas break lines
+	clause string
+	list   bool
+	// first word of the current statement
+	verb           string
+	statementStart bool
+	between        bool
+}
+
+// PrettyPrintSQL lays out SQL with clauses on lines of their own, one selected column per line and
+// indented subqueries, CASE expressions and compound statement bodies. Comments are kept,
+// statements ending with ; are separated by a blank line
+func PrettyPrintSQL(sql string, options FormatOptions) string {
+	f := &formatter{options: options, lineStart: true, statementStart: true}
+	for _, token := range Tokenize(sql) {
+		if token.Kind != TokenWhitespace {
+			f.tokens = append(f.tokens, token)
+		}
+	}
+	for f.i = 0; f.i < len(f.tokens); f.i++ {
+		f.format()
+	}
+	return strings.TrimRight(f.out.String(), "\n")
+}
+
+// PrettyPrintScript formats each statement of a script, keeping DELIMITER commands, comments between
+// statements and the delimiter ending each statement
+func PrettyPrintScript(script string, options FormatOptions) string {
+	var parts []string
+	previousEnd := 0
+	for _, statement := range SplitStatements(script) {
+		// DELIMITER commands and comments go on the lines right above the statement following them
+		var part, between strings.Builder
+		for _, line := range strings.Split(script[previousEnd:statement.Start], "\n") {
+			if _, _, ok := delimiterCommand(strings.TrimSpace(line), 0); ok {
+				writeComments(&part, between.String())
+				between.Reset()
+				part.WriteString(strings.TrimSpace(line) + "\n")
+			} else {
+				between.WriteString(line + "\n")
+			}
+		}
+		writeComments(&part, between.String())
+		delimiter := strings.TrimSpace(script[statement.Start+len(statement.Text) : statement.End])
+		part.WriteString(PrettyPrintSQL(statement.Text, options) + delimiter)
+		parts = append(parts, part.String())
+		previousEnd = statement.End
+	}
+	if rest := strings.TrimSpace(script[previousEnd:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// writeComments writes the comments of text between statements, each on lines of its own
+func writeComments(b *strings.Builder, text string) {
+	for _, token := range Tokenize(text) {
+		if token.Kind == TokenComment {
+			b.WriteString(strings.TrimSpace(token.Text) + "\n")
+		}
+	}
+}
+
+// format writes the token at f.i, consuming following tokens of multi word phrases
+func (f *formatter) format() {
+	token := f.tokens[f.i]
+	start := f.statementStart
+	if token.Kind == TokenComment {
+		f.emit(token)
+		if !strings.HasPrefix(token.Text, "/*") {
+			f.newline(f.lineIndent)
+		}
+		return
+	}
+	f.statementStart = false
+
+	switch token.Text {
+	case ";":
+		f.endStatement()
+		return
+	case "(":
+		f.openParen()
+		return
+	case ")":
+		f.closeParen()
+		return
+	case ",":
+		f.emit(token)
+		if f.list && f.atClauseLevel() {
+			f.newline(f.base + 1)
+		}
+		return
+	}
+
+	word := f.word(f.i)
+	if start && word != "" {
+		f.verb = word
+	}
+	if word == "" || !f.formatWord(word, start) {
+		f.emit(token)
+	}
+}
+
+// formatWord lays out clause, condition, CASE and compound statement words,
+// it reports false for words written like any other token
+func (f *formatter) formatWord(word string, start bool) bool {
+	top := f.top()
+	label := f.prev.Text == ":"
+
+	if phrase := f.clauseAt(f.i, start); phrase > 0 {
+		f.startClause(phrase)
+		return true
+	}
+	if phrase := f.joinAt(f.i); phrase > 0 && f.atClauseLevel() {
+		f.newline(f.base)
+		f.emitWords(phrase)
+		f.clause, f.list = "JOIN", false
+		return true
+	}
+
+	switch word {
+	case "BETWEEN":
+		f.between = true
+	case "AND", "OR":
+		if word == "AND" && f.between {
+			f.between = false
+			return false
+		}
+		if f.atClauseLevel() && (f.clause == "WHERE" || f.clause == "HAVING" || f.clause == "JOIN") {
+			f.newline(f.base + 1)
+			f.emitWords(1)
+			return true
+		}
+	case "CASE":
+		f.emitWords(1)
+		f.push("CASE")
+		return true
+	case "WHEN":
+		if top != nil && top.kind == "CASE" {
+			f.newline(top.indent + 1)
+			f.emitWords(1)
+			return true
+		}
+	case "THEN":
+		if top != nil && top.kind == "IF" {
+			f.emitWords(1)
+			f.startBody(top)
+			return true
+		}
+	case "ELSEIF":
+		if top != nil && top.kind == "IF" {
+			f.newline(top.indent)
+			f.emitWords(1)
+			return true
+		}
+	case "ELSE":
+		if top != nil && top.kind == "CASE" {
+			f.newline(top.indent + 1)
+			f.emitWords(1)
+			return true
+		}
+		if top != nil && top.kind == "IF" {
+			f.newline(top.indent)
+			f.emitWords(1)
+			f.startBody(top)
+			return true
+		}
+	case "UNTIL":
+		if top != nil && top.kind == "REPEAT" {
+			f.newline(top.indent)
+			f.emitWords(1)
+			return true
+		}
+	case "END":
+		return f.endBlock()
+	case "IF", "WHILE":
+		if (start || label) && f.tokens[f.i].Kind != TokenFunction {
+			f.emitWords(1)
+			f.push(word)
+			return true
+		}
+	case "LOOP", "REPEAT":
+		if (start || label) && f.tokens[f.i].Kind != TokenFunction {
+			f.emitWords(1)
+			f.startBody(f.push(word))
+			return true
+		}
+	case "BEGIN":
+		// BEGIN; and BEGIN WORK start a transaction rather than a block
+		if next := f.word(f.i + 1); f.i+1 < len(f.tokens) && f.tokens[f.i+1].Text != ";" && next != "WORK" && next != "TRANSACTION" {
+			f.emitWords(1)
+			f.startBody(f.push(word))
+			return true
+		}
+	case "DO":
+		f.emitWords(1)
+		if top != nil && top.kind == "WHILE" {
+			f.startBody(top)
+		} else {
+			// the body of an event
+			f.statementStart = true
+		}
+		return true
+	case "ROW":
+		// FOR EACH ROW precedes the body of a trigger
+		if f.word(f.i-1) == "EACH" {
+			f.emitWords(1)
+			f.statementStart = true
+			return true
+		}
+	}
+	return false
+}
+
+// clauseAt returns the number of words of a clause phrase at i, or 0 when none starts there
+func (f *formatter) clauseAt(i int, start bool) int {
+	if !f.atClauseLevel() || f.tokens[i].Kind == TokenFunction {
+		return 0
+	}
+	for _, phrase := range clausePhrases {
+		if !f.matchWords(i, phrase) {
+			continue
+		}
+		switch {
+		case statementClauses[phrase[0]] && !start:
+			continue
+		case phrase[0] == "SET" && !start && f.verb != "UPDATE" && f.verb != "INSERT" && f.verb != "REPLACE":
+			return 0
+		case phrase[0] == "VALUES" && i > 0 && (f.tokens[i-1].Kind == TokenOperator || f.tokens[i-1].Text == "," || f.tokens[i-1].Text == "("):
+			// VALUES(column) in ON DUPLICATE KEY UPDATE
+			return 0
+		}
+		return len(phrase)
+	}
+	return 0
+}
+
+// joinAt returns the number of words of a join phrase such as LEFT OUTER JOIN at i, or 0
+func (f *formatter) joinAt(i int) int {
+	for n := 0; i+n < len(f.tokens); n++ {
+		word := f.word(i + n)
+		switch {
+		case word == "JOIN" || word == "STRAIGHT_JOIN":
+			return n + 1
+		case !joinWords[word] || f.tokens[i+n].Kind == TokenFunction:
+			return 0
+		}
+	}
+	return 0
+}
+
+// startClause writes a clause phrase on a new line, a SELECT of several columns lists them one per line
+func (f *formatter) startClause(phrase int) {
+	f.newline(f.base)
+	f.clause = f.word(f.i)
+	f.list = false
+	f.between = false
+	f.emitWords(phrase)
+
+	if f.clause != "SELECT" {
+		return
+	}
+	for next := f.word(f.i + 1); next == "DISTINCT" || next == "ALL"; next = f.word(f.i + 1) {
+		f.i++
+		f.emitWords(1)
+	}
+	if f.selectHasList() {
+		f.list = true
+		f.newline(f.base + 1)
+	}
+}
+
+// selectHasList reports whether the SELECT list following f.i has more than one column
+func (f *formatter) selectHasList() bool {
+	depth := 0
+	for i := f.i + 1; i < len(f.tokens); i++ {
+		switch f.tokens[i].Text {
+		case "(":
+			depth++
+		case ")":
+			if depth--; depth < 0 {
+				return false
+			}
+		case ";":
+			return false
+		case ",":
+			if depth == 0 {
+				return true
+			}
+		default:
+			if depth == 0 && selectListEnds[f.word(i)] {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// openParen opens a subquery on the following lines or a parenthesis kept on the line
+func (f *formatter) openParen() {
+	f.emit(f.tokens[f.i])
+	next := f.word(f.i + 1)
+	if next != "SELECT" && next != "WITH" {
+		f.push("(")
+		return
+	}
+	f.push("subquery")
+	f.base = f.lineIndent + 1
+	f.clause, f.list = "", false
+	f.newline(f.base)
+}
+
+// closeParen closes the innermost parenthesis along with CASE expressions left open in it
+func (f *formatter) closeParen() {
+	for len(f.blocks) > 0 {
+		block := f.pop()
+		if block.kind == "subquery" {
+			f.newline(block.indent)
+		}
+		if block.kind == "(" || block.kind == "subquery" {
+			break
+		}
+	}
+	f.emit(f.tokens[f.i])
+}
+
+// endStatement writes ; and starts the next statement, inside a compound statement on the next line of its body
+func (f *formatter) endStatement() {
+	f.emit(f.tokens[f.i])
+	for len(f.blocks) > 0 && (f.top().kind == "(" || f.top().kind == "subquery") {
+		f.pop()
+	}
+	f.clause, f.list, f.between = "", false, false
+	f.statementStart = true
+
+	switch top := f.top(); {
+	case top == nil:
+		f.base = 0
+		f.newline(0)
+		f.blank = true
+	case top.kind == "CASE":
+		// a CASE statement of a routine
+		f.newline(top.indent + 2)
+	default:
+		f.newline(f.base)
+	}
+}
+
+// endBlock writes END closing a CASE expression or compound statement with the word naming it, as in END IF
+func (f *formatter) endBlock() bool {
+	top := f.top()
+	if top == nil || top.kind == "(" || top.kind == "subquery" {
+		return false
+	}
+	f.newline(top.indent)
+	f.emitWords(1)
+	kind := f.pop().kind
+	if next := f.word(f.i + 1); kind == "CASE" && next == "CASE" || kind != "CASE" && compoundEnds[next] {
+		f.i++
+		f.emitWords(1)
+	}
+	return true
+}
+
+// startBody continues a compound statement with its body on the following lines
+func (f *formatter) startBody(block *formatBlock) {
+	f.base = block.indent + 1
+	f.clause, f.list = "", false
+	f.statementStart = true
+	f.newline(f.base)
+}
+
+// atClauseLevel reports whether clause words and commas are outside parentheses and CASE expressions
+func (f *formatter) atClauseLevel() bool {
+	top := f.top()
+	return top == nil || top.kind != "(" && top.kind != "CASE"
+}
+
+func (f *formatter) push(kind string) *formatBlock {
+	f.blocks = append(f.blocks, formatBlock{
+		kind:   kind,
+		indent: f.lineIndent,
+		base:   f.base,
+		clause: f.clause,
+		list:   f.list,
+		verb:   f.verb,
+	})
+	return f.top()
+}
+
+// pop closes the innermost block, subqueries and compound statements restore the clause state around them
+func (f *formatter) pop() formatBlock {
+	block := f.blocks[len(f.blocks)-1]
+	f.blocks = f.blocks[:len(f.blocks)-1]
+	if block.kind != "(" && block.kind != "CASE" {
+		f.base, f.clause, f.list, f.verb = block.base, block.clause, block.list, block.verb
+	}
+	return block
+}
+
+func (f *formatter) top() *formatBlock {
+	if len(f.blocks) == 0 {
+		return nil
+	}
+	return &f.blocks[len(f.blocks)-1]
+}
+
+// word returns the upper case text of an unquoted word at i, empty for other tokens and qualified names
+func (f *formatter) word(i int) string {
+	if i < 0 || i >= len(f.tokens) {
+		return ""
+	}
+	switch f.tokens[i].Kind {
+	case TokenKeyword, TokenDataType, TokenFunction, TokenIdentifier:
+	default:
+		return ""
+	}
+	if i > 0 && f.tokens[i-1].Text == "." {
+		return ""
+	}
+	return strings.ToUpper(f.tokens[i].Text)
+}
+
+// matchWords reports whether the words starting at i are phrase
+func (f *formatter) matchWords(i int, phrase []string) bool {
+	for n, word := range phrase {
+		if f.word(i+n) != word {
+			return false
+		}
+	}
+	return true
+}
+
+// emitWords writes count words starting at f.i, leaving f.i on the last one
+func (f *formatter) emitWords(count int) {
+	for n := 0; n < count; n++ {
+		if n > 0 {
+			f.i++
+		}
+		f.emit(f.tokens[f.i])
+	}
+}
+
+// newline starts a new line at the given indentation, an empty current line is reused
+func (f *formatter) newline(indent int) {
+	if !f.lineStart {
+		f.out.WriteByte('\n')
+		f.lineStart = true
+	}
+	f.lineIndent = indent
+}
+
+// emit writes a token, preceded by indentation at the start of a line or a space where one belongs
+func (f *formatter) emit(token Token) {
+	if f.lineStart {
+		if f.blank && f.out.Len() > 0 {
+			f.out.WriteByte('\n')
+		}
+		f.blank = false
+		f.out.WriteString(strings.Repeat(formatIndent, f.lineIndent))
+	} else if f.spaceBefore(token) {
+		f.out.WriteByte(' ')
+	}
+
+	text := token.Text
+	if f.options.UppercaseKeywords && (token.Kind == TokenKeyword || token.Kind == TokenDataType || token.Kind == TokenFunction) {
+		text = strings.ToUpper(text)
+	}
+	f.out.WriteString(text)
+	f.lineStart = false
+	f.prevPrev, f.prev = f.prev, token
+}
+
+// spaceBefore reports whether a space separates token from the previous one on the line
+func (f *formatter) spaceBefore(token Token) bool {
+	prev := f.prev
+	adjacent := prev.End == token.Start
+	switch {
+	case token.Text == "," || token.Text == ";" || token.Text == ")" || token.Text == "." || token.Text == "]":
+		return false
+	case prev.Text == "." || prev.Text == "(" || prev.Text == "[" || prev.Text == "::" || token.Text == "::":
+		return false
+	case adjacent && (prev.Text == "@" || token.Text == "@" || token.Text == "[" || token.Text == ":"):
+		// user@host accounts, array subscripts and labels
+		return false
+	case token.Text == "(":
+		return prev.Kind == TokenOperator || prev.Text == "," || prev.Kind != TokenFunction && !adjacent
+	case (prev.Text == "-" || prev.Text == "+" || prev.Text == "~") && f.isUnary():
+		return false
+	}
+	return true
+}
+
+// isUnary reports whether the previous token is a sign rather than a binary operator
+func (f *formatter) isUnary() bool {
+	before := f.prevPrev
+	return before.Text == "" || before.Kind == TokenOperator || before.Kind == TokenKeyword ||
+		before.Text == "(" || before.Text == ","
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyPrintSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  FormatOptions
+		expected string
+	}{
+		{
+			name:     "empty string",
+			input:    "",
+			expected: "",
+		},
+		{
+			name:     "single column stays on the SELECT line",
+			input:    "select *   from users",
+			expected: "select *\nfrom users",
+		},
+		{
+			name:  "clauses, joins, conditions and column list",
+			input: "select id, name, count(*) as n from users u left join orders o on o.user_id = u.id where u.age between 18 and 30 and (u.x = 1 or u.y = 2) group by id, name order by n desc limit 10",
+			expected: "select\n" +
+				"  id,\n" +
+				"  name,\n" +
+				"  count(*) as n\n" +
+				"from users u\n" +
+				"left join orders o on o.user_id = u.id\n" +
+				"where u.age between 18 and 30\n" +
+				"  and (u.x = 1 or u.y = 2)\n" +
+				"group by id, name\n" +
+				"order by n desc\n" +
+				"limit 10",
+		},
+		{
+			name:    "subquery and uppercase keywords",
+			input:   "select id from users where id in (select user_id from orders where total > -1)",
+			options: FormatOptions{UppercaseKeywords: true},
+			expected: "SELECT id\n" +
+				"FROM users\n" +
+				"WHERE id IN (\n" +
+				"  SELECT user_id\n" +
+				"  FROM orders\n" +
+				"  WHERE total > -1\n" +
+				")",
+		},
+		{
+			name:  "CASE expression",
+			input: "SELECT CASE WHEN a > 1 THEN 'big' ELSE 'small' END AS size, b FROM t",
+			expected: "SELECT\n" +
+				"  CASE\n" +
+				"    WHEN a > 1 THEN 'big'\n" +
+				"    ELSE 'small'\n" +
+				"  END AS size,\n" +
+				"  b\n" +
+				"FROM t",
+		},
+		{
+			name:  "view definition returned on one line",
+			input: "CREATE ALGORITHM=UNDEFINED DEFINER=`admin`@`%` SQL SECURITY DEFINER VIEW `v` AS select `users`.`id` AS `id`,`users`.`name` AS `name` from `users` where (`users`.`id` > 0)",
+			expected: "CREATE ALGORITHM = UNDEFINED DEFINER = `admin`@`%` SQL SECURITY DEFINER VIEW `v` AS\n" +
+				"select\n" +
+				"  `users`.`id` AS `id`,\n" +
+				"  `users`.`name` AS `name`\n" +
+				"from `users`\n" +
+				"where (`users`.`id` > 0)",
+		},
+		{
+			name:  "routine body",
+			input: "CREATE PROCEDURE p(IN n INT) BEGIN DECLARE i INT DEFAULT 0; WHILE i < n DO SET i = i + 1; END WHILE; IF i > 10 THEN SELECT 'many'; ELSE SELECT 'few'; END IF; END",
+			expected: "CREATE PROCEDURE p(IN n INT) BEGIN\n" +
+				"  DECLARE i INT DEFAULT 0;\n" +
+				"  WHILE i < n DO\n" +
+				"    SET i = i + 1;\n" +
+				"  END WHILE;\n" +
+				"  IF i > 10 THEN\n" +
+				"    SELECT 'many';\n" +
+				"  ELSE\n" +
+				"    SELECT 'few';\n" +
+				"  END IF;\n" +
+				"END",
+		},
+		{
+			name:  "trigger and event bodies",
+			input: "CREATE TRIGGER t BEFORE INSERT ON orders FOR EACH ROW SET NEW.d = NOW(); CREATE EVENT e ON SCHEDULE EVERY 1 HOUR DO DELETE FROM sessions WHERE expires_at < NOW()",
+			expected: "CREATE TRIGGER t BEFORE INSERT ON orders FOR EACH ROW\n" +
+				"SET NEW.d = NOW();\n" +
+				"\n" +
+				"CREATE EVENT e ON SCHEDULE EVERY 1 HOUR DO\n" +
+				"DELETE FROM sessions\n" +
+				"WHERE expires_at < NOW()",
+		},
+		{
+			name:  "statements, comments and VALUES function",
+			input: "insert into t (a, b) values (1, 2) on duplicate key update a = values(a); -- next\nupdate t set a = 1 where id = 3",
+			expected: "insert into t (a, b)\n" +
+				"values (1, 2)\n" +
+				"on duplicate key update a = values(a);\n" +
+				"\n" +
+				"-- next\n" +
+				"update t\n" +
+				"set a = 1\n" +
+				"where id = 3",
+		},
+		{
+			name:     "casts, subscripts and qualified keywords",
+			input:    "SELECT x::int, a[1], t.select FROM t",
+			expected: "SELECT\n  x::int,\n  a[1],\n  t.select\nFROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PrettyPrintSQL(tt.input, tt.options))
+		})
+	}
+}
+
+func TestPrettyPrintScript(t *testing.T) {
+	script := "DELIMITER $$\nCREATE PROCEDURE p() BEGIN SELECT 1; END$$\nDELIMITER ;\nselect a, b from t;\n-- trailing"
+
+	expected := "DELIMITER $$\n" +
+		"CREATE PROCEDURE p() BEGIN\n" +
+		"  SELECT 1;\n" +
+		"END$$\n" +
+		"\n" +
+		"DELIMITER ;\n" +
+		"select\n" +
+		"  a,\n" +
+		"  b\n" +
+		"from t;\n" +
+		"\n" +
+		"-- trailing"
+	assert.Equal(t, expected, PrettyPrintScript(script, FormatOptions{}))
+}
+
+func TestPrettyPrintScriptKeepsComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected string
+	}{
+		{
+			name:     "comment after a statement",
+			script:   "select 1; -- c\nDELIMITER //\nselect 2//",
+			expected: "select 1;\n\n-- c\nDELIMITER //\nselect 2//",
+		},
+		{
+			name:     "comment above a delimiter command",
+			script:   "-- setup\nDELIMITER //\nselect 2//",
+			expected: "-- setup\nDELIMITER //\nselect 2//",
+		},
+		{
+			name:     "block comment between delimiter commands",
+			script:   "DELIMITER //\nselect 1//\n/* back\n   to ; */\nDELIMITER ;\nselect 2;",
+			expected: "DELIMITER //\nselect 1//\n\n/* back\n   to ; */\nDELIMITER ;\nselect 2;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PrettyPrintScript(tt.script, FormatOptions{}))
+		})
+	}
+}
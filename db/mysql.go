@@ -2,12 +2,24 @@ package db
 
 import (
 	"database/sql"
+	"strings"
 )
 
 type TableData interface{}
 
+// SQL dialects returned by Dialect
+const (
+	DialectMysql    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSqlite   = "sqlite"
+)
+
 type Mysql struct {
 	DbInstance *sql.DB
+	// database/sql driver name the instance was opened with, empty means mysql
+	DriverName string
+	// host and database connected to, without credentials
+	Name string
 }
 
 type MysqlTable struct {
@@ -27,3 +39,127 @@ type MysqlDatabase struct {
 func (m *Mysql) Db() *sql.DB {
 	return m.DbInstance
 }
+
+// Dialect returns the SQL dialect of the connection: mysql, postgres or sqlite
+func (m *Mysql) Dialect() string {
+	switch m.DriverName {
+	case "pgx":
+		return DialectPostgres
+	case "sqlite3":
+		return DialectSqlite
+	default:
+		return DialectMysql
+	}
+}
+
+// ConnectionName returns the host and database connected to, used to keep settings per connection
+func (m *Mysql) ConnectionName() string {
+	return m.Name
+}
+
+// QuoteIdentifier quotes a table or column name for the dialect
+func QuoteIdentifier(dialect string, name string) string {
+	if dialect == DialectMysql {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+type MysqlColumn struct {
+	Name     string
+	Type     string
+	Nullable string
+	Default  string
+	Key      string
+	Extra    string
+	Comment  string
+}
+
+type MysqlIndex struct {
+	Name        string
+	Columns     string
+	Unique      string
+	Cardinality string
+	Type        string
+}
+
+type MysqlForeignKey struct {
+	Name       string
+	Columns    string
+	RefTable   string
+	RefColumns string
+	OnUpdate   string
+	OnDelete   string
+}
+
+type MysqlCheck struct {
+	Name     string
+	Clause   string
+	Enforced string
+}
+
+type MysqlTrigger struct {
+	Name      string
+	Timing    string
+	Event     string
+	Statement string
+}
+
+type MysqlProcess struct {
+	ID    string
+	User  string
+	Host  string
+	Db    string
+	State string
+	Time  string
+	Query string
+}
+
+type MysqlView struct {
+	Name        string
+	Updatable   string
+	CheckOption string
+	Security    string
+	Definer     string
+}
+
+type MysqlRoutine struct {
+	Name          string
+	Type          string
+	Returns       string
+	Deterministic string
+	Security      string
+	Definer       string
+	Modified      string
+}
+
+type MysqlTriggerSummary struct {
+	Name    string
+	Table   string
+	Timing  string
+	Event   string
+	Created string
+}
+
+type MysqlEvent struct {
+	Name     string
+	Type     string
+	Schedule string
+	Status   string
+	Starts   string
+	Ends     string
+}
+
+type MysqlSequence struct {
+	Name string
+}
+
+// Schema object kinds accepted by FetchObjectDefinition
+const (
+	ObjectView      = "VIEW"
+	ObjectProcedure = "PROCEDURE"
+	ObjectFunction  = "FUNCTION"
+	ObjectTrigger   = "TRIGGER"
+	ObjectEvent     = "EVENT"
+	ObjectSequence  = "SEQUENCE"
+)
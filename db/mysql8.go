@@ -2,8 +2,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 type Mysql8 struct {
@@ -192,7 +195,7 @@ func (m *Mysql8) FetchTables(ctx context.Context) ([]string, []TableData) {
 			IFNULL(TABLE_ROWS, 0) as TABLE_ROWS,
 			IFNULL(ROUND(((DATA_LENGTH + INDEX_LENGTH) / 1024 / 1024), 2), 0) as SIZE_MB
 		FROM information_schema.TABLES 
-		WHERE TABLE_SCHEMA = DATABASE()
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
 		ORDER BY TABLE_NAME
 	`
 
@@ -244,14 +247,84 @@ func (m *Mysql8) FetchTables(ctx context.Context) ([]string, []TableData) {
 	return headers, tableData
 }
 
-// FetchSqlRows executes a SQL query and returns the results similar to FetchTableRows
-func (m *Mysql8) FetchSqlRows(ctx context.Context, sqlQuery string) ([]string, []TableData) {
-	slog.Debug("FetchSqlRows: Executing SQL query", "query", sqlQuery)
+// FetchSqlRows executes a SQL query with bind arguments and returns the results similar to FetchTableRows
+func (m *Mysql8) FetchSqlRows(ctx context.Context, sqlQuery string, args ...any) ([]string, []TableData) {
+	columnNames, tableData, err := m.querySqlRows(ctx, sqlQuery, args...)
+	if err != nil {
+		return []string{"Error"}, []TableData{map[string]string{"Error": err.Error()}}
+	}
+	return columnNames, tableData
+}
+
+// ExecuteSql runs a single statement of a script, fetching rows of queries
+// and the affected row count of other statements
+func (m *Mysql8) ExecuteSql(ctx context.Context, statement string, args ...any) StatementResult {
+	return executeOn(ctx, m.Db(), statement, args...)
+}
+
+// ExecuteScript runs statements in order on a single connection, so USE, session variables, transactions and
+// temporary tables carry over from one statement to the next. It stops after the first failing statement and
+// returns the results of the statements run, progress receives the number run so far
+func (m *Mysql8) ExecuteScript(ctx context.Context, statements []string, progress func(done int)) []StatementResult {
+	conn, err := m.Db().Conn(ctx)
+	if err != nil {
+		slog.Error("ExecuteScript: Failed to get a connection", "error", err)
+		return []StatementResult{{Err: err}}
+	}
+	defer conn.Close()
+	// the session the script changed is not handed to other queries, closing it also rolls back an open transaction
+	defer conn.Raw(func(any) error { return driver.ErrBadConn })
+
+	var results []StatementResult
+	for i, statement := range statements {
+		result := executeOn(ctx, conn, statement)
+		results = append(results, result)
+		progress(i + 1)
+		if result.Err != nil {
+			break
+		}
+	}
+	return results
+}
+
+// sqlExecutor runs statements on the connection pool or on a single connection
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// executeOn runs a statement with an executor, fetching rows of queries and the affected row count of others
+func executeOn(ctx context.Context, executor sqlExecutor, statement string, args ...any) StatementResult {
+	started := time.Now()
+	if ReturnsRows(statement) {
+		headers, data, err := queryRows(ctx, executor, statement, args...)
+		return StatementResult{Headers: headers, Data: data, Duration: time.Since(started), Err: err}
+	}
 
-	rows, err := m.Db().QueryContext(ctx, sqlQuery)
+	slog.Debug("ExecuteSql: Executing statement", "statement", statement)
+	result, err := executor.ExecContext(ctx, statement, args...)
+	if err != nil {
+		slog.Error("ExecuteSql: Statement failed", "error", err, "statement", statement)
+		return StatementResult{Duration: time.Since(started), Err: err}
+	}
+	// drivers without affected row counts report an error, the statement still succeeded
+	rowsAffected, _ := result.RowsAffected()
+	return StatementResult{RowsAffected: rowsAffected, Duration: time.Since(started)}
+}
+
+// querySqlRows executes a SQL query and returns up to 1000 rows
+func (m *Mysql8) querySqlRows(ctx context.Context, sqlQuery string, args ...any) ([]string, []TableData, error) {
+	return queryRows(ctx, m.Db(), sqlQuery, args...)
+}
+
+// queryRows executes a SQL query with an executor and returns up to 1000 rows
+func queryRows(ctx context.Context, executor sqlExecutor, sqlQuery string, args ...any) ([]string, []TableData, error) {
+	slog.Debug("FetchSqlRows: Executing SQL query", "query", sqlQuery, "args", len(args))
+
+	rows, err := executor.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		slog.Error("FetchSqlRows: Query failed", "error", err, "query", sqlQuery)
-		return []string{"Error"}, []TableData{map[string]string{"Error": err.Error()}}
+		return nil, nil, err
 	}
 	defer rows.Close()
 
@@ -259,7 +332,7 @@ func (m *Mysql8) FetchSqlRows(ctx context.Context, sqlQuery string) ([]string, [
 	columnNames, err := rows.Columns()
 	if err != nil {
 		slog.Error("FetchSqlRows: Failed to get column names", "error", err)
-		return []string{"Error"}, []TableData{map[string]string{"Error": err.Error()}}
+		return nil, nil, err
 	}
 
 	slog.Debug("FetchSqlRows: Found columns", "count", len(columnNames), "headers", columnNames)
@@ -307,9 +380,81 @@ func (m *Mysql8) FetchSqlRows(ctx context.Context, sqlQuery string) ([]string, [
 
 	if err := rows.Err(); err != nil {
 		slog.Error("FetchSqlRows: Error during row iteration", "error", err)
-		return []string{"Error"}, []TableData{map[string]string{"Error": err.Error()}}
+		return nil, nil, err
 	}
 
 	slog.Debug("FetchSqlRows: Processing complete", "query", sqlQuery, "rowsFound", rowCount)
-	return columnNames, tableData
+	return columnNames, tableData, nil
+}
+
+// StreamSqlRows executes a SQL query and passes every row to onRow without a row limit, nil values are NULLs.
+// onColumns receives the column names with the type names the driver reports for them, such as INT or VARCHAR
+func (m *Mysql8) StreamSqlRows(ctx context.Context, sqlQuery string, onColumns func(columns []string, types []string) error, onRow func(values []*string) error, args ...any) error {
+	slog.Debug("StreamSqlRows: Executing SQL query", "query", sqlQuery)
+
+	rows, err := m.Db().QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		slog.Error("StreamSqlRows: Query failed", "error", err, "query", sqlQuery)
+		return err
+	}
+	defer rows.Close()
+
+	columnNames, err := rows.Columns()
+	if err != nil {
+		slog.Error("StreamSqlRows: Failed to get column names", "error", err)
+		return err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		slog.Error("StreamSqlRows: Failed to get column types", "error", err)
+		return err
+	}
+	typeNames := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		typeNames[i] = columnType.DatabaseTypeName()
+	}
+	if err := onColumns(columnNames, typeNames); err != nil {
+		return err
+	}
+
+	rowCount := 0
+	for rows.Next() {
+		values := make([]interface{}, len(columnNames))
+		valuePtrs := make([]interface{}, len(columnNames))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			slog.Error("StreamSqlRows: Failed to scan row", "error", err, "rowNum", rowCount)
+			return err
+		}
+
+		fields := make([]*string, len(columnNames))
+		for i := range values {
+			if values[i] == nil {
+				continue
+			}
+			var field string
+			if byteData, ok := values[i].([]byte); ok {
+				field = string(byteData)
+			} else {
+				field = fmt.Sprintf("%v", values[i])
+			}
+			fields[i] = &field
+		}
+
+		if err := onRow(fields); err != nil {
+			return err
+		}
+		rowCount++
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("StreamSqlRows: Error during row iteration", "error", err)
+		return err
+	}
+
+	slog.Debug("StreamSqlRows: Processing complete", "query", sqlQuery, "rowsStreamed", rowCount)
+	return nil
 }
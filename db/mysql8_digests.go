@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// StatementDigest sums up the runs of a normalised statement, its literals replaced by placeholders
+type StatementDigest struct {
+	Digest    string
+	Text      string
+	Db        string
+	Calls     int64
+	TotalTime time.Duration
+	AvgTime   time.Duration
+	// RowsExamined is -1 where the server doesn't count it, such as on Postgres
+	RowsExamined int64
+	RowsSent     int64
+	// Sample is a run of the statement with its literals, empty when the server keeps none
+	Sample string
+}
+
+// digestQueries read the statements taking the most time first, times in milliseconds. MySQL keeps a sample
+// of each statement, pg_stat_statements has none and counts no examined rows
+var digestQueries = map[string]string{
+	DialectMysql: `
+		SELECT IFNULL(DIGEST, ''), DIGEST_TEXT, IFNULL(SCHEMA_NAME, ''), COUNT_STAR,
+			SUM_TIMER_WAIT / 1000000000, AVG_TIMER_WAIT / 1000000000, SUM_ROWS_EXAMINED, SUM_ROWS_SENT,
+			IFNULL(QUERY_SAMPLE_TEXT, '')
+		FROM performance_schema.events_statements_summary_by_digest
+		WHERE DIGEST_TEXT IS NOT NULL
+		ORDER BY SUM_TIMER_WAIT DESC
+		LIMIT ?
+	`,
+	DialectPostgres: `
+		SELECT s.queryid::text, s.query, d.datname, s.calls,
+			s.total_exec_time, s.mean_exec_time, -1, s.rows, ''
+		FROM pg_stat_statements s
+		JOIN pg_database d ON d.oid = s.dbid
+		WHERE d.datname = current_database()
+		ORDER BY s.total_exec_time DESC
+		LIMIT $1
+	`,
+}
+
+// FetchStatementDigests queries the statements that took the most time in total, at most limit of them.
+// Postgres needs the pg_stat_statements extension
+func (m *Mysql8) FetchStatementDigests(ctx context.Context, limit int) ([]StatementDigest, error) {
+	dialect := m.Dialect()
+	query, ok := digestQueries[dialect]
+	if !ok {
+		return nil, ErrNoStatistics
+	}
+	rows, err := m.fetchStrings(ctx, "fetchStatementDigests", query, limit)
+	if err != nil {
+		if dialect == DialectPostgres {
+			return nil, fmt.Errorf("pg_stat_statements is not available: %w", err)
+		}
+		return nil, err
+	}
+
+	var digests []StatementDigest
+	for _, row := range rows {
+		digest := StatementDigest{Digest: row[0], Text: row[1], Db: row[2], Sample: row[8]}
+		digest.Calls, _ = strconv.ParseInt(row[3], 10, 64)
+		digest.TotalTime = milliseconds(row[4])
+		digest.AvgTime = milliseconds(row[5])
+		digest.RowsExamined, _ = strconv.ParseInt(row[6], 10, 64)
+		digest.RowsSent, _ = strconv.ParseInt(row[7], 10, 64)
+		digests = append(digests, digest)
+	}
+	return digests, nil
+}
+
+// milliseconds reads a number of milliseconds, which may have a fraction
+func milliseconds(text string) time.Duration {
+	value, _ := strconv.ParseFloat(text, 64)
+	return time.Duration(value * float64(time.Millisecond))
+}
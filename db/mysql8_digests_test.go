@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchStatementDigests(t *testing.T) {
+	tests := []struct {
+		name          string
+		driver        string
+		expectedQuery string
+		examined      string
+		expected      StatementDigest
+	}{
+		{
+			name:          "mysql",
+			driver:        "mysql",
+			expectedQuery: "FROM performance_schema.events_statements_summary_by_digest",
+			examined:      "2400",
+			expected: StatementDigest{
+				Digest: "3f1c", Text: "SELECT * FROM `orders` WHERE `id` = ?", Db: "shop", Calls: 12,
+				TotalTime: 1500 * time.Millisecond, AvgTime: 125 * time.Millisecond, RowsExamined: 2400, RowsSent: 12,
+				Sample: "SELECT * FROM orders WHERE id = 7",
+			},
+		},
+		{
+			name:          "postgres",
+			driver:        "pgx",
+			expectedQuery: "FROM pg_stat_statements",
+			examined:      "-1",
+			expected: StatementDigest{
+				Digest: "3f1c", Text: "SELECT * FROM `orders` WHERE `id` = ?", Db: "shop", Calls: 12,
+				TotalTime: 1500 * time.Millisecond, AvgTime: 125 * time.Millisecond, RowsExamined: -1, RowsSent: 12,
+				Sample: "SELECT * FROM orders WHERE id = 7",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			mock.ExpectQuery(tt.expectedQuery).WithArgs(10).WillReturnRows(sqlmock.NewRows(
+				[]string{"digest", "text", "db", "calls", "total", "avg", "examined", "sent", "sample"}).
+				AddRow("3f1c", "SELECT * FROM `orders` WHERE `id` = ?", "shop", "12", "1500.0000", "125.0000", tt.examined, "12",
+					"SELECT * FROM orders WHERE id = 7"))
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			digests, err := mysql.FetchStatementDigests(context.Background(), 10)
+
+			assert.NoError(t, err)
+			assert.Equal(t, []StatementDigest{tt.expected}, digests)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchStatementDigestsErrors(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	missing := errors.New(`relation "pg_stat_statements" does not exist`)
+	mock.ExpectQuery("pg_stat_statements").WillReturnError(missing)
+	postgres := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "pgx"}}
+	_, err = postgres.FetchStatementDigests(context.Background(), 10)
+	assert.ErrorIs(t, err, missing)
+	assert.Contains(t, err.Error(), "pg_stat_statements is not available")
+
+	sqlite := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "sqlite3"}}
+	_, err = sqlite.FetchStatementDigests(context.Background(), 10)
+	assert.ErrorIs(t, err, ErrNoStatistics)
+}
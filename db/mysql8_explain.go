@@ -0,0 +1,396 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PlanNode is a step of a query plan with the steps feeding it
+type PlanNode struct {
+	// Operation is what the step does, such as table, nested loop or Seq Scan
+	Operation string
+	Table     string
+	// Access is how rows are read, such as ALL, ref or SEARCH
+	Access string
+	Index  string
+	Cost   string
+	Rows   string
+	// FullScan marks steps reading every row of a table
+	FullScan bool
+	// Detail holds conditions and notes such as using filesort
+	Detail   string
+	Children []PlanNode
+}
+
+// FullScans counts the full table scans of a plan
+func (n PlanNode) FullScans() int {
+	count := 0
+	if n.FullScan {
+		count++
+	}
+	for _, child := range n.Children {
+		count += child.FullScans()
+	}
+	return count
+}
+
+// sqliteIndexPattern finds the index used by a step of a SQLite query plan
+var sqliteIndexPattern = regexp.MustCompile(`USING (?:COVERING )?INDEX (\S+)|USING (INTEGER PRIMARY KEY|PRIMARY KEY)`)
+
+// mysqlTreeLinePattern splits a line of an EXPLAIN ANALYZE tree into its indentation, operation, estimates
+// and actual figures, a step that never ran has none
+var mysqlTreeLinePattern = regexp.MustCompile(`^( *)-> (.*?)(?:\s+\(cost=(\S+) rows=(\S+)\))?(?:\s+\((actual [^)]*|never executed)\))?\s*$`)
+
+// mysqlTreeTablePattern finds the table and index a step of an EXPLAIN ANALYZE tree reads
+var mysqlTreeTablePattern = regexp.MustCompile(` on (\S+)(?: using (\S+))?`)
+
+// FetchPlan explains a statement: EXPLAIN FORMAT=JSON on MySQL, EXPLAIN (FORMAT JSON) on Postgres and
+// EXPLAIN QUERY PLAN on SQLite. On MySQL and Postgres analyze runs the statement to add actual rows and timing,
+// with EXPLAIN ANALYZE on MySQL 8.0.18 and later, SQLite has no analyzed plans
+func (m *Mysql8) FetchPlan(ctx context.Context, statement string, analyze bool, args ...any) (PlanNode, error) {
+	switch m.Dialect() {
+	case DialectPostgres:
+		options := "FORMAT JSON"
+		query := m.fetchStrings
+		if analyze {
+			options = "ANALYZE, " + options
+			query = m.fetchAnalyzedStrings
+		}
+		rows, err := query(ctx, "fetchPlan", "EXPLAIN ("+options+") "+statement, args...)
+		if err != nil {
+			return PlanNode{}, err
+		}
+		if len(rows) == 0 {
+			return PlanNode{}, errors.New("no plan returned")
+		}
+		return parsePostgresPlan(rows[0][0])
+	case DialectSqlite:
+		rows, err := m.fetchStrings(ctx, "fetchPlan", "EXPLAIN QUERY PLAN "+statement, args...)
+		if err != nil {
+			return PlanNode{}, err
+		}
+		return parseSqlitePlan(rows), nil
+	default:
+		if analyze {
+			rows, err := m.fetchAnalyzedStrings(ctx, "fetchPlan", "EXPLAIN ANALYZE "+statement, args...)
+			if err != nil {
+				return PlanNode{}, err
+			}
+			if len(rows) == 0 {
+				return PlanNode{}, errors.New("no plan returned")
+			}
+			return parseMysqlTreePlan(rows[0][0])
+		}
+		rows, err := m.fetchStrings(ctx, "fetchPlan", "EXPLAIN FORMAT=JSON "+statement, args...)
+		if err != nil {
+			return PlanNode{}, err
+		}
+		if len(rows) == 0 {
+			return PlanNode{}, errors.New("no plan returned")
+		}
+		return parseMysqlPlan(rows[0][0])
+	}
+}
+
+// fetchAnalyzedStrings is fetchStrings for EXPLAIN ANALYZE, which runs the statement. It runs in a transaction
+// that is rolled back, so explaining an UPDATE or DELETE changes no data
+func (m *Mysql8) fetchAnalyzedStrings(ctx context.Context, caller string, query string, args ...interface{}) ([][]string, error) {
+	tx, err := m.Db().BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(caller+": Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, rows, err := queryStrings(ctx, tx, caller, query, args...)
+	return rows, err
+}
+
+// parseMysqlTreePlan reads the steps of a MySQL EXPLAIN ANALYZE tree, each indented below the step it feeds.
+// A table scan is a full table scan
+func parseMysqlTreePlan(raw string) (PlanNode, error) {
+	root := PlanNode{Operation: "query plan"}
+	// the steps from the root to the last one read, with their indentation
+	var path []*PlanNode
+	var indents []int
+	for _, line := range strings.Split(raw, "\n") {
+		match := mysqlTreeLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		node := PlanNode{Operation: match[2], Rows: match[4], Cost: match[3], Detail: match[5]}
+		if table := mysqlTreeTablePattern.FindStringSubmatch(node.Operation); table != nil {
+			node.Table, node.Index = table[1], table[2]
+		}
+		node.FullScan = strings.HasPrefix(node.Operation, "Table scan on")
+
+		indent := len(match[1])
+		for len(indents) > 0 && indents[len(indents)-1] >= indent {
+			path, indents = path[:len(path)-1], indents[:len(indents)-1]
+		}
+		parent := &root
+		if len(path) > 0 {
+			parent = path[len(path)-1]
+		}
+		parent.Children = append(parent.Children, node)
+		path = append(path, &parent.Children[len(parent.Children)-1])
+		indents = append(indents, indent)
+	}
+
+	if len(root.Children) == 0 {
+		return PlanNode{}, errors.New("reading plan: no steps")
+	}
+	if len(root.Children) == 1 {
+		return root.Children[0], nil
+	}
+	return root, nil
+}
+
+// parseMysqlPlan reads the query blocks, operations and tables of a MySQL JSON plan
+func parseMysqlPlan(raw string) (PlanNode, error) {
+	var plan map[string]any
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		return PlanNode{}, fmt.Errorf("reading plan: %w", err)
+	}
+	block, ok := plan["query_block"].(map[string]any)
+	if !ok {
+		return PlanNode{}, errors.New("reading plan: no query block")
+	}
+	return mysqlQueryBlock(block), nil
+}
+
+// mysqlQueryBlock converts a query block, a SELECT of the statement
+func mysqlQueryBlock(block map[string]any) PlanNode {
+	node := PlanNode{Operation: "query block", Detail: mysqlNotes(block)}
+	if id, ok := block["select_id"]; ok {
+		node.Operation = "query block #" + jsonText(id)
+	}
+	if costInfo, ok := block["cost_info"].(map[string]any); ok {
+		node.Cost = jsonText(costInfo["query_cost"])
+	}
+	if message, ok := block["message"].(string); ok {
+		node.Detail = joinNotes(node.Detail, message)
+	}
+	node.Children = mysqlOperations(block)
+	return node
+}
+
+// mysqlTable converts a table access, ALL is a full table scan
+func mysqlTable(table map[string]any) PlanNode {
+	node := PlanNode{
+		Operation: "table",
+		Table:     jsonText(table["table_name"]),
+		Access:    jsonText(table["access_type"]),
+		Index:     jsonText(table["key"]),
+		Rows:      jsonText(table["rows_examined_per_scan"]),
+		Detail:    joinNotes(mysqlNotes(table), jsonText(table["attached_condition"])),
+	}
+	if costInfo, ok := table["cost_info"].(map[string]any); ok {
+		node.Cost = jsonText(costInfo["prefix_cost"])
+	}
+	node.FullScan = node.Access == "ALL"
+	node.Children = mysqlOperations(table)
+	return node
+}
+
+// mysqlOperations returns the steps nested in an object of a MySQL JSON plan, such as nested loops,
+// ordering and grouping, materialized subqueries and unions
+func mysqlOperations(object map[string]any) []PlanNode {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var nodes []PlanNode
+	for _, key := range keys {
+		switch value := object[key].(type) {
+		case map[string]any:
+			switch key {
+			case "cost_info":
+			case "table":
+				nodes = append(nodes, mysqlTable(value))
+			case "query_block":
+				nodes = append(nodes, mysqlQueryBlock(value))
+			default:
+				nodes = append(nodes, PlanNode{
+					Operation: strings.ReplaceAll(key, "_", " "),
+					Detail:    mysqlNotes(value),
+					Children:  mysqlOperations(value),
+				})
+			}
+		case []any:
+			var children []PlanNode
+			for _, item := range value {
+				if itemObject, ok := item.(map[string]any); ok {
+					children = append(children, mysqlOperations(itemObject)...)
+				}
+			}
+			if len(children) > 0 {
+				nodes = append(nodes, PlanNode{Operation: strings.ReplaceAll(key, "_", " "), Children: children})
+			}
+		}
+	}
+	return nodes
+}
+
+// mysqlNotes lists the flags set on a plan object, such as using filesort or using temporary table
+func mysqlNotes(object map[string]any) string {
+	var notes []string
+	for key, value := range object {
+		if set, ok := value.(bool); ok && set && strings.HasPrefix(key, "using_") {
+			notes = append(notes, strings.ReplaceAll(key, "_", " "))
+		}
+	}
+	sort.Strings(notes)
+	return strings.Join(notes, ", ")
+}
+
+// parsePostgresPlan reads the nodes of a Postgres JSON plan
+func parsePostgresPlan(raw string) (PlanNode, error) {
+	var plans []map[string]any
+	if err := json.Unmarshal([]byte(raw), &plans); err != nil {
+		return PlanNode{}, fmt.Errorf("reading plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return PlanNode{}, errors.New("reading plan: no plan")
+	}
+	plan, ok := plans[0]["Plan"].(map[string]any)
+	if !ok {
+		return PlanNode{}, errors.New("reading plan: no plan")
+	}
+
+	root := postgresNode(plan)
+	if planning, ok := plans[0]["Planning Time"]; ok {
+		root.Detail = joinNotes(root.Detail, "planning "+jsonText(planning)+" ms")
+	}
+	if execution, ok := plans[0]["Execution Time"]; ok {
+		root.Detail = joinNotes(root.Detail, "execution "+jsonText(execution)+" ms")
+	}
+	return root, nil
+}
+
+// postgresNode converts a plan node, a Seq Scan is a full table scan
+func postgresNode(plan map[string]any) PlanNode {
+	node := PlanNode{
+		Operation: jsonText(plan["Node Type"]),
+		Table:     jsonText(plan["Relation Name"]),
+		Index:     jsonText(plan["Index Name"]),
+		Rows:      jsonText(plan["Plan Rows"]),
+	}
+	if joinType := jsonText(plan["Join Type"]); joinType != "" {
+		node.Operation += " (" + joinType + ")"
+	}
+	if total, ok := plan["Total Cost"]; ok {
+		node.Cost = jsonText(plan["Startup Cost"]) + ".." + jsonText(total)
+	}
+	node.FullScan = node.Operation == "Seq Scan"
+
+	for _, key := range []string{"Index Cond", "Hash Cond", "Merge Cond", "Join Filter", "Filter", "Sort Key"} {
+		if value, ok := plan[key]; ok {
+			node.Detail = joinNotes(node.Detail, strings.ToLower(key)+" "+jsonText(value))
+		}
+	}
+	if actual, ok := plan["Actual Rows"]; ok {
+		node.Detail = joinNotes(node.Detail, fmt.Sprintf("actual rows %s, loops %s, %s ms",
+			jsonText(actual), jsonText(plan["Actual Loops"]), jsonText(plan["Actual Total Time"])))
+	}
+
+	children, _ := plan["Plans"].([]any)
+	for _, child := range children {
+		if childPlan, ok := child.(map[string]any); ok {
+			node.Children = append(node.Children, postgresNode(childPlan))
+		}
+	}
+	return node
+}
+
+// parseSqlitePlan builds the tree of EXPLAIN QUERY PLAN rows of id, parent, unused and detail.
+// A SCAN without an index is a full table scan
+func parseSqlitePlan(rows [][]string) PlanNode {
+	root := PlanNode{Operation: "query plan"}
+	byParent := map[string][]int{}
+	for i, row := range rows {
+		if len(row) >= 4 {
+			byParent[row[1]] = append(byParent[row[1]], i)
+		}
+	}
+
+	var children func(parent string) []PlanNode
+	children = func(parent string) []PlanNode {
+		var nodes []PlanNode
+		for _, i := range byParent[parent] {
+			node := sqliteNode(rows[i][3])
+			// ids are unique, a node being its own parent would never end
+			if rows[i][0] != parent {
+				node.Children = children(rows[i][0])
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	root.Children = children("0")
+	return root
+}
+
+// sqliteNode converts a plan step such as SEARCH users USING INDEX idx_email (email=?)
+func sqliteNode(detail string) PlanNode {
+	node := PlanNode{Operation: detail}
+	words := strings.Fields(detail)
+	if len(words) == 0 {
+		return node
+	}
+	node.Access = words[0]
+	if node.Access == "SCAN" || node.Access == "SEARCH" {
+		// older versions write SCAN TABLE users
+		if len(words) > 2 && words[1] == "TABLE" {
+			words = words[1:]
+		}
+		if len(words) > 1 {
+			node.Table = words[1]
+		}
+	}
+	if match := sqliteIndexPattern.FindStringSubmatch(detail); match != nil {
+		node.Index = match[1] + match[2]
+	}
+	node.FullScan = node.Access == "SCAN" && node.Table != "" && node.Index == ""
+	return node
+}
+
+// jsonText formats a JSON value of a plan, numbers without trailing zeros
+func jsonText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = jsonText(item)
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// joinNotes joins the non empty notes of a plan step
+func joinNotes(notes ...string) string {
+	var present []string
+	for _, note := range notes {
+		if note != "" {
+			present = append(present, note)
+		}
+	}
+	return strings.Join(present, "; ")
+}
@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const mysqlJoinPlan = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "12.50"},
+    "ordering_operation": {
+      "using_filesort": true,
+      "nested_loop": [
+        {"table": {"table_name": "u", "access_type": "ALL", "rows_examined_per_scan": 50,
+                   "cost_info": {"prefix_cost": "5.25"}, "attached_condition": "(u.active = 1)"}},
+        {"table": {"table_name": "o", "access_type": "ref", "possible_keys": ["idx_user_id"], "key": "idx_user_id",
+                   "rows_examined_per_scan": 3, "cost_info": {"prefix_cost": "12.50"}}}
+      ]
+    }
+  }
+}`
+
+func TestParseMysqlPlan(t *testing.T) {
+	plan, err := parseMysqlPlan(mysqlJoinPlan)
+	assert.NoError(t, err)
+	assert.Equal(t, PlanNode{
+		Operation: "query block #1",
+		Cost:      "12.50",
+		Children: []PlanNode{{
+			Operation: "ordering operation",
+			Detail:    "using filesort",
+			Children: []PlanNode{{
+				Operation: "nested loop",
+				Children: []PlanNode{
+					{Operation: "table", Table: "u", Access: "ALL", Rows: "50", Cost: "5.25", FullScan: true, Detail: "(u.active = 1)"},
+					{Operation: "table", Table: "o", Access: "ref", Index: "idx_user_id", Rows: "3", Cost: "12.50"},
+				},
+			}},
+		}},
+	}, plan)
+	assert.Equal(t, 1, plan.FullScans())
+
+	plan, err = parseMysqlPlan(`{"query_block": {"select_id": 1, "message": "No tables used"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, PlanNode{Operation: "query block #1", Detail: "No tables used"}, plan)
+
+	_, err = parseMysqlPlan(`{}`)
+	assert.EqualError(t, err, "reading plan: no query block")
+	_, err = parseMysqlPlan(`not json`)
+	assert.Error(t, err)
+}
+
+func TestParseMysqlTreePlan(t *testing.T) {
+	raw := "-> Nested loop inner join  (cost=12.5 rows=50) (actual time=0.0625..0.241 rows=98 loops=1)\n" +
+		"    -> Filter: (u.active = 1)  (cost=5.25 rows=5) (actual time=0.0393..0.0724 rows=10 loops=1)\n" +
+		"        -> Table scan on u  (cost=5.25 rows=50) (actual time=0.0371..0.0628 rows=50 loops=1)\n" +
+		"    -> Index lookup on o using idx_user_id (user_id=u.id)  (cost=1.5 rows=3) (never executed)\n"
+
+	plan, err := parseMysqlTreePlan(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, PlanNode{
+		Operation: "Nested loop inner join", Cost: "12.5", Rows: "50", Detail: "actual time=0.0625..0.241 rows=98 loops=1",
+		Children: []PlanNode{
+			{
+				Operation: "Filter: (u.active = 1)", Cost: "5.25", Rows: "5", Detail: "actual time=0.0393..0.0724 rows=10 loops=1",
+				Children: []PlanNode{{
+					Operation: "Table scan on u", Table: "u", Cost: "5.25", Rows: "50", FullScan: true,
+					Detail: "actual time=0.0371..0.0628 rows=50 loops=1",
+				}},
+			},
+			{
+				Operation: "Index lookup on o using idx_user_id (user_id=u.id)", Table: "o", Index: "idx_user_id",
+				Cost: "1.5", Rows: "3", Detail: "never executed",
+			},
+		},
+	}, plan)
+	assert.Equal(t, 1, plan.FullScans())
+
+	_, err = parseMysqlTreePlan("")
+	assert.EqualError(t, err, "reading plan: no steps")
+}
+
+func TestFetchPlanAnalyzeRollsBack(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	// the analyzed statement runs, its changes are rolled back
+	mock.ExpectBegin()
+	mock.ExpectQuery("EXPLAIN ANALYZE DELETE FROM users WHERE id = \\?").WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow("-> Delete from users  (actual time=0.1..0.1 rows=1 loops=1)"))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`EXPLAIN \(ANALYZE, FORMAT JSON\) DELETE FROM users`).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(`[{"Plan": {"Node Type": "ModifyTable"}}]`))
+	mock.ExpectRollback()
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "mysql"}}
+	plan, err := mysql.FetchPlan(context.Background(), "DELETE FROM users WHERE id = ?", true, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Delete from users", plan.Operation)
+
+	postgres := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "pgx"}}
+	plan, err = postgres.FetchPlan(context.Background(), "DELETE FROM users", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "ModifyTable", plan.Operation)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParsePostgresPlan(t *testing.T) {
+	raw := `[{"Plan": {"Node Type": "Hash Join", "Join Type": "Inner", "Startup Cost": 1.5, "Total Cost": 40.25,
+		"Plan Rows": 120, "Hash Cond": "(o.user_id = u.id)", "Actual Rows": 98, "Actual Loops": 1, "Actual Total Time": 0.75,
+		"Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "orders", "Startup Cost": 0, "Total Cost": 30, "Plan Rows": 2000},
+			{"Node Type": "Index Scan", "Relation Name": "users", "Index Name": "users_pkey", "Startup Cost": 0.28,
+			 "Total Cost": 8.3, "Plan Rows": 1, "Index Cond": "(id = 1)"}
+		]}, "Planning Time": 0.2, "Execution Time": 1.1}]`
+
+	plan, err := parsePostgresPlan(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, PlanNode{
+		Operation: "Hash Join (Inner)",
+		Cost:      "1.5..40.25",
+		Rows:      "120",
+		Detail:    "hash cond (o.user_id = u.id); actual rows 98, loops 1, 0.75 ms; planning 0.2 ms; execution 1.1 ms",
+		Children: []PlanNode{
+			{Operation: "Seq Scan", Table: "orders", Cost: "0..30", Rows: "2000", FullScan: true},
+			{Operation: "Index Scan", Table: "users", Index: "users_pkey", Cost: "0.28..8.3", Rows: "1", Detail: "index cond (id = 1)"},
+		},
+	}, plan)
+
+	_, err = parsePostgresPlan(`[]`)
+	assert.EqualError(t, err, "reading plan: no plan")
+}
+
+func TestParseSqlitePlan(t *testing.T) {
+	plan := parseSqlitePlan([][]string{
+		{"2", "0", "0", "SCAN o"},
+		{"5", "0", "0", "SEARCH u USING INTEGER PRIMARY KEY (rowid=?)"},
+		{"8", "0", "0", "USE TEMP B-TREE FOR ORDER BY"},
+		{"10", "8", "0", "SCAN TABLE t USING COVERING INDEX idx_t"},
+	})
+
+	assert.Equal(t, PlanNode{
+		Operation: "query plan",
+		Children: []PlanNode{
+			{Operation: "SCAN o", Access: "SCAN", Table: "o", FullScan: true},
+			{Operation: "SEARCH u USING INTEGER PRIMARY KEY (rowid=?)", Access: "SEARCH", Table: "u", Index: "INTEGER PRIMARY KEY"},
+			{Operation: "USE TEMP B-TREE FOR ORDER BY", Access: "USE", Children: []PlanNode{
+				{Operation: "SCAN TABLE t USING COVERING INDEX idx_t", Access: "SCAN", Table: "t", Index: "idx_t"},
+			}},
+		},
+	}, plan)
+}
+
+func TestFetchPlan(t *testing.T) {
+	tests := []struct {
+		name          string
+		driver        string
+		analyze       bool
+		expectedQuery string
+		columns       []string
+		row           []string
+		expectedOp    string
+	}{
+		{
+			name:          "mysql",
+			driver:        "mysql",
+			expectedQuery: "EXPLAIN FORMAT=JSON SELECT \\* FROM users WHERE id = \\?",
+			columns:       []string{"EXPLAIN"},
+			row:           []string{mysqlJoinPlan},
+			expectedOp:    "query block #1",
+		},
+		{
+			name:          "postgres",
+			driver:        "pgx",
+			expectedQuery: "EXPLAIN \\(FORMAT JSON\\) SELECT \\* FROM users WHERE id = \\?",
+			columns:       []string{"QUERY PLAN"},
+			row:           []string{`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users"}}]`},
+			expectedOp:    "Seq Scan",
+		},
+		{
+			name:          "sqlite",
+			driver:        "sqlite3",
+			expectedQuery: "EXPLAIN QUERY PLAN SELECT \\* FROM users WHERE id = \\?",
+			columns:       []string{"id", "parent", "notused", "detail"},
+			row:           []string{"2", "0", "0", "SCAN users"},
+			expectedOp:    "query plan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			values := make([]driver.Value, len(tt.row))
+			for i, value := range tt.row {
+				values[i] = value
+			}
+			mock.ExpectQuery(tt.expectedQuery).WithArgs("1").
+				WillReturnRows(sqlmock.NewRows(tt.columns).AddRow(values...))
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			plan, err := mysql.FetchPlan(context.Background(), "SELECT * FROM users WHERE id = ?", tt.analyze, "1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOp, plan.Operation)
+			assert.Equal(t, 1, plan.FullScans())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
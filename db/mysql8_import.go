@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// RowSource passes rows to each in order, stopping at the first error of each
+type RowSource func(each func(row []*string) error) error
+
+// ImportRequest describes rows to load into a table, nil values are SQL NULLs
+type ImportRequest struct {
+	Table string
+	// CREATE TABLE statement executed before loading, empty to load into an existing table
+	CreateSQL string
+	Columns   []string
+	// Rows are read once while loading, so they need not fit in memory
+	Rows RowSource
+	// delimited source file, loaded with LOAD DATA LOCAL INFILE on MySQL when the server allows it
+	Path      string
+	Delimiter string
+	LineEnd   string
+	// rows per INSERT statement
+	BatchSize int
+}
+
+// defaultImportBatchSize is the number of rows per multi-row INSERT
+const defaultImportBatchSize = 500
+
+// maxPlaceholders limits bind parameters of a single statement per dialect
+var maxPlaceholders = map[string]int{
+	DialectMysql:    65535,
+	DialectPostgres: 65535,
+	DialectSqlite:   32766,
+}
+
+// ImportRows loads rows into a table inside a transaction, optionally creating the table first.
+// The table is created in the transaction where DDL is transactional, and dropped again when a MySQL import fails.
+// progress receives the number of rows loaded so far after every batch
+func (m *Mysql8) ImportRows(ctx context.Context, request ImportRequest, progress func(done int)) (imported int, err error) {
+	dialect := m.Dialect()
+	slog.Debug("importRows: Starting import", "table", request.Table, "dialect", dialect)
+
+	if request.CreateSQL != "" && dialect == DialectMysql {
+		// CREATE TABLE commits implicitly on MySQL, so it can't be part of the transaction
+		slog.Debug("importRows: Creating table", "query", request.CreateSQL)
+		if _, err := m.Db().ExecContext(ctx, request.CreateSQL); err != nil {
+			slog.Error("importRows: Failed to create table", "error", err, "table", request.Table)
+			return 0, err
+		}
+		defer func() {
+			if err != nil {
+				m.dropImportTable(ctx, request.Table)
+			}
+		}()
+	}
+
+	tx, err := m.Db().BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("importRows: Failed to begin transaction", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if request.CreateSQL != "" && dialect != DialectMysql {
+		slog.Debug("importRows: Creating table", "query", request.CreateSQL)
+		if _, err := tx.ExecContext(ctx, request.CreateSQL); err != nil {
+			slog.Error("importRows: Failed to create table", "error", err, "table", request.Table)
+			return 0, err
+		}
+	}
+
+	if dialect == DialectMysql && request.Path != "" && request.Delimiter != "" {
+		loaded, err := loadDataInfile(ctx, tx, request)
+		if err == nil {
+			if err := tx.Commit(); err != nil {
+				slog.Error("importRows: Failed to commit", "error", err)
+				return 0, err
+			}
+			progress(loaded)
+			slog.Info("importRows: Loaded file", "table", request.Table, "rows", loaded)
+			return loaded, nil
+		}
+		slog.Warn("importRows: LOAD DATA LOCAL INFILE failed, falling back to inserts", "error", err)
+	}
+
+	batchSize := request.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	if len(request.Columns) > 0 {
+		batchSize = min(batchSize, maxPlaceholders[dialect]/len(request.Columns))
+	}
+
+	batch := make([][]*string, 0, batchSize)
+	insert := func() error {
+		query, args := insertStatement(dialect, request.Table, request.Columns, batch)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			slog.Error("importRows: Insert failed", "error", err, "table", request.Table, "row", imported+1)
+			return fmt.Errorf("rows %d-%d: %w", imported+1, imported+len(batch), err)
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		progress(imported)
+		return nil
+	}
+	err = request.Rows(func(row []*string) error {
+		if batch = append(batch, row); len(batch) == batchSize {
+			return insert()
+		}
+		return nil
+	})
+	if err == nil && len(batch) > 0 {
+		err = insert()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("importRows: Failed to commit", "error", err)
+		return 0, err
+	}
+
+	slog.Info("importRows: Import complete", "table", request.Table, "rows", imported)
+	return imported, nil
+}
+
+// dropImportTable drops a table created for an import that failed, also when the import was cancelled
+func (m *Mysql8) dropImportTable(ctx context.Context, table string) {
+	query := "DROP TABLE " + QuoteIdentifier(m.Dialect(), table)
+	if _, err := m.Db().ExecContext(context.WithoutCancel(ctx), query); err != nil {
+		slog.Error("importRows: Failed to drop created table", "error", err, "table", table)
+	}
+}
+
+// insertStatement builds a multi-row INSERT with bind parameters for the dialect
+func insertStatement(dialect string, table string, columns []string, rows [][]*string) (string, []interface{}) {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = QuoteIdentifier(dialect, column)
+	}
+
+	var b strings.Builder
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	b.WriteString("INSERT INTO " + QuoteIdentifier(dialect, table) + " (" + strings.Join(quoted, ", ") + ") VALUES ")
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		placeholders := make([]string, len(row))
+		for j, value := range row {
+			placeholders[j] = "?"
+			if dialect == DialectPostgres {
+				placeholders[j] = fmt.Sprintf("$%d", len(args)+1)
+			}
+			if value == nil {
+				args = append(args, nil)
+			} else {
+				args = append(args, *value)
+			}
+		}
+		b.WriteString("(" + strings.Join(placeholders, ", ") + ")")
+	}
+	return b.String(), args
+}
+
+// loadDataInfile loads a delimited file with LOAD DATA LOCAL INFILE, empty fields become NULLs.
+// It fails when local_infile is disabled on the server
+func loadDataInfile(ctx context.Context, tx *sql.Tx, request ImportRequest) (int, error) {
+	mysql.RegisterLocalFile(request.Path)
+	defer mysql.DeregisterLocalFile(request.Path)
+
+	variables := make([]string, len(request.Columns))
+	assignments := make([]string, len(request.Columns))
+	for i, column := range request.Columns {
+		variables[i] = fmt.Sprintf("@c%d", i)
+		assignments[i] = fmt.Sprintf("%s = NULLIF(@c%d, '')", QuoteIdentifier(DialectMysql, column), i)
+	}
+
+	query := fmt.Sprintf(
+		"LOAD DATA LOCAL INFILE %s INTO TABLE %s CHARACTER SET utf8mb4 "+
+			"FIELDS TERMINATED BY %s OPTIONALLY ENCLOSED BY '\"' ESCAPED BY '' "+
+			"LINES TERMINATED BY %s IGNORE 1 LINES (%s) SET %s",
+		mysqlString(request.Path), QuoteIdentifier(DialectMysql, request.Table),
+		mysqlString(request.Delimiter), mysqlString(request.LineEnd),
+		strings.Join(variables, ", "), strings.Join(assignments, ", "),
+	)
+	slog.Debug("loadDataInfile: Executing query", "query", query)
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	loaded, err := result.RowsAffected()
+	return int(loaded), err
+}
+
+// mysqlString renders a MySQL string literal
+func mysqlString(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\t", `\t`, "\r", `\r`, "\n", `\n`)
+	return "'" + replacer.Replace(value) + "'"
+}
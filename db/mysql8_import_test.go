@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportRows(t *testing.T) {
+	one, two, three := "1", "2", "3"
+	name := "John"
+	request := ImportRequest{
+		Table:     "users",
+		CreateSQL: "CREATE TABLE `users` (`id` BIGINT, `name` VARCHAR(255))",
+		Columns:   []string{"id", "name"},
+		Rows:      rowsOf([][]*string{{&one, &name}, {&two, nil}, {&three, nil}}),
+		BatchSize: 2,
+	}
+
+	tests := []struct {
+		name          string
+		driver        string
+		request       ImportRequest
+		setup         func(mock sqlmock.Sqlmock)
+		expectedDone  []int
+		expectedError string
+	}{
+		{
+			name:    "create and insert in batches",
+			request: request,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("CREATE TABLE `users`").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `users` \\(`id`, `name`\\) VALUES \\(\\?, \\?\\), \\(\\?, \\?\\)").
+					WithArgs("1", "John", "2", nil).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("INSERT INTO `users` \\(`id`, `name`\\) VALUES \\(\\?, \\?\\)$").
+					WithArgs("3", nil).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedDone: []int{2, 3},
+		},
+		{
+			name:   "postgres placeholders",
+			driver: "pgx",
+			request: ImportRequest{
+				Table: "users", Columns: []string{"id", "name"}, Rows: rowsOf([][]*string{{&one, &name}, {&two, nil}}),
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO "users" \("id", "name"\) VALUES \(\$1, \$2\), \(\$3, \$4\)`).
+					WithArgs("1", "John", "2", nil).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			expectedDone: []int{2},
+		},
+		{
+			name: "load data falls back to inserts",
+			request: ImportRequest{
+				Table: "users", Columns: []string{"id"}, Rows: rowsOf([][]*string{{&one}}),
+				Path: "users.csv", Delimiter: ",", LineEnd: "\r\n",
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("LOAD DATA LOCAL INFILE 'users.csv' INTO TABLE `users` .* FIELDS TERMINATED BY ',' .* LINES TERMINATED BY '\\\\r\\\\n' IGNORE 1 LINES \\(@c0\\) SET `id` = NULLIF\\(@c0, ''\\)").
+					WillReturnError(errors.New("Loading local data is disabled"))
+				mock.ExpectExec("INSERT INTO `users`").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedDone: []int{1},
+		},
+		{
+			name:    "created table is dropped when the import fails",
+			request: ImportRequest{Table: "users", CreateSQL: request.CreateSQL, Columns: []string{"id"}, Rows: rowsOf([][]*string{{&one}})},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("CREATE TABLE `users`").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `users`").WillReturnError(errors.New("data too long"))
+				mock.ExpectRollback()
+				mock.ExpectExec("DROP TABLE `users`").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: "rows 1-1: data too long",
+		},
+		{
+			name:   "postgres creates the table in the transaction",
+			driver: "pgx",
+			request: ImportRequest{
+				Table: "users", CreateSQL: `CREATE TABLE "users" ("id" BIGINT)`, Columns: []string{"id"}, Rows: rowsOf([][]*string{{&one}}),
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`CREATE TABLE "users"`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO "users"`).WillReturnError(errors.New("canceling statement"))
+				mock.ExpectRollback()
+			},
+			expectedError: "rows 1-1: canceling statement",
+		},
+		{
+			name:    "failed insert rolls back",
+			request: ImportRequest{Table: "users", Columns: []string{"id"}, Rows: rowsOf([][]*string{{&one}})},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `users`").WillReturnError(errors.New("duplicate key"))
+				mock.ExpectRollback()
+			},
+			expectedError: "rows 1-1: duplicate key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+			tt.setup(mock)
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			var done []int
+			imported, err := mysql.ImportRows(context.Background(), tt.request, func(n int) {
+				done = append(done, n)
+			})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedDone[len(tt.expectedDone)-1], imported)
+			}
+			assert.Equal(t, tt.expectedDone, done)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// rowsOf streams rows held in memory
+func rowsOf(rows [][]*string) RowSource {
+	return func(each func(row []*string) error) error {
+		for _, row := range rows {
+			if err := each(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
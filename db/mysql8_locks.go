@@ -0,0 +1,68 @@
+package db
+
+import "context"
+
+// LockWait is a session waiting for a lock held by another session
+type LockWait struct {
+	WaitingID    string
+	WaitingUser  string
+	WaitingQuery string
+	BlockingID   string
+	BlockingUser string
+	// BlockingQuery is the running statement of the blocker, NULL when it idles in an open transaction
+	BlockingQuery string
+	// Object is the locked table, with the index of row locks
+	Object string
+	Mode   string
+	// Seconds is how long the waiting statement has run
+	Seconds string
+}
+
+// lockWaitQueries pair waiting and blocking sessions, from performance_schema.data_lock_waits on MySQL 8
+// and pg_blocking_pids on Postgres
+var lockWaitQueries = map[string]string{
+	DialectMysql: `
+		SELECT wt.PROCESSLIST_ID, wt.PROCESSLIST_USER, wt.PROCESSLIST_INFO,
+			bt.PROCESSLIST_ID, bt.PROCESSLIST_USER, bt.PROCESSLIST_INFO,
+			CONCAT(wl.OBJECT_SCHEMA, '.', wl.OBJECT_NAME, IFNULL(CONCAT(' ', wl.INDEX_NAME), '')),
+			CONCAT(wl.LOCK_TYPE, ' ', wl.LOCK_MODE),
+			IFNULL(wt.PROCESSLIST_TIME, 0)
+		FROM performance_schema.data_lock_waits w
+		JOIN performance_schema.data_locks wl ON wl.ENGINE_LOCK_ID = w.REQUESTING_ENGINE_LOCK_ID
+		JOIN performance_schema.threads wt ON wt.THREAD_ID = w.REQUESTING_THREAD_ID
+		JOIN performance_schema.threads bt ON bt.THREAD_ID = w.BLOCKING_THREAD_ID
+		ORDER BY wt.PROCESSLIST_TIME DESC, wt.PROCESSLIST_ID
+	`,
+	DialectPostgres: `
+		SELECT w.pid, w.usename, w.query, b.pid, b.usename, b.query,
+			COALESCE(l.relation::regclass::text, l.locktype, ''), COALESCE(l.mode, ''),
+			COALESCE(EXTRACT(EPOCH FROM now() - w.query_start)::bigint, 0)
+		FROM pg_stat_activity w
+		CROSS JOIN LATERAL unnest(pg_blocking_pids(w.pid)) AS blocker(pid)
+		JOIN pg_stat_activity b ON b.pid = blocker.pid
+		LEFT JOIN pg_locks l ON l.pid = w.pid AND NOT l.granted
+		ORDER BY 9 DESC, w.pid
+	`,
+}
+
+// FetchLockWaits queries the sessions waiting for locks and the sessions holding them, the longest waiting first
+func (m *Mysql8) FetchLockWaits(ctx context.Context) ([]LockWait, error) {
+	query, ok := lockWaitQueries[m.Dialect()]
+	if !ok {
+		return nil, ErrNoSessions
+	}
+	rows, err := m.fetchStrings(ctx, "fetchLockWaits", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var waits []LockWait
+	for _, row := range rows {
+		waits = append(waits, LockWait{
+			WaitingID: row[0], WaitingUser: row[1], WaitingQuery: row[2],
+			BlockingID: row[3], BlockingUser: row[4], BlockingQuery: row[5],
+			Object: row[6], Mode: row[7], Seconds: row[8],
+		})
+	}
+	return waits, nil
+}
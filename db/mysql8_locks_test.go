@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchLockWaits(t *testing.T) {
+	tests := []struct {
+		name          string
+		driver        string
+		expectedQuery string
+	}{
+		{name: "mysql", driver: "mysql", expectedQuery: "FROM performance_schema.data_lock_waits"},
+		{name: "postgres", driver: "pgx", expectedQuery: "pg_blocking_pids"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			mock.ExpectQuery(tt.expectedQuery).WillReturnRows(sqlmock.NewRows(
+				[]string{"waiting", "waiting_user", "waiting_query", "blocking", "blocking_user", "blocking_query", "object", "mode", "seconds"}).
+				AddRow("42", "report", "SELECT 1 FOR SHARE", "7", "app", nil, "shop.orders", "RECORD S", "12"))
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			waits, err := mysql.FetchLockWaits(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, []LockWait{{
+				WaitingID: "42", WaitingUser: "report", WaitingQuery: "SELECT 1 FOR SHARE",
+				BlockingID: "7", BlockingUser: "app", BlockingQuery: "NULL",
+				Object: "shop.orders", Mode: "RECORD S", Seconds: "12",
+			}}, waits)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchLockWaitsErrors(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlite := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "sqlite3"}}
+	_, err = sqlite.FetchLockWaits(context.Background())
+	assert.ErrorIs(t, err, ErrNoSessions)
+
+	mock.ExpectQuery("data_lock_waits").WillReturnError(sql.ErrConnDone)
+	mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "mysql"}}
+	_, err = mysql.FetchLockWaits(context.Background())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+}
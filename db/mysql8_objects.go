@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// definitionColumns maps object kind to the SHOW CREATE column that holds its source
+var definitionColumns = map[string]string{
+	ObjectView:      "Create View",
+	ObjectProcedure: "Create Procedure",
+	ObjectFunction:  "Create Function",
+	ObjectTrigger:   "SQL Original Statement",
+	ObjectEvent:     "Create Event",
+	ObjectSequence:  "Create Table",
+}
+
+// FetchViews queries views of the current database
+func (m *Mysql8) FetchViews(ctx context.Context) ([]string, []TableData) {
+	headers := []string{"NAME", "UPDATABLE", "CHECK OPTION", "SECURITY", "DEFINER"}
+
+	query := `
+		SELECT TABLE_NAME, IS_UPDATABLE, CHECK_OPTION, SECURITY_TYPE, DEFINER
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA = DATABASE()
+		ORDER BY TABLE_NAME
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchViews", query)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlView{Name: row[0], Updatable: row[1], CheckOption: row[2], Security: row[3], Definer: row[4]})
+	}
+	return headers, tableData
+}
+
+// FetchRoutines queries stored procedures or functions depending on kind
+func (m *Mysql8) FetchRoutines(ctx context.Context, kind string) ([]string, []TableData) {
+	headers := []string{"NAME", "TYPE", "RETURNS", "DETERMINISTIC", "SECURITY", "DEFINER", "MODIFIED"}
+
+	query := `
+		SELECT ROUTINE_NAME, ROUTINE_TYPE, DTD_IDENTIFIER, IS_DETERMINISTIC, SECURITY_TYPE, DEFINER, LAST_ALTERED
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = DATABASE() AND ROUTINE_TYPE = ?
+		ORDER BY ROUTINE_NAME
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchRoutines", query, kind)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlRoutine{
+			Name: row[0], Type: row[1], Returns: row[2], Deterministic: row[3], Security: row[4], Definer: row[5], Modified: row[6],
+		})
+	}
+	return headers, tableData
+}
+
+// FetchTriggers queries all triggers of the current database
+func (m *Mysql8) FetchTriggers(ctx context.Context) ([]string, []TableData) {
+	headers := []string{"NAME", "TABLE", "TIMING", "EVENT", "CREATED"}
+
+	query := `
+		SELECT TRIGGER_NAME, EVENT_OBJECT_TABLE, ACTION_TIMING, EVENT_MANIPULATION, CREATED
+		FROM information_schema.TRIGGERS
+		WHERE TRIGGER_SCHEMA = DATABASE()
+		ORDER BY TRIGGER_NAME
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchTriggers", query)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlTriggerSummary{Name: row[0], Table: row[1], Timing: row[2], Event: row[3], Created: row[4]})
+	}
+	return headers, tableData
+}
+
+// FetchEvents queries scheduled events of the current database
+func (m *Mysql8) FetchEvents(ctx context.Context) ([]string, []TableData) {
+	headers := []string{"NAME", "TYPE", "SCHEDULE", "STATUS", "STARTS", "ENDS"}
+
+	query := `
+		SELECT
+			EVENT_NAME,
+			EVENT_TYPE,
+			IFNULL(CONCAT('EVERY ', INTERVAL_VALUE, ' ', INTERVAL_FIELD), EXECUTE_AT),
+			STATUS,
+			STARTS,
+			ENDS
+		FROM information_schema.EVENTS
+		WHERE EVENT_SCHEMA = DATABASE()
+		ORDER BY EVENT_NAME
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchEvents", query)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlEvent{Name: row[0], Type: row[1], Schedule: row[2], Status: row[3], Starts: row[4], Ends: row[5]})
+	}
+	return headers, tableData
+}
+
+// FetchSequences queries sequences of the current database.
+// MySQL has no sequences, MariaDB reports them as tables of type SEQUENCE
+func (m *Mysql8) FetchSequences(ctx context.Context) ([]string, []TableData) {
+	headers := []string{"NAME"}
+
+	query := `
+		SELECT TABLE_NAME
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'SEQUENCE'
+		ORDER BY TABLE_NAME
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchSequences", query)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlSequence{Name: row[0]})
+	}
+	return headers, tableData
+}
+
+// FetchObjectDefinition queries source of a schema object using SHOW CREATE <kind>
+func (m *Mysql8) FetchObjectDefinition(ctx context.Context, kind string, name string) string {
+	slog.Debug("fetchObjectDefinition: Getting definition", "kind", kind, "name", name)
+
+	column, ok := definitionColumns[kind]
+	if !ok {
+		slog.Error("fetchObjectDefinition: Unsupported object kind", "kind", kind)
+		return ""
+	}
+
+	query := fmt.Sprintf("SHOW CREATE %s `%s`", kind, name)
+	slog.Debug("fetchObjectDefinition: Executing query", "query", query)
+
+	rows, err := m.Db().QueryContext(ctx, query)
+	if err != nil {
+		slog.Error("fetchObjectDefinition: Query failed", "error", err, "kind", kind, "name", name)
+		return ""
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil || !rows.Next() {
+		slog.Error("fetchObjectDefinition: No definition returned", "error", err, "kind", kind, "name", name)
+		return ""
+	}
+
+	values := make([]sql.NullString, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	if err := rows.Scan(valuePtrs...); err != nil {
+		slog.Error("fetchObjectDefinition: Failed to scan definition", "error", err, "kind", kind, "name", name)
+		return ""
+	}
+
+	for i, columnName := range columns {
+		if columnName == column {
+			// definition is NULL when the user lacks privileges to see it
+			return values[i].String
+		}
+	}
+
+	slog.Error("fetchObjectDefinition: Definition column not found", "column", column, "columns", columns)
+	return ""
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchObjectListings(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("FROM information_schema.VIEWS").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d", "e"}).
+			AddRow("active_users", "YES", "NONE", "DEFINER", "root@%"))
+	mock.ExpectQuery("FROM information_schema.ROUTINES").WithArgs(ObjectFunction).
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d", "e", "f", "g"}).
+			AddRow("tax", "FUNCTION", "decimal(10,2)", "YES", "DEFINER", "root@%", "2024-01-01 00:00:00"))
+	mock.ExpectQuery("FROM information_schema.TRIGGERS").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d", "e"}).
+			AddRow("orders_bi", "orders", "BEFORE", "INSERT", nil))
+	mock.ExpectQuery("FROM information_schema.EVENTS").
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectQuery("TABLE_TYPE = 'SEQUENCE'").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}))
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+	ctx := context.Background()
+
+	_, views := mysql.FetchViews(ctx)
+	assert.Equal(t, []TableData{MysqlView{Name: "active_users", Updatable: "YES", CheckOption: "NONE", Security: "DEFINER", Definer: "root@%"}}, views)
+
+	_, functions := mysql.FetchRoutines(ctx, ObjectFunction)
+	assert.Len(t, functions, 1)
+	assert.Equal(t, "decimal(10,2)", functions[0].(MysqlRoutine).Returns)
+
+	_, triggers := mysql.FetchTriggers(ctx)
+	assert.Equal(t, []TableData{MysqlTriggerSummary{Name: "orders_bi", Table: "orders", Timing: "BEFORE", Event: "INSERT", Created: "NULL"}}, triggers)
+
+	headers, events := mysql.FetchEvents(ctx)
+	assert.Equal(t, []string{}, headers)
+	assert.Empty(t, events)
+
+	headers, sequences := mysql.FetchSequences(ctx)
+	assert.Equal(t, []string{"NAME"}, headers)
+	assert.Empty(t, sequences)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchObjectDefinition(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      string
+		mockSetup func(sqlmock.Sqlmock)
+		expected  string
+	}{
+		{
+			name: "view definition",
+			kind: ObjectView,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"View", "Create View", "character_set_client", "collation_connection"}).
+					AddRow("v", "CREATE VIEW `v` AS select 1", "utf8mb4", "utf8mb4_0900_ai_ci")
+				mock.ExpectQuery("SHOW CREATE VIEW `v`").WillReturnRows(rows)
+			},
+			expected: "CREATE VIEW `v` AS select 1",
+		},
+		{
+			name: "trigger definition",
+			kind: ObjectTrigger,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"Trigger", "sql_mode", "SQL Original Statement", "character_set_client"}).
+					AddRow("v", "STRICT_TRANS_TABLES", "CREATE TRIGGER `v` BEFORE INSERT ON t FOR EACH ROW SET @x = 1", "utf8mb4")
+				mock.ExpectQuery("SHOW CREATE TRIGGER `v`").WillReturnRows(rows)
+			},
+			expected: "CREATE TRIGGER `v` BEFORE INSERT ON t FOR EACH ROW SET @x = 1",
+		},
+		{
+			name: "hidden procedure body",
+			kind: ObjectProcedure,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"Procedure", "sql_mode", "Create Procedure"}).
+					AddRow("v", "", nil)
+				mock.ExpectQuery("SHOW CREATE PROCEDURE `v`").WillReturnRows(rows)
+			},
+			expected: "",
+		},
+		{
+			name:      "unsupported kind",
+			kind:      "PACKAGE",
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			expected:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			tt.mockSetup(mock)
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+			result := mysql.FetchObjectDefinition(context.Background(), tt.kind, "v")
+
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
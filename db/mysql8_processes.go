@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrNoSessions is returned for databases without sessions to list or kill, such as SQLite
+var ErrNoSessions = errors.New("no sessions on this database")
+
+// FetchProcesses queries the sessions connected to the server, the longest running first.
+// MySQL reads information_schema.PROCESSLIST, Postgres the client backends of pg_stat_activity.
+// Time is in seconds and State falls back to the command of idle MySQL sessions
+func (m *Mysql8) FetchProcesses(ctx context.Context) ([]string, []TableData) {
+	headers := []string{"ID", "USER", "HOST", "DB", "STATE", "TIME", "QUERY"}
+
+	var query string
+	switch m.Dialect() {
+	case DialectSqlite:
+		return headers, []TableData{}
+	case DialectPostgres:
+		query = `
+			SELECT pid, usename, COALESCE(client_hostname, host(client_addr), 'local'), datname,
+				COALESCE(NULLIF(wait_event_type || ': ' || wait_event, ''), state),
+				COALESCE(EXTRACT(EPOCH FROM now() - query_start)::bigint, 0), query
+			FROM pg_stat_activity
+			WHERE backend_type = 'client backend'
+			ORDER BY query_start NULLS LAST
+		`
+	default:
+		query = `
+			SELECT ID, USER, HOST, DB, IF(STATE IS NULL OR STATE = '', COMMAND, STATE), TIME, INFO
+			FROM information_schema.PROCESSLIST
+			ORDER BY TIME DESC, ID
+		`
+	}
+
+	rows, err := m.fetchStrings(ctx, "fetchProcesses", query)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	tableData := []TableData{}
+	for _, row := range rows {
+		tableData = append(tableData, MysqlProcess{
+			ID: row[0], User: row[1], Host: row[2], Db: row[3], State: row[4], Time: row[5], Query: row[6],
+		})
+	}
+	return headers, tableData
+}
+
+// KillSession ends a session and its running statement, with KILL on MySQL and pg_terminate_backend on Postgres
+func (m *Mysql8) KillSession(ctx context.Context, id string) error {
+	number, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid session id %q", id)
+	}
+
+	switch m.Dialect() {
+	case DialectSqlite:
+		return ErrNoSessions
+	case DialectPostgres:
+		rows, err := m.fetchStrings(ctx, "killSession", "SELECT pg_terminate_backend($1)", number)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 || rows[0][0] != "true" {
+			return fmt.Errorf("session %d was not terminated", number)
+		}
+		return nil
+	default:
+		// KILL takes no placeholder, the id is a number
+		_, err := m.Db().ExecContext(ctx, fmt.Sprintf("KILL %d", number))
+		return err
+	}
+}
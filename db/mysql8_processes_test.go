@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchProcesses(t *testing.T) {
+	tests := []struct {
+		name          string
+		driver        string
+		expectedQuery string
+		expected      []TableData
+	}{
+		{
+			name:          "mysql",
+			driver:        "mysql",
+			expectedQuery: "FROM information_schema.PROCESSLIST",
+			expected: []TableData{
+				MysqlProcess{ID: "42", User: "report", Host: "10.0.0.7:51234", Db: "shop", State: "executing", Time: "312", Query: "SELECT 1"},
+				MysqlProcess{ID: "7", User: "app", Host: "10.0.0.5:40112", Db: "NULL", State: "Sleep", Time: "3", Query: "NULL"},
+			},
+		},
+		{
+			name:          "postgres",
+			driver:        "pgx",
+			expectedQuery: "FROM pg_stat_activity",
+			expected: []TableData{
+				MysqlProcess{ID: "42", User: "report", Host: "10.0.0.7:51234", Db: "shop", State: "executing", Time: "312", Query: "SELECT 1"},
+				MysqlProcess{ID: "7", User: "app", Host: "10.0.0.5:40112", Db: "NULL", State: "Sleep", Time: "3", Query: "NULL"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			mock.ExpectQuery(tt.expectedQuery).WillReturnRows(sqlmock.NewRows([]string{"id", "user", "host", "db", "state", "time", "query"}).
+				AddRow("42", "report", "10.0.0.7:51234", "shop", "executing", "312", "SELECT 1").
+				AddRow("7", "app", "10.0.0.5:40112", nil, "Sleep", "3", nil))
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			headers, data := mysql.FetchProcesses(context.Background())
+
+			assert.Equal(t, []string{"ID", "USER", "HOST", "DB", "STATE", "TIME", "QUERY"}, headers)
+			assert.Equal(t, tt.expected, data)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchProcessesSqlite(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "sqlite3"}}
+	_, data := mysql.FetchProcesses(context.Background())
+
+	assert.Empty(t, data)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKillSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		driver    string
+		id        string
+		mockSetup func(sqlmock.Sqlmock)
+		expected  string
+	}{
+		{
+			name:   "mysql",
+			driver: "mysql",
+			id:     "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("KILL 42").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:   "postgres",
+			driver: "pgx",
+			id:     "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT pg_terminate_backend").WithArgs(int64(42)).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_terminate_backend"}).AddRow("true"))
+			},
+		},
+		{
+			name:   "postgres session already gone",
+			driver: "pgx",
+			id:     "42",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT pg_terminate_backend").WithArgs(int64(42)).
+					WillReturnRows(sqlmock.NewRows([]string{"pg_terminate_backend"}).AddRow("false"))
+			},
+			expected: "session 42 was not terminated",
+		},
+		{
+			name:      "id is no number",
+			driver:    "mysql",
+			id:        "42; DROP TABLE users",
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			expected:  `invalid session id "42; DROP TABLE users"`,
+		},
+		{
+			name:      "sqlite",
+			driver:    "sqlite3",
+			id:        "1",
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+			expected:  ErrNoSessions.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+			tt.mockSetup(mock)
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			err = mysql.KillSession(context.Background(), tt.id)
+
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
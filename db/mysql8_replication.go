@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// ReplicationSource is the server this one replicates from
+	ReplicationSource = "source"
+	// ReplicationReplica is a server replicating from this one
+	ReplicationReplica = "replica"
+)
+
+// ReplicationLink is a replication channel of the server, from its source or to one of its replicas
+type ReplicationLink struct {
+	Role  string
+	Host  string
+	State string
+	// Running is false when the channel stopped, such as a replica thread that is not running
+	Running bool
+	// LagSeconds is how far the replica is behind, -1 when unknown
+	LagSeconds int64
+	LastError  string
+}
+
+// postgresReplicationQuery lists the standbys streaming from this server and, on a standby, its WAL receiver.
+// Standbys starting up or catching up run as well as streaming ones, as does a receiver waiting for WAL.
+// A standby that replayed all it received has no lag however long the primary was idle
+const postgresReplicationQuery = `
+	SELECT 'replica', application_name || ' ' || COALESCE(client_addr::text, 'local'), state,
+		state IN ('startup', 'catchup', 'streaming'), COALESCE(EXTRACT(EPOCH FROM replay_lag)::bigint, -1), ''
+	FROM pg_stat_replication
+	UNION ALL
+	SELECT 'source', COALESCE(sender_host, '') || ':' || COALESCE(sender_port::text, ''), status,
+		status NOT IN ('stopped', 'stopping'),
+		CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::bigint, -1) END,
+		''
+	FROM pg_stat_wal_receiver
+	UNION ALL
+	SELECT 'source', '', 'stopped', false, -1, 'WAL receiver is not running'
+	WHERE pg_is_in_recovery() AND NOT EXISTS (SELECT 1 FROM pg_stat_wal_receiver)
+`
+
+// FetchReplication queries the replication channels of the server, with SHOW REPLICA STATUS on MySQL,
+// SHOW SLAVE STATUS before 8.0.22, and pg_stat_replication and pg_stat_wal_receiver on Postgres.
+// A server that doesn't replicate, and SQLite, has none
+func (m *Mysql8) FetchReplication(ctx context.Context) ([]ReplicationLink, error) {
+	switch m.Dialect() {
+	case DialectSqlite:
+		return nil, nil
+	case DialectPostgres:
+		rows, err := m.fetchStrings(ctx, "fetchReplication", postgresReplicationQuery)
+		if err != nil {
+			return nil, err
+		}
+		var links []ReplicationLink
+		for _, row := range rows {
+			lag, _ := strconv.ParseInt(row[4], 10, 64)
+			links = append(links, ReplicationLink{
+				Role: row[0], Host: row[1], State: row[2], Running: row[3] == "true", LagSeconds: lag, LastError: row[5],
+			})
+		}
+		return links, nil
+	}
+
+	columns, rows, err := m.fetchColumnsAndStrings(ctx, "fetchReplication", "SHOW REPLICA STATUS")
+	if err != nil {
+		columns, rows, err = m.fetchColumnsAndStrings(ctx, "fetchReplication", "SHOW SLAVE STATUS")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var links []ReplicationLink
+	for _, row := range rows {
+		// a column by its name or the name before 8.0.22
+		field := func(name string, old string) string {
+			for i, column := range columns {
+				if column == name || column == old {
+					return row[i]
+				}
+			}
+			return ""
+		}
+		io, sql := field("Replica_IO_Running", "Slave_IO_Running"), field("Replica_SQL_Running", "Slave_SQL_Running")
+		lag, err := strconv.ParseInt(field("Seconds_Behind_Source", "Seconds_Behind_Master"), 10, 64)
+		if err != nil {
+			lag = -1
+		}
+		lastError := field("Last_IO_Error", "Last_IO_Error")
+		if lastError == "" {
+			lastError = field("Last_SQL_Error", "Last_SQL_Error")
+		}
+		host := field("Source_Host", "Master_Host") + ":" + field("Source_Port", "Master_Port")
+		if channel := field("Channel_Name", "Channel_Name"); channel != "" {
+			host += " (" + channel + ")"
+		}
+		links = append(links, ReplicationLink{
+			Role: ReplicationSource, Host: host, State: fmt.Sprintf("IO %s, SQL %s", io, sql),
+			Running: io == "Yes" && sql == "Yes", LagSeconds: lag, LastError: lastError,
+		})
+	}
+	return links, nil
+}
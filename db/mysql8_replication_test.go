@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchReplicationMysql(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	// columns are read by name, a stopped SQL thread reports its error
+	mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(sqlmock.NewRows([]string{
+		"Source_Host", "Source_Port", "Replica_IO_Running", "Replica_SQL_Running", "Seconds_Behind_Source",
+		"Last_IO_Error", "Last_SQL_Error", "Channel_Name",
+	}).AddRow("db-primary", "3306", "Yes", "Yes", "4", "", "", "").
+		AddRow("db-archive", "3306", "Yes", "No", nil, "", "Duplicate entry '1'", "archive"))
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "mysql"}}
+	links, err := mysql.FetchReplication(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ReplicationLink{
+		{Role: ReplicationSource, Host: "db-primary:3306", State: "IO Yes, SQL Yes", Running: true, LagSeconds: 4},
+		{Role: ReplicationSource, Host: "db-archive:3306 (archive)", State: "IO Yes, SQL No", LagSeconds: -1,
+			LastError: "Duplicate entry '1'"},
+	}, links)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchReplicationBefore8022(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnError(errors.New("You have an error in your SQL syntax"))
+	mock.ExpectQuery("SHOW SLAVE STATUS").WillReturnRows(sqlmock.NewRows([]string{
+		"Master_Host", "Master_Port", "Slave_IO_Running", "Slave_SQL_Running", "Seconds_Behind_Master",
+	}).AddRow("db-primary", "3306", "Yes", "Yes", "0"))
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "mysql"}}
+	links, err := mysql.FetchReplication(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ReplicationLink{
+		{Role: ReplicationSource, Host: "db-primary:3306", State: "IO Yes, SQL Yes", Running: true},
+	}, links)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchReplicationPostgres(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	// standbys catching up run as well
+	mock.ExpectQuery(`state IN \('startup', 'catchup', 'streaming'\)`).WillReturnRows(sqlmock.NewRows([]string{
+		"role", "host", "state", "running", "lag", "error",
+	}).AddRow("replica", "standby1 10.0.0.2", "streaming", "true", "2", "").
+		AddRow("replica", "standby2 10.0.0.3", "catchup", "true", "40", ""))
+
+	postgres := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "pgx"}}
+	links, err := postgres.FetchReplication(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ReplicationLink{
+		{Role: ReplicationReplica, Host: "standby1 10.0.0.2", State: "streaming", Running: true, LagSeconds: 2},
+		{Role: ReplicationReplica, Host: "standby2 10.0.0.3", State: "catchup", Running: true, LagSeconds: 40},
+	}, links)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	sqlite := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "sqlite3"}}
+	links, err = sqlite.FetchReplication(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, links)
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// fetchStrings executes a query and returns every row as strings, NULL values become "NULL"
+func (m *Mysql8) fetchStrings(ctx context.Context, caller string, query string, args ...interface{}) ([][]string, error) {
+	_, result, err := m.fetchColumnsAndStrings(ctx, caller, query, args...)
+	return result, err
+}
+
+// fetchColumnsAndStrings is fetchStrings also returning the column names, for statements such as SHOW
+// whose columns differ between server versions
+func (m *Mysql8) fetchColumnsAndStrings(ctx context.Context, caller string, query string, args ...interface{}) ([]string, [][]string, error) {
+	return queryStrings(ctx, m.Db(), caller, query, args...)
+}
+
+// queryStrings is fetchColumnsAndStrings with an executor, such as a transaction
+func queryStrings(ctx context.Context, executor sqlExecutor, caller string, query string, args ...interface{}) ([]string, [][]string, error) {
+	slog.Debug(caller+": Executing query", "query", query, "args", args)
+
+	rows, err := executor.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Error(caller+": Query failed", "error", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		slog.Error(caller+": Failed to get column names", "error", err)
+		return nil, nil, err
+	}
+
+	var result [][]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			slog.Warn(caller+": Failed to scan row, skipping", "error", err)
+			continue
+		}
+
+		fields := make([]string, len(columns))
+		for i, value := range values {
+			if value.Valid {
+				fields[i] = value.String
+			} else {
+				fields[i] = "NULL"
+			}
+		}
+		result = append(result, fields)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error(caller+": Error during row iteration", "error", err)
+		return nil, nil, err
+	}
+
+	slog.Debug(caller+": Processing complete", "rowsFound", len(result))
+	return columns, result, nil
+}
+
+// FetchTableColumns queries column definitions of a table
+func (m *Mysql8) FetchTableColumns(ctx context.Context, name string) ([]string, []TableData) {
+	headers := []string{"NAME", "TYPE", "NULLABLE", "DEFAULT", "KEY", "EXTRA", "COMMENT"}
+
+	query := `
+		SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COLUMN_KEY, EXTRA, COLUMN_COMMENT
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchTableColumns", query, name)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlColumn{
+			Name: row[0], Type: row[1], Nullable: row[2], Default: row[3], Key: row[4], Extra: row[5], Comment: row[6],
+		})
+	}
+	return headers, tableData
+}
+
+// FetchTableIndexes queries indexes of a table, one row per index
+func (m *Mysql8) FetchTableIndexes(ctx context.Context, name string) ([]string, []TableData) {
+	headers := []string{"NAME", "COLUMNS", "UNIQUE", "CARDINALITY", "TYPE"}
+
+	query := `
+		SELECT
+			INDEX_NAME,
+			GROUP_CONCAT(IFNULL(COLUMN_NAME, EXPRESSION) ORDER BY SEQ_IN_INDEX SEPARATOR ', '),
+			IF(MIN(NON_UNIQUE) = 0, 'YES', 'NO'),
+			IFNULL(MAX(CARDINALITY), 0),
+			INDEX_TYPE
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		GROUP BY INDEX_NAME, INDEX_TYPE
+		ORDER BY INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchTableIndexes", query, name)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlIndex{
+			Name: row[0], Columns: row[1], Unique: row[2], Cardinality: row[3], Type: row[4],
+		})
+	}
+	return headers, tableData
+}
+
+// FetchTableForeignKeys queries foreign keys declared on a table
+func (m *Mysql8) FetchTableForeignKeys(ctx context.Context, name string) ([]string, []TableData) {
+	headers := []string{"NAME", "COLUMNS", "REFERENCES", "REF COLUMNS", "ON UPDATE", "ON DELETE"}
+
+	query := `
+		SELECT
+			k.CONSTRAINT_NAME,
+			GROUP_CONCAT(k.COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ', '),
+			k.REFERENCED_TABLE_NAME,
+			GROUP_CONCAT(k.REFERENCED_COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ', '),
+			r.UPDATE_RULE,
+			r.DELETE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+			ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		WHERE k.TABLE_SCHEMA = DATABASE() AND k.TABLE_NAME = ?
+		GROUP BY k.CONSTRAINT_NAME, k.REFERENCED_TABLE_NAME, r.UPDATE_RULE, r.DELETE_RULE
+		ORDER BY k.CONSTRAINT_NAME
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchTableForeignKeys", query, name)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlForeignKey{
+			Name: row[0], Columns: row[1], RefTable: row[2], RefColumns: row[3], OnUpdate: row[4], OnDelete: row[5],
+		})
+	}
+	return headers, tableData
+}
+
+// FetchTableChecks queries check constraints of a table (MySQL 8.0.16+)
+func (m *Mysql8) FetchTableChecks(ctx context.Context, name string) ([]string, []TableData) {
+	headers := []string{"NAME", "CLAUSE", "ENFORCED"}
+
+	query := `
+		SELECT tc.CONSTRAINT_NAME, cc.CHECK_CLAUSE, tc.ENFORCED
+		FROM information_schema.TABLE_CONSTRAINTS tc
+		JOIN information_schema.CHECK_CONSTRAINTS cc
+			ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE tc.TABLE_SCHEMA = DATABASE() AND tc.TABLE_NAME = ? AND tc.CONSTRAINT_TYPE = 'CHECK'
+		ORDER BY tc.CONSTRAINT_NAME
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchTableChecks", query, name)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlCheck{Name: row[0], Clause: row[1], Enforced: row[2]})
+	}
+	return headers, tableData
+}
+
+// FetchTableTriggers queries triggers attached to a table
+func (m *Mysql8) FetchTableTriggers(ctx context.Context, name string) ([]string, []TableData) {
+	headers := []string{"NAME", "TIMING", "EVENT", "STATEMENT"}
+
+	query := `
+		SELECT TRIGGER_NAME, ACTION_TIMING, EVENT_MANIPULATION, ACTION_STATEMENT
+		FROM information_schema.TRIGGERS
+		WHERE TRIGGER_SCHEMA = DATABASE() AND EVENT_OBJECT_TABLE = ?
+		ORDER BY ACTION_TIMING, EVENT_MANIPULATION, ACTION_ORDER
+	`
+
+	rows, err := m.fetchStrings(ctx, "fetchTableTriggers", query, name)
+	if err != nil {
+		return []string{}, []TableData{}
+	}
+
+	var tableData []TableData
+	for _, row := range rows {
+		tableData = append(tableData, MysqlTrigger{Name: row[0], Timing: row[1], Event: row[2], Statement: row[3]})
+	}
+	return headers, tableData
+}
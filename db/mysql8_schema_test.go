@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchTableColumns(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedCount int
+		expectedFirst MysqlColumn
+	}{
+		{
+			name: "columns with NULL default",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "COLUMN_KEY", "EXTRA", "COLUMN_COMMENT"}).
+					AddRow("id", "int", "NO", nil, "PRI", "auto_increment", "").
+					AddRow("email", "varchar(100)", "YES", "''", "UNI", "", "contact")
+				mock.ExpectQuery("SELECT COLUMN_NAME, COLUMN_TYPE").WithArgs("users").WillReturnRows(rows)
+			},
+			expectedCount: 2,
+			expectedFirst: MysqlColumn{Name: "id", Type: "int", Nullable: "NO", Default: "NULL", Key: "PRI", Extra: "auto_increment", Comment: ""},
+		},
+		{
+			name: "query error returns empty result",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COLUMN_NAME, COLUMN_TYPE").WithArgs("users").WillReturnError(sql.ErrConnDone)
+			},
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			tt.mockSetup(mock)
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+			_, data := mysql.FetchTableColumns(context.Background(), "users")
+
+			assert.Len(t, data, tt.expectedCount)
+			if tt.expectedCount > 0 {
+				assert.Equal(t, tt.expectedFirst, data[0])
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchTableIndexes(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	rows := sqlmock.NewRows([]string{"INDEX_NAME", "COLUMNS", "UNIQUE", "CARDINALITY", "INDEX_TYPE"}).
+		AddRow("PRIMARY", "id", "YES", 100, "BTREE").
+		AddRow("idx_name_email", "name, email", "NO", 90, "BTREE")
+	mock.ExpectQuery("FROM information_schema.STATISTICS").WithArgs("users").WillReturnRows(rows)
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+	headers, data := mysql.FetchTableIndexes(context.Background(), "users")
+
+	assert.Equal(t, []string{"NAME", "COLUMNS", "UNIQUE", "CARDINALITY", "TYPE"}, headers)
+	assert.Len(t, data, 2)
+	assert.Equal(t, MysqlIndex{Name: "idx_name_email", Columns: "name, email", Unique: "NO", Cardinality: "90", Type: "BTREE"}, data[1])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchTableConstraintsAndTriggers(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("FROM information_schema.KEY_COLUMN_USAGE").WithArgs("orders").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d", "e", "f"}).
+			AddRow("fk_user", "user_id", "users", "id", "CASCADE", "RESTRICT"))
+	mock.ExpectQuery("FROM information_schema.TABLE_CONSTRAINTS").WithArgs("orders").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c"}).
+			AddRow("orders_chk_1", "(`total` >= 0)", "YES"))
+	mock.ExpectQuery("FROM information_schema.TRIGGERS").WithArgs("orders").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d"}))
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+	ctx := context.Background()
+
+	_, foreignKeys := mysql.FetchTableForeignKeys(ctx, "orders")
+	assert.Equal(t, []TableData{MysqlForeignKey{Name: "fk_user", Columns: "user_id", RefTable: "users", RefColumns: "id", OnUpdate: "CASCADE", OnDelete: "RESTRICT"}}, foreignKeys)
+
+	_, checks := mysql.FetchTableChecks(ctx, "orders")
+	assert.Equal(t, []TableData{MysqlCheck{Name: "orders_chk_1", Clause: "(`total` >= 0)", Enforced: "YES"}}, checks)
+
+	headers, triggers := mysql.FetchTableTriggers(ctx, "orders")
+	assert.Equal(t, []string{"NAME", "TIMING", "EVENT", "STATEMENT"}, headers)
+	assert.Empty(t, triggers)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// ErrNoStatistics is returned for databases without storage statistics, such as SQLite
+var ErrNoStatistics = errors.New("no storage statistics on this database")
+
+// TableSize is the storage of a table in bytes, Rows is the estimate of the server statistics
+type TableSize struct {
+	Name       string
+	Rows       int64
+	DataBytes  int64
+	IndexBytes int64
+	// FreeBytes is allocated but unused, reclaimed by rebuilding the table
+	FreeBytes int64
+}
+
+// UnusedIndex is an index not read since the server statistics were reset
+type UnusedIndex struct {
+	Table string
+	Name  string
+}
+
+// tableSizeQueries read the sizes of the base tables. MySQL reports the free space of the tablespace,
+// Postgres estimates it from the share of dead rows
+var tableSizeQueries = map[string]string{
+	DialectMysql: `
+		SELECT TABLE_NAME, IFNULL(TABLE_ROWS, 0), IFNULL(DATA_LENGTH, 0), IFNULL(INDEX_LENGTH, 0), IFNULL(DATA_FREE, 0)
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME
+	`,
+	DialectPostgres: `
+		SELECT c.relname, GREATEST(c.reltuples, 0)::bigint, pg_table_size(c.oid), pg_indexes_size(c.oid),
+			CASE WHEN s.n_live_tup + s.n_dead_tup > 0
+				THEN (pg_table_size(c.oid) * s.n_dead_tup / (s.n_live_tup + s.n_dead_tup))::bigint
+				ELSE 0 END
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+		WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p')
+		ORDER BY c.relname
+	`,
+}
+
+// unusedIndexQueries read the indexes without reads, from sys.schema_unused_indexes on MySQL and
+// pg_stat_user_indexes on Postgres. Unique indexes are left out as they enforce a constraint
+var unusedIndexQueries = map[string]string{
+	DialectMysql: `
+		SELECT object_name, index_name
+		FROM sys.schema_unused_indexes
+		WHERE object_schema = DATABASE()
+		ORDER BY object_name, index_name
+	`,
+	DialectPostgres: `
+		SELECT s.relname, s.indexrelname
+		FROM pg_stat_user_indexes s
+		JOIN pg_index i ON i.indexrelid = s.indexrelid
+		WHERE s.schemaname = current_schema() AND s.idx_scan = 0 AND NOT i.indisunique
+		ORDER BY s.relname, s.indexrelname
+	`,
+}
+
+// FetchTableSizes queries the sizes and estimated rows of the tables of the current database or schema
+func (m *Mysql8) FetchTableSizes(ctx context.Context) ([]TableSize, error) {
+	query, ok := tableSizeQueries[m.Dialect()]
+	if !ok {
+		return nil, ErrNoStatistics
+	}
+	rows, err := m.fetchStrings(ctx, "fetchTableSizes", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var sizes []TableSize
+	for _, row := range rows {
+		size := TableSize{Name: row[0]}
+		for i, field := range []*int64{&size.Rows, &size.DataBytes, &size.IndexBytes, &size.FreeBytes} {
+			// sizes may be reported as decimals
+			value, _ := strconv.ParseFloat(row[i+1], 64)
+			*field = int64(value)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// FetchUnusedIndexes queries the indexes of the current database or schema that were never read
+func (m *Mysql8) FetchUnusedIndexes(ctx context.Context) ([]UnusedIndex, error) {
+	query, ok := unusedIndexQueries[m.Dialect()]
+	if !ok {
+		return nil, ErrNoStatistics
+	}
+	rows, err := m.fetchStrings(ctx, "fetchUnusedIndexes", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []UnusedIndex
+	for _, row := range rows {
+		indexes = append(indexes, UnusedIndex{Table: row[0], Name: row[1]})
+	}
+	return indexes, nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchTableSizes(t *testing.T) {
+	tests := []struct {
+		name        string
+		driver      string
+		sizesQuery  string
+		unusedQuery string
+	}{
+		{name: "mysql", driver: "mysql", sizesQuery: "FROM information_schema.TABLES", unusedQuery: "FROM sys.schema_unused_indexes"},
+		{name: "postgres", driver: "pgx", sizesQuery: "pg_indexes_size", unusedQuery: "FROM pg_stat_user_indexes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			mock.ExpectQuery(tt.sizesQuery).WillReturnRows(sqlmock.NewRows([]string{"name", "rows", "data", "index", "free"}).
+				AddRow("orders", "120000", "50331648", "16777216.0000", "0"))
+			mock.ExpectQuery(tt.unusedQuery).WillReturnRows(sqlmock.NewRows([]string{"table", "index"}).
+				AddRow("orders", "idx_user_id"))
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			sizes, err := mysql.FetchTableSizes(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, []TableSize{{Name: "orders", Rows: 120000, DataBytes: 50331648, IndexBytes: 16777216}}, sizes)
+
+			unused, err := mysql.FetchUnusedIndexes(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, []UnusedIndex{{Table: "orders", Name: "idx_user_id"}}, unused)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchTableSizesSqlite(t *testing.T) {
+	sqlite := &Mysql8{Mysql{DriverName: "sqlite3"}}
+	_, err := sqlite.FetchTableSizes(context.Background())
+	assert.ErrorIs(t, err, ErrNoStatistics)
+	_, err = sqlite.FetchUnusedIndexes(context.Background())
+	assert.ErrorIs(t, err, ErrNoStatistics)
+}
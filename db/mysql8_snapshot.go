@@ -0,0 +1,274 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaTable is the structure of a base table compared between connections
+type SchemaTable struct {
+	Name        string
+	Columns     []SchemaColumn
+	Indexes     []SchemaIndex
+	ForeignKeys []SchemaForeignKey
+}
+
+// SchemaColumn is a column of a table. Default is SQL, NULL without a default, and Extra the attributes following
+// it such as AUTO_INCREMENT
+type SchemaColumn struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+	Extra    string
+}
+
+// SchemaIndex is an index of a table, Primary marks the primary key whatever its name
+type SchemaIndex struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Primary bool
+}
+
+// SchemaForeignKey is a foreign key of a table, SQLite foreign keys have no name
+type SchemaForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnUpdate   string
+	OnDelete   string
+}
+
+// postgresActions names the referential actions of pg_constraint
+var postgresActions = map[string]string{"a": "NO ACTION", "r": "RESTRICT", "c": "CASCADE", "n": "SET NULL", "d": "SET DEFAULT"}
+
+// schemaQueries read the columns, indexes and foreign keys of every base table, one row per column,
+// index and foreign key. Index and key columns are joined by ", ", only MySQL has column extras
+var schemaQueries = map[string][3]string{
+	DialectMysql: {`
+		SELECT c.TABLE_NAME, c.COLUMN_NAME, c.COLUMN_TYPE, c.IS_NULLABLE, c.COLUMN_DEFAULT, c.EXTRA
+		FROM information_schema.COLUMNS c
+		JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE c.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION
+	`, `
+		SELECT TABLE_NAME, INDEX_NAME,
+			GROUP_CONCAT(IFNULL(COLUMN_NAME, EXPRESSION) ORDER BY SEQ_IN_INDEX SEPARATOR ', '),
+			IF(MIN(NON_UNIQUE) = 0, 'YES', 'NO'),
+			IF(INDEX_NAME = 'PRIMARY', 'YES', 'NO')
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE()
+		GROUP BY TABLE_NAME, INDEX_NAME
+		ORDER BY TABLE_NAME, INDEX_NAME
+	`, `
+		SELECT k.TABLE_NAME, k.CONSTRAINT_NAME,
+			GROUP_CONCAT(k.COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ', '),
+			k.REFERENCED_TABLE_NAME,
+			GROUP_CONCAT(k.REFERENCED_COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ', '),
+			r.UPDATE_RULE, r.DELETE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+			ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		WHERE k.TABLE_SCHEMA = DATABASE()
+		GROUP BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.REFERENCED_TABLE_NAME, r.UPDATE_RULE, r.DELETE_RULE
+		ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME
+	`},
+	DialectPostgres: {`
+		SELECT c.relname, a.attname, format_type(a.atttypid, a.atttypmod),
+			CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END, pg_get_expr(d.adbin, d.adrelid), ''
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY c.relname, a.attnum
+	`, `
+		SELECT t.relname, i.relname,
+			array_to_string(ARRAY(
+				SELECT pg_get_indexdef(x.indexrelid, k, true) FROM generate_series(1, x.indnkeyatts) k ORDER BY k
+			), ', '),
+			CASE WHEN x.indisunique THEN 'YES' ELSE 'NO' END,
+			CASE WHEN x.indisprimary THEN 'YES' ELSE 'NO' END
+		FROM pg_index x
+		JOIN pg_class t ON t.oid = x.indrelid
+		JOIN pg_class i ON i.oid = x.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema()
+		ORDER BY t.relname, i.relname
+	`, `
+		SELECT t.relname, c.conname,
+			(SELECT string_agg(a.attname, ', ' ORDER BY k.n) FROM unnest(c.conkey) WITH ORDINALITY k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum),
+			r.relname,
+			(SELECT string_agg(a.attname, ', ' ORDER BY k.n) FROM unnest(c.confkey) WITH ORDINALITY k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = c.confrelid AND a.attnum = k.attnum),
+			c.confupdtype, c.confdeltype
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_class r ON r.oid = c.confrelid
+		JOIN pg_namespace n ON n.oid = c.connamespace
+		WHERE c.contype = 'f' AND n.nspname = current_schema()
+		ORDER BY t.relname, c.conname
+	`},
+	DialectSqlite: {`
+		SELECT m.name, p.name, p.type, CASE WHEN p."notnull" OR p.pk > 0 THEN 'NO' ELSE 'YES' END, p.dflt_value, ''
+		FROM sqlite_master m JOIN pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+		ORDER BY m.name, p.cid
+	`, `
+		SELECT m.name, 'PRIMARY',
+			(SELECT group_concat(name, ', ') FROM (SELECT name FROM pragma_table_info(m.name) WHERE pk > 0 ORDER BY pk)),
+			'YES', 'YES'
+		FROM sqlite_master m
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%' AND EXISTS (SELECT 1 FROM pragma_table_info(m.name) WHERE pk > 0)
+		UNION ALL
+		SELECT m.name, l.name, (SELECT group_concat(name, ', ') FROM (SELECT name FROM pragma_index_info(l.name) ORDER BY seqno)),
+			CASE WHEN l."unique" THEN 'YES' ELSE 'NO' END, 'NO'
+		FROM sqlite_master m JOIN pragma_index_list(m.name) l
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%' AND l.origin <> 'pk'
+		ORDER BY 1, 2
+	`, `
+		SELECT m.name, f.id, f."from", f."table", f."to", f.on_update, f.on_delete
+		FROM sqlite_master m JOIN pragma_foreign_key_list(m.name) f
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+		ORDER BY m.name, f.id, f.seq
+	`},
+}
+
+// FetchSchema reads the tables of the current database or schema with their columns, indexes and foreign keys,
+// sorted by table name
+func (m *Mysql8) FetchSchema(ctx context.Context) ([]SchemaTable, error) {
+	dialect := m.Dialect()
+	queries := schemaQueries[dialect]
+
+	columns, err := m.fetchStrings(ctx, "fetchSchema", queries[0])
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := m.fetchStrings(ctx, "fetchSchema", queries[1])
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := m.fetchStrings(ctx, "fetchSchema", queries[2])
+	if err != nil {
+		return nil, err
+	}
+
+	tables := map[string]*SchemaTable{}
+	for _, row := range columns {
+		table := schemaTable(tables, row[0])
+		column := SchemaColumn{Name: row[1], Type: row[2], Nullable: row[3] == "YES", Default: row[4]}
+		if dialect == DialectMysql {
+			column.Default, column.Extra = mysqlColumnDefault(column.Type, row[4], row[5]), mysqlColumnExtra(row[5])
+		}
+		table.Columns = append(table.Columns, column)
+	}
+	for _, row := range indexes {
+		// indexes of views and partitions are left out with them
+		if table, ok := tables[row[0]]; ok {
+			table.Indexes = append(table.Indexes, SchemaIndex{
+				Name: row[1], Columns: splitColumns(row[2]), Unique: row[3] == "YES", Primary: row[4] == "YES",
+			})
+		}
+	}
+	for _, row := range foreignKeys {
+		table, ok := tables[row[0]]
+		if !ok {
+			continue
+		}
+		switch dialect {
+		case DialectSqlite:
+			table.ForeignKeys = appendSqliteForeignKey(table.ForeignKeys, row)
+		case DialectPostgres:
+			table.ForeignKeys = append(table.ForeignKeys, SchemaForeignKey{
+				Name: row[1], Columns: splitColumns(row[2]), RefTable: row[3], RefColumns: splitColumns(row[4]),
+				OnUpdate: postgresActions[row[5]], OnDelete: postgresActions[row[6]],
+			})
+		default:
+			table.ForeignKeys = append(table.ForeignKeys, SchemaForeignKey{
+				Name: row[1], Columns: splitColumns(row[2]), RefTable: row[3], RefColumns: splitColumns(row[4]),
+				OnUpdate: row[5], OnDelete: row[6],
+			})
+		}
+	}
+
+	schema := make([]SchemaTable, 0, len(tables))
+	for _, table := range tables {
+		if dialect == DialectSqlite {
+			// foreign keys were grouped by their id, which is no name
+			for i := range table.ForeignKeys {
+				table.ForeignKeys[i].Name = ""
+			}
+		}
+		schema = append(schema, *table)
+	}
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Name < schema[j].Name })
+	return schema, nil
+}
+
+// schemaTable returns the table of a name, adding it when first seen
+func schemaTable(tables map[string]*SchemaTable, name string) *SchemaTable {
+	table, ok := tables[name]
+	if !ok {
+		table = &SchemaTable{Name: name}
+		tables[name] = table
+	}
+	return table
+}
+
+// appendSqliteForeignKey adds a row of pragma_foreign_key_list, one per column, to the foreign key of its id
+func appendSqliteForeignKey(foreignKeys []SchemaForeignKey, row []string) []SchemaForeignKey {
+	id := row[1]
+	if n := len(foreignKeys); n > 0 && foreignKeys[n-1].Name == id {
+		foreignKeys[n-1].Columns = append(foreignKeys[n-1].Columns, row[2])
+		foreignKeys[n-1].RefColumns = append(foreignKeys[n-1].RefColumns, row[4])
+		return foreignKeys
+	}
+	return append(foreignKeys, SchemaForeignKey{
+		Name: id, Columns: []string{row[2]}, RefTable: row[3], RefColumns: []string{row[4]}, OnUpdate: row[5], OnDelete: row[6],
+	})
+}
+
+// mysqlNumericTypes start the column types whose defaults MySQL reads as numbers
+var mysqlNumericTypes = regexp.MustCompile(`(?i)^(tinyint|smallint|mediumint|int|integer|bigint|decimal|numeric|float|double|real|bit|year)\b`)
+
+// mysqlTimestampDefault matches the current time defaults, which are written unquoted
+var mysqlTimestampDefault = regexp.MustCompile(`(?i)^(current_timestamp|now|localtime|localtimestamp)(\(\d*\))?$`)
+
+// mysqlColumnExtras match the attributes of EXTRA kept in a column definition
+var mysqlColumnExtras = regexp.MustCompile(`(?i)auto_increment|on update \S+`)
+
+// mysqlColumnDefault turns COLUMN_DEFAULT, which holds the bare value, into SQL. Literals are quoted unless the
+// column is numeric, expressions flagged DEFAULT_GENERATED are put in parentheses unless they are the current time
+func mysqlColumnDefault(columnType string, value string, extra string) string {
+	switch {
+	case value == "NULL" || mysqlTimestampDefault.MatchString(value):
+		return value
+	case strings.Contains(extra, "DEFAULT_GENERATED"):
+		return "(" + value + ")"
+	case mysqlNumericTypes.MatchString(columnType):
+		return value
+	}
+	return quoteString(value)
+}
+
+// mysqlColumnExtra keeps AUTO_INCREMENT and ON UPDATE of EXTRA, which a column definition has to repeat
+func mysqlColumnExtra(extra string) string {
+	attributes := mysqlColumnExtras.FindAllString(extra, -1)
+	for i, attribute := range attributes {
+		attributes[i] = strings.ToUpper(attribute)
+	}
+	return strings.Join(attributes, " ")
+}
+
+// splitColumns splits a column list joined by ", "
+func splitColumns(columns string) []string {
+	if columns == "" || columns == "NULL" {
+		return nil
+	}
+	return strings.Split(columns, ", ")
+}
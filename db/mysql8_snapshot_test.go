@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		driver      string
+		columns     *sqlmock.Rows
+		indexes     *sqlmock.Rows
+		foreignKeys *sqlmock.Rows
+		expected    []SchemaTable
+	}{
+		{
+			name:   "mysql",
+			driver: "mysql",
+			columns: sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA"}).
+				AddRow("users", "id", "int", "NO", nil, "auto_increment").
+				AddRow("orders", "id", "int", "NO", nil, "").
+				AddRow("orders", "user_id", "int", "YES", nil, "").
+				AddRow("orders", "status", "varchar(20)", "NO", "it's new", "").
+				AddRow("orders", "quantity", "int", "NO", "1", "").
+				AddRow("orders", "code", "char(36)", "NO", "uuid()", "DEFAULT_GENERATED").
+				AddRow("orders", "changed_at", "timestamp", "NO", "CURRENT_TIMESTAMP", "DEFAULT_GENERATED on update CURRENT_TIMESTAMP"),
+			indexes: sqlmock.NewRows([]string{"TABLE_NAME", "INDEX_NAME", "COLUMNS", "UNIQUE", "PRIMARY"}).
+				AddRow("orders", "PRIMARY", "id", "YES", "YES").
+				AddRow("orders", "idx_user", "user_id, id", "NO", "NO").
+				AddRow("report", "idx_day", "day", "NO", "NO"),
+			foreignKeys: sqlmock.NewRows([]string{"TABLE_NAME", "CONSTRAINT_NAME", "COLUMNS", "REFERENCED_TABLE_NAME", "REF_COLUMNS", "UPDATE_RULE", "DELETE_RULE"}).
+				AddRow("orders", "fk_user", "user_id", "users", "id", "RESTRICT", "CASCADE"),
+			expected: []SchemaTable{
+				{
+					Name: "orders",
+					Columns: []SchemaColumn{
+						{Name: "id", Type: "int", Default: "NULL"},
+						{Name: "user_id", Type: "int", Nullable: true, Default: "NULL"},
+						{Name: "status", Type: "varchar(20)", Default: "'it''s new'"},
+						{Name: "quantity", Type: "int", Default: "1"},
+						{Name: "code", Type: "char(36)", Default: "(uuid())"},
+						{Name: "changed_at", Type: "timestamp", Default: "CURRENT_TIMESTAMP", Extra: "ON UPDATE CURRENT_TIMESTAMP"},
+					},
+					Indexes: []SchemaIndex{
+						{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+						{Name: "idx_user", Columns: []string{"user_id", "id"}},
+					},
+					ForeignKeys: []SchemaForeignKey{
+						{Name: "fk_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnUpdate: "RESTRICT", OnDelete: "CASCADE"},
+					},
+				},
+				{Name: "users", Columns: []SchemaColumn{{Name: "id", Type: "int", Default: "NULL", Extra: "AUTO_INCREMENT"}}},
+			},
+		},
+		{
+			name:   "postgres actions",
+			driver: "pgx",
+			columns: sqlmock.NewRows([]string{"relname", "attname", "format_type", "nullable", "default", "extra"}).
+				AddRow("orders", "user_id", "integer", "YES", nil, ""),
+			indexes: sqlmock.NewRows([]string{"relname", "relname", "columns", "unique", "primary"}),
+			foreignKeys: sqlmock.NewRows([]string{"relname", "conname", "columns", "relname", "columns", "confupdtype", "confdeltype"}).
+				AddRow("orders", "orders_user_id_fkey", "user_id", "users", "id", "a", "n"),
+			expected: []SchemaTable{{
+				Name:    "orders",
+				Columns: []SchemaColumn{{Name: "user_id", Type: "integer", Nullable: true, Default: "NULL"}},
+				ForeignKeys: []SchemaForeignKey{
+					{Name: "orders_user_id_fkey", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "SET NULL"},
+				},
+			}},
+		},
+		{
+			name:   "sqlite foreign keys of several columns",
+			driver: "sqlite3",
+			columns: sqlmock.NewRows([]string{"name", "name", "type", "nullable", "dflt_value", "extra"}).
+				AddRow("lines", "order_id", "INTEGER", "NO", nil, "").
+				AddRow("lines", "shop_id", "INTEGER", "NO", nil, ""),
+			indexes: sqlmock.NewRows([]string{"name", "name", "columns", "unique", "primary"}),
+			foreignKeys: sqlmock.NewRows([]string{"name", "id", "from", "table", "to", "on_update", "on_delete"}).
+				AddRow("lines", "0", "order_id", "orders", "id", "NO ACTION", "CASCADE").
+				AddRow("lines", "0", "shop_id", "orders", "shop_id", "NO ACTION", "CASCADE").
+				AddRow("lines", "1", "shop_id", "shops", "id", "NO ACTION", "NO ACTION"),
+			expected: []SchemaTable{{
+				Name: "lines",
+				Columns: []SchemaColumn{
+					{Name: "order_id", Type: "INTEGER", Default: "NULL"},
+					{Name: "shop_id", Type: "INTEGER", Default: "NULL"},
+				},
+				ForeignKeys: []SchemaForeignKey{
+					{Columns: []string{"order_id", "shop_id"}, RefTable: "orders", RefColumns: []string{"id", "shop_id"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+					{Columns: []string{"shop_id"}, RefTable: "shops", RefColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "NO ACTION"},
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			mock.ExpectQuery("SELECT").WillReturnRows(tt.columns)
+			mock.ExpectQuery("SELECT").WillReturnRows(tt.indexes)
+			mock.ExpectQuery("SELECT").WillReturnRows(tt.foreignKeys)
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			schema, err := mysql.FetchSchema(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, schema)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchSchemaError(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnError(sql.ErrConnDone)
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+	schema, err := mysql.FetchSchema(context.Background())
+
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.Nil(t, schema)
+}
@@ -0,0 +1,80 @@
+package db
+
+import "context"
+
+// ServerValue is a status counter or a server variable
+type ServerValue struct {
+	Name  string
+	Value string
+}
+
+// statusQueries read the global status counters, sorted by name. Postgres has no single status list,
+// the statistics of the current database are named after the columns of pg_stat_database and
+// active_sessions counts the client sessions running a statement. SQLite reports the pages of the file
+var statusQueries = map[string]string{
+	DialectMysql: `SHOW GLOBAL STATUS`,
+	DialectPostgres: `
+		SELECT s.name, s.value
+		FROM pg_stat_database d
+		CROSS JOIN LATERAL (VALUES
+			('numbackends', d.numbackends::text), ('xact_commit', d.xact_commit::text),
+			('xact_rollback', d.xact_rollback::text), ('blks_read', d.blks_read::text),
+			('blks_hit', d.blks_hit::text), ('tup_returned', d.tup_returned::text),
+			('tup_fetched', d.tup_fetched::text), ('tup_inserted', d.tup_inserted::text),
+			('tup_updated', d.tup_updated::text), ('tup_deleted', d.tup_deleted::text),
+			('conflicts', d.conflicts::text), ('temp_files', d.temp_files::text),
+			('temp_bytes', d.temp_bytes::text), ('deadlocks', d.deadlocks::text)
+		) AS s(name, value)
+		WHERE d.datname = current_database()
+		UNION ALL
+		SELECT 'active_sessions', count(*)::text
+		FROM pg_stat_activity
+		WHERE backend_type = 'client backend' AND state = 'active'
+		ORDER BY 1
+	`,
+	DialectSqlite: `
+		SELECT 'freelist_count', freelist_count FROM pragma_freelist_count()
+		UNION ALL
+		SELECT 'page_count', page_count FROM pragma_page_count()
+	`,
+}
+
+// variableQueries read the server variables sorted by name, Postgres settings are shown with their unit
+// and SQLite reports the pragmas of the connection
+var variableQueries = map[string]string{
+	DialectMysql:    `SHOW GLOBAL VARIABLES`,
+	DialectPostgres: `SELECT name, current_setting(name) FROM pg_settings ORDER BY name`,
+	DialectSqlite: `
+		SELECT 'auto_vacuum', auto_vacuum FROM pragma_auto_vacuum()
+		UNION ALL SELECT 'cache_size', cache_size FROM pragma_cache_size()
+		UNION ALL SELECT 'encoding', encoding FROM pragma_encoding()
+		UNION ALL SELECT 'foreign_keys', foreign_keys FROM pragma_foreign_keys()
+		UNION ALL SELECT 'journal_mode', journal_mode FROM pragma_journal_mode()
+		UNION ALL SELECT 'page_size', page_size FROM pragma_page_size()
+		UNION ALL SELECT 'synchronous', synchronous FROM pragma_synchronous()
+		UNION ALL SELECT 'user_version', user_version FROM pragma_user_version()
+	`,
+}
+
+// FetchStatus queries the global status counters of the server
+func (m *Mysql8) FetchStatus(ctx context.Context) ([]ServerValue, error) {
+	return m.fetchServerValues(ctx, "fetchStatus", statusQueries[m.Dialect()])
+}
+
+// FetchVariables queries the global variables of the server
+func (m *Mysql8) FetchVariables(ctx context.Context) ([]ServerValue, error) {
+	return m.fetchServerValues(ctx, "fetchVariables", variableQueries[m.Dialect()])
+}
+
+// fetchServerValues reads name and value pairs, the first two columns of each row
+func (m *Mysql8) fetchServerValues(ctx context.Context, caller string, query string) ([]ServerValue, error) {
+	rows, err := m.fetchStrings(ctx, caller, query)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]ServerValue, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, ServerValue{Name: row[0], Value: row[1]})
+	}
+	return values, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchStatusAndVariables(t *testing.T) {
+	tests := []struct {
+		name           string
+		driver         string
+		statusQuery    string
+		variablesQuery string
+	}{
+		{name: "mysql", driver: "mysql", statusQuery: "SHOW GLOBAL STATUS", variablesQuery: "SHOW GLOBAL VARIABLES"},
+		{name: "postgres", driver: "pgx", statusQuery: "FROM pg_stat_database", variablesQuery: "FROM pg_settings"},
+		{name: "sqlite", driver: "sqlite3", statusQuery: "pragma_page_count", variablesQuery: "pragma_journal_mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			mock.ExpectQuery(tt.statusQuery).WillReturnRows(sqlmock.NewRows([]string{"name", "value"}).
+				AddRow("Queries", "120").AddRow("Threads_running", "3"))
+			mock.ExpectQuery(tt.variablesQuery).WillReturnRows(sqlmock.NewRows([]string{"name", "value"}).
+				AddRow("max_connections", "151"))
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			status, err := mysql.FetchStatus(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, []ServerValue{{Name: "Queries", Value: "120"}, {Name: "Threads_running", Value: "3"}}, status)
+
+			variables, err := mysql.FetchVariables(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, []ServerValue{{Name: "max_connections", Value: "151"}}, variables)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchStatusError(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SHOW GLOBAL STATUS").WillReturnError(sql.ErrConnDone)
+	mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "mysql"}}
+	_, err = mysql.FetchStatus(context.Background())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+}
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrNoUsers is returned for databases without accounts, such as SQLite
+var ErrNoUsers = errors.New("no users on this database")
+
+// DatabaseUser is an account of the server, Host is empty on Postgres where roles have no host
+type DatabaseUser struct {
+	Name string
+	Host string
+	// Attributes are flags such as LOCKED or SUPERUSER, separated by spaces
+	Attributes string
+}
+
+// TableAccess is a privilege on a table held by a user or role
+type TableAccess struct {
+	Grantee   string
+	Privilege string
+	// Via tells where the privilege comes from: the table, a column, the database, a global grant,
+	// a role granted to the grantee, ownership or superuser
+	Via string
+}
+
+// userQueries list the accounts, MySQL reads mysql.user and Postgres the roles other than the predefined ones
+var userQueries = map[string]string{
+	DialectMysql: `
+		SELECT User, Host, CONCAT_WS(' ',
+			IF(Super_priv = 'Y', 'SUPER', NULL), IF(account_locked = 'Y', 'LOCKED', NULL),
+			IF(password_expired = 'Y', 'EXPIRED', NULL))
+		FROM mysql.user
+		ORDER BY User, Host
+	`,
+	DialectPostgres: `
+		SELECT rolname, '', concat_ws(' ',
+			CASE WHEN rolsuper THEN 'SUPERUSER' END, CASE WHEN NOT rolcanlogin THEN 'NOLOGIN' END,
+			CASE WHEN rolcreatedb THEN 'CREATEDB' END, CASE WHEN rolcreaterole THEN 'CREATEROLE' END)
+		FROM pg_roles
+		WHERE rolname NOT LIKE 'pg\_%'
+		ORDER BY rolname
+	`,
+}
+
+// postgresGrantsQuery writes the table privileges and role memberships of a role as GRANT statements. The privileges
+// are read from the access lists of pg_class, information_schema only shows those of roles the connected one belongs to
+const postgresGrantsQuery = `
+	SELECT 'GRANT ' || string_agg(a.privilege_type, ', ' ORDER BY a.privilege_type) || ' ON ' ||
+		n.nspname || '.' || c.relname || ' TO ' || g.rolname
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	CROSS JOIN LATERAL aclexplode(c.relacl) a
+	JOIN pg_roles g ON g.oid = a.grantee
+	WHERE g.rolname = $1
+	GROUP BY g.rolname, n.nspname, c.relname
+	UNION ALL
+	SELECT 'GRANT ' || r.rolname || ' TO ' || m.rolname
+	FROM pg_auth_members a
+	JOIN pg_roles r ON r.oid = a.roleid
+	JOIN pg_roles m ON m.oid = a.member
+	WHERE m.rolname = $1
+	ORDER BY 1
+`
+
+// tablePrivileges are the global and database privileges that reach a table
+const tablePrivileges = `('SELECT', 'INSERT', 'UPDATE', 'DELETE', 'CREATE', 'DROP', 'ALTER', 'INDEX', 'REFERENCES', 'TRIGGER')`
+
+// tableAccessQueries find who holds privileges on a table of the current database or schema. MySQL only lists the
+// grants information_schema shows the connected user. Postgres reads the access lists of the table and its columns,
+// a grantee of 0 being PUBLIC, and adds the members of granted roles, the owner and superusers
+var tableAccessQueries = map[string]string{
+	DialectMysql: `
+		SELECT GRANTEE, PRIVILEGE_TYPE, 'table'
+		FROM information_schema.TABLE_PRIVILEGES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		UNION ALL
+		SELECT GRANTEE, PRIVILEGE_TYPE, CONCAT('column ', COLUMN_NAME)
+		FROM information_schema.COLUMN_PRIVILEGES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		UNION ALL
+		SELECT GRANTEE, PRIVILEGE_TYPE, 'database'
+		FROM information_schema.SCHEMA_PRIVILEGES
+		WHERE TABLE_SCHEMA = DATABASE() AND PRIVILEGE_TYPE IN ` + tablePrivileges + `
+		UNION ALL
+		SELECT GRANTEE, PRIVILEGE_TYPE, 'global'
+		FROM information_schema.USER_PRIVILEGES
+		WHERE PRIVILEGE_TYPE IN ` + tablePrivileges + `
+		ORDER BY 1, 3, 2
+	`,
+	DialectPostgres: `
+		WITH acl AS (
+			SELECT a.grantee, a.privilege_type
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			CROSS JOIN LATERAL aclexplode(c.relacl) a
+			WHERE n.nspname = current_schema() AND c.relname = $1
+		)
+		SELECT coalesce(g.rolname, 'PUBLIC'), acl.privilege_type, 'table'
+		FROM acl
+		LEFT JOIN pg_roles g ON g.oid = acl.grantee
+		UNION ALL
+		SELECT coalesce(g.rolname, 'PUBLIC'), a.privilege_type, 'column ' || att.attname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute att ON att.attrelid = c.oid AND att.attnum > 0 AND NOT att.attisdropped
+		CROSS JOIN LATERAL aclexplode(att.attacl) a
+		LEFT JOIN pg_roles g ON g.oid = a.grantee
+		WHERE n.nspname = current_schema() AND c.relname = $1
+		UNION ALL
+		SELECT m.rolname, acl.privilege_type, 'role ' || r.rolname
+		FROM acl
+		JOIN pg_roles r ON r.oid = acl.grantee
+		JOIN pg_auth_members a ON a.roleid = r.oid
+		JOIN pg_roles m ON m.oid = a.member
+		UNION ALL
+		SELECT pg_get_userbyid(c.relowner), 'ALL', 'owner'
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relname = $1
+		UNION ALL
+		SELECT rolname, 'ALL', 'superuser'
+		FROM pg_roles
+		WHERE rolsuper
+		ORDER BY 1, 3, 2
+	`,
+}
+
+// FetchUsers queries the accounts of the server
+func (m *Mysql8) FetchUsers(ctx context.Context) ([]DatabaseUser, error) {
+	query, ok := userQueries[m.Dialect()]
+	if !ok {
+		return nil, ErrNoUsers
+	}
+	rows, err := m.fetchStrings(ctx, "fetchUsers", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []DatabaseUser
+	for _, row := range rows {
+		users = append(users, DatabaseUser{Name: row[0], Host: row[1], Attributes: row[2]})
+	}
+	return users, nil
+}
+
+// FetchGrants queries the privileges of an account as GRANT statements, with SHOW GRANTS on MySQL
+func (m *Mysql8) FetchGrants(ctx context.Context, user DatabaseUser) ([]string, error) {
+	var rows [][]string
+	var err error
+	switch m.Dialect() {
+	case DialectSqlite:
+		return nil, ErrNoUsers
+	case DialectPostgres:
+		rows, err = m.fetchStrings(ctx, "fetchGrants", postgresGrantsQuery, user.Name)
+	default:
+		// SHOW GRANTS takes no placeholders
+		rows, err = m.fetchStrings(ctx, "fetchGrants", "SHOW GRANTS FOR "+quoteString(user.Name)+"@"+quoteString(user.Host))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make([]string, 0, len(rows))
+	for _, row := range rows {
+		grants = append(grants, row[0])
+	}
+	return grants, nil
+}
+
+// FetchTableAccess queries the privileges users and roles hold on a table, by grantee
+func (m *Mysql8) FetchTableAccess(ctx context.Context, table string) ([]TableAccess, error) {
+	dialect := m.Dialect()
+	query, ok := tableAccessQueries[dialect]
+	if !ok {
+		return nil, ErrNoUsers
+	}
+	args := []any{table}
+	if dialect == DialectMysql {
+		// the table is compared twice
+		args = append(args, table)
+	}
+	rows, err := m.fetchStrings(ctx, "fetchTableAccess", query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var access []TableAccess
+	for _, row := range rows {
+		access = append(access, TableAccess{Grantee: row[0], Privilege: row[1], Via: row[2]})
+	}
+	return access, nil
+}
+
+// quoteString writes a MySQL string literal
+func quoteString(value string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", "''") + "'"
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchUsers(t *testing.T) {
+	tests := []struct {
+		name          string
+		driver        string
+		expectedQuery string
+	}{
+		{name: "mysql", driver: "mysql", expectedQuery: "FROM mysql.user"},
+		{name: "postgres", driver: "pgx", expectedQuery: "FROM pg_roles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			mock.ExpectQuery(tt.expectedQuery).WillReturnRows(sqlmock.NewRows([]string{"name", "host", "attributes"}).
+				AddRow("app", "%", "").AddRow("root", "localhost", "SUPER"))
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			users, err := mysql.FetchUsers(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, []DatabaseUser{{Name: "app", Host: "%"}, {Name: "root", Host: "localhost", Attributes: "SUPER"}}, users)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFetchGrants(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	// account names are quoted as literals
+	mock.ExpectQuery(`SHOW GRANTS FOR 'o''brien'@'%'`).WillReturnRows(sqlmock.NewRows([]string{"grants"}).
+		AddRow("GRANT USAGE ON *.* TO `o'brien`@`%`"))
+	mock.ExpectQuery(`CROSS JOIN LATERAL aclexplode\(c.relacl\)`).WithArgs("app").
+		WillReturnRows(sqlmock.NewRows([]string{"grant"}).AddRow("GRANT SELECT ON public.orders TO app"))
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "mysql"}}
+	grants, err := mysql.FetchGrants(context.Background(), DatabaseUser{Name: "o'brien", Host: "%"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GRANT USAGE ON *.* TO `o'brien`@`%`"}, grants)
+
+	postgres := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "pgx"}}
+	grants, err = postgres.FetchGrants(context.Background(), DatabaseUser{Name: "app"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GRANT SELECT ON public.orders TO app"}, grants)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	sqlite := &Mysql8{Mysql{DbInstance: mockDB, DriverName: "sqlite3"}}
+	_, err = sqlite.FetchGrants(context.Background(), DatabaseUser{Name: "app"})
+	assert.ErrorIs(t, err, ErrNoUsers)
+}
+
+func TestFetchTableAccess(t *testing.T) {
+	tests := []struct {
+		name          string
+		driver        string
+		expectedQuery string
+		args          []driver.Value
+	}{
+		{name: "mysql", driver: "mysql", expectedQuery: "FROM information_schema.TABLE_PRIVILEGES", args: []driver.Value{"orders", "orders"}},
+		{name: "postgres", driver: "pgx", expectedQuery: `aclexplode\(att.attacl\)`, args: []driver.Value{"orders"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			mock.ExpectQuery(tt.expectedQuery).WithArgs(tt.args...).WillReturnRows(sqlmock.NewRows([]string{"grantee", "privilege", "via"}).
+				AddRow("'report'@'%'", "SELECT", "table"))
+
+			mysql := &Mysql8{Mysql{DbInstance: mockDB, DriverName: tt.driver}}
+			access, err := mysql.FetchTableAccess(context.Background(), "orders")
+
+			assert.NoError(t, err)
+			assert.Equal(t, []TableAccess{{Grantee: "'report'@'%'", Privilege: "SELECT", Via: "table"}}, access)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
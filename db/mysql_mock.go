@@ -4,12 +4,18 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 type MysqlMock struct {
 	Mysql
 }
 
+// ConnectionName names the mock connection
+func (m *MysqlMock) ConnectionName() string {
+	return "mock"
+}
+
 func (m *MysqlMock) FetchTableDescr(ctx context.Context, name string) string {
 	slog.Debug("fetchTableDescr: Getting mock table description", "tableName", name)
 
@@ -98,7 +104,7 @@ func (m *MysqlMock) FetchTables(ctx context.Context) ([]string, []TableData) {
 }
 
 // FetchSqlRows executes a mock SQL query and returns mock results
-func (m *MysqlMock) FetchSqlRows(ctx context.Context, sqlQuery string) ([]string, []TableData) {
+func (m *MysqlMock) FetchSqlRows(ctx context.Context, sqlQuery string, args ...any) ([]string, []TableData) {
 	slog.Debug("FetchSqlRows: Executing mock SQL query", "query", sqlQuery)
 
 	// For mock, return some generic columns and data based on the query
@@ -118,3 +124,367 @@ func (m *MysqlMock) FetchSqlRows(ctx context.Context, sqlQuery string) ([]string
 	slog.Debug("FetchSqlRows: Mock processing complete", "query", sqlQuery, "rowsReturned", len(tableData))
 	return headers, tableData
 }
+
+// FetchPlan returns a mock plan joining a full scan of users to an index lookup of orders
+func (m *MysqlMock) FetchPlan(ctx context.Context, statement string, analyze bool, args ...any) (PlanNode, error) {
+	return PlanNode{
+		Operation: "query block #1",
+		Cost:      "12.50",
+		Children: []PlanNode{{
+			Operation: "nested loop",
+			Children: []PlanNode{
+				{Operation: "table", Table: "users", Access: "ALL", Rows: "50", Cost: "5.25", FullScan: true},
+				{Operation: "table", Table: "orders", Access: "ref", Index: "idx_user_id", Rows: "3", Cost: "12.50"},
+			},
+		}},
+	}, nil
+}
+
+// FetchSchema returns a mock schema of users and their orders
+func (m *MysqlMock) FetchSchema(ctx context.Context) ([]SchemaTable, error) {
+	return []SchemaTable{
+		{
+			Name: "orders",
+			Columns: []SchemaColumn{
+				{Name: "id", Type: "int", Default: "NULL"},
+				{Name: "user_id", Type: "int", Default: "NULL"},
+				{Name: "total", Type: "decimal(10,2)", Default: "0.00"},
+			},
+			Indexes: []SchemaIndex{
+				{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+				{Name: "idx_user_id", Columns: []string{"user_id"}},
+			},
+			ForeignKeys: []SchemaForeignKey{
+				{Name: "fk_orders_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnUpdate: "CASCADE", OnDelete: "RESTRICT"},
+			},
+		},
+		{
+			Name: "users",
+			Columns: []SchemaColumn{
+				{Name: "id", Type: "int", Default: "NULL"},
+				{Name: "name", Type: "varchar(100)", Default: "NULL"},
+				{Name: "email", Type: "varchar(255)", Nullable: true, Default: "NULL"},
+			},
+			Indexes: []SchemaIndex{
+				{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+				{Name: "idx_users_email", Columns: []string{"email"}, Unique: true},
+			},
+		},
+	}, nil
+}
+
+// FetchProcesses returns mock sessions, a long running report and the idle session of the browser
+func (m *MysqlMock) FetchProcesses(ctx context.Context) ([]string, []TableData) {
+	headers := []string{"ID", "USER", "HOST", "DB", "STATE", "TIME", "QUERY"}
+	return headers, []TableData{
+		MysqlProcess{ID: "42", User: "report", Host: "10.0.0.7:51234", Db: "shop", State: "executing", Time: "312", Query: "SELECT customer_id, SUM(total) FROM orders GROUP BY customer_id"},
+		MysqlProcess{ID: "7", User: "app", Host: "10.0.0.5:40112", Db: "shop", State: "Sleep", Time: "3", Query: "NULL"},
+	}
+}
+
+// KillSession pretends to end the mock sessions
+func (m *MysqlMock) KillSession(ctx context.Context, id string) error {
+	if id != "42" && id != "7" {
+		return fmt.Errorf("unknown session %s", id)
+	}
+	return nil
+}
+
+// FetchLockWaits returns a mock chain, the report waits for the app which waits for an idle transaction
+func (m *MysqlMock) FetchLockWaits(ctx context.Context) ([]LockWait, error) {
+	return []LockWait{
+		{
+			WaitingID: "42", WaitingUser: "report", WaitingQuery: "SELECT * FROM orders WHERE id = 1 FOR SHARE",
+			BlockingID: "7", BlockingUser: "app", BlockingQuery: "UPDATE orders SET total = 0 WHERE id = 1",
+			Object: "shop.orders PRIMARY", Mode: "RECORD S,REC_NOT_GAP", Seconds: "12",
+		},
+		{
+			WaitingID: "7", WaitingUser: "app", WaitingQuery: "UPDATE orders SET total = 0 WHERE id = 1",
+			BlockingID: "9", BlockingUser: "batch", BlockingQuery: "NULL",
+			Object: "shop.orders PRIMARY", Mode: "RECORD X,REC_NOT_GAP", Seconds: "30",
+		},
+	}, nil
+}
+
+// FetchStatus returns mock counters growing with the clock, 12 queries and 400 buffer pool reads a second,
+// one of which misses the buffer pool
+func (m *MysqlMock) FetchStatus(ctx context.Context) ([]ServerValue, error) {
+	now := time.Now().Unix()
+	return []ServerValue{
+		{Name: "Innodb_buffer_pool_read_requests", Value: fmt.Sprint(now * 400)},
+		{Name: "Innodb_buffer_pool_reads", Value: fmt.Sprint(now)},
+		{Name: "Queries", Value: fmt.Sprint(now * 12)},
+		{Name: "Threads_connected", Value: "12"},
+		{Name: "Threads_running", Value: "3"},
+		{Name: "Uptime", Value: "86400"},
+	}, nil
+}
+
+// FetchVariables returns mock server variables
+func (m *MysqlMock) FetchVariables(ctx context.Context) ([]ServerValue, error) {
+	return []ServerValue{
+		{Name: "innodb_buffer_pool_size", Value: "134217728"},
+		{Name: "max_connections", Value: "151"},
+		{Name: "sql_mode", Value: "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES"},
+		{Name: "version", Value: "8.0.36"},
+	}, nil
+}
+
+// FetchTableSizes returns mock sizes, the orders table has grown and holds free space
+func (m *MysqlMock) FetchTableSizes(ctx context.Context) ([]TableSize, error) {
+	return []TableSize{
+		{Name: "orders", Rows: 120000, DataBytes: 48 << 20, IndexBytes: 16 << 20, FreeBytes: 12 << 20},
+		{Name: "products", Rows: 800, DataBytes: 256 << 10, IndexBytes: 64 << 10},
+		{Name: "users", Rows: 5000, DataBytes: 2 << 20, IndexBytes: 1 << 20, FreeBytes: 4 << 10},
+	}, nil
+}
+
+// FetchUnusedIndexes returns the mock index on the user of orders as never read
+func (m *MysqlMock) FetchUnusedIndexes(ctx context.Context) ([]UnusedIndex, error) {
+	return []UnusedIndex{{Table: "orders", Name: "idx_user_id"}}, nil
+}
+
+// FetchStatementDigests returns mock statements, the report taking the most time
+func (m *MysqlMock) FetchStatementDigests(ctx context.Context, limit int) ([]StatementDigest, error) {
+	digests := []StatementDigest{
+		{
+			Digest: "3f1c", Text: "SELECT `customer_id` , SUM ( `total` ) FROM `orders` GROUP BY `customer_id`", Db: "shop",
+			Calls: 24, TotalTime: 7488 * time.Millisecond, AvgTime: 312 * time.Millisecond, RowsExamined: 2880000, RowsSent: 19200,
+			Sample: "SELECT customer_id, SUM(total) FROM orders GROUP BY customer_id",
+		},
+		{
+			Digest: "9a2e", Text: "SELECT * FROM `orders` WHERE `user_id` = ?", Db: "shop",
+			Calls: 5200, TotalTime: 2600 * time.Millisecond, AvgTime: 500 * time.Microsecond, RowsExamined: 52000, RowsSent: 52000,
+		},
+	}
+	if limit < len(digests) {
+		digests = digests[:limit]
+	}
+	return digests, nil
+}
+
+// FetchUsers returns mock accounts, the application, a reporting account and root
+func (m *MysqlMock) FetchUsers(ctx context.Context) ([]DatabaseUser, error) {
+	return []DatabaseUser{
+		{Name: "app", Host: "%"},
+		{Name: "report", Host: "10.0.0.%", Attributes: "LOCKED"},
+		{Name: "root", Host: "localhost", Attributes: "SUPER"},
+	}, nil
+}
+
+// FetchGrants returns mock grants, the application writes to the shop database and the report reads orders
+func (m *MysqlMock) FetchGrants(ctx context.Context, user DatabaseUser) ([]string, error) {
+	grants := map[string][]string{
+		"app":    {"GRANT USAGE ON *.* TO `app`@`%`", "GRANT SELECT, INSERT, UPDATE, DELETE ON `shop`.* TO `app`@`%`"},
+		"report": {"GRANT USAGE ON *.* TO `report`@`10.0.0.%`", "GRANT SELECT ON `shop`.`orders` TO `report`@`10.0.0.%`"},
+		"root":   {"GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost` WITH GRANT OPTION"},
+	}
+	if _, ok := grants[user.Name]; !ok {
+		return nil, fmt.Errorf("there is no such grant defined for user '%s' on host '%s'", user.Name, user.Host)
+	}
+	return grants[user.Name], nil
+}
+
+// FetchTableAccess returns the mock grants reaching a table, the report only reads orders
+func (m *MysqlMock) FetchTableAccess(ctx context.Context, table string) ([]TableAccess, error) {
+	var access []TableAccess
+	for _, privilege := range []string{"DELETE", "INSERT", "SELECT", "UPDATE"} {
+		access = append(access, TableAccess{Grantee: "'app'@'%'", Privilege: privilege, Via: "database"})
+	}
+	if table == "orders" {
+		access = append(access, TableAccess{Grantee: "'report'@'10.0.0.%'", Privilege: "SELECT", Via: "table"})
+	}
+	for _, privilege := range []string{"ALTER", "DELETE", "DROP", "INSERT", "SELECT", "UPDATE"} {
+		access = append(access, TableAccess{Grantee: "'root'@'localhost'", Privilege: privilege, Via: "global"})
+	}
+	return access, nil
+}
+
+// FetchReplication returns a mock replica channel that is up to date with its source
+func (m *MysqlMock) FetchReplication(ctx context.Context) ([]ReplicationLink, error) {
+	return []ReplicationLink{
+		{Role: ReplicationSource, Host: "db-primary:3306", State: "IO Yes, SQL Yes", Running: true},
+	}, nil
+}
+
+// ExecuteSql returns mock rows for queries and one affected row for other statements
+func (m *MysqlMock) ExecuteSql(ctx context.Context, statement string, args ...any) StatementResult {
+	if ReturnsRows(statement) {
+		headers, data := m.FetchSqlRows(ctx, statement, args...)
+		return StatementResult{Headers: headers, Data: data, Duration: time.Millisecond}
+	}
+	return StatementResult{RowsAffected: 1, Duration: time.Millisecond}
+}
+
+// ExecuteScript runs the statements one by one like ExecuteSql, stopping after an error
+func (m *MysqlMock) ExecuteScript(ctx context.Context, statements []string, progress func(done int)) []StatementResult {
+	var results []StatementResult
+	for i, statement := range statements {
+		result := m.ExecuteSql(ctx, statement)
+		results = append(results, result)
+		progress(i + 1)
+		if result.Err != nil {
+			break
+		}
+	}
+	return results
+}
+
+func (m *MysqlMock) FetchTableColumns(ctx context.Context, name string) ([]string, []TableData) {
+	slog.Debug("fetchTableColumns: Getting mock columns", "tableName", name)
+	headers := []string{"NAME", "TYPE", "NULLABLE", "DEFAULT", "KEY", "EXTRA", "COMMENT"}
+
+	columns := []MysqlColumn{
+		{Name: "id", Type: "int", Nullable: "NO", Default: "NULL", Key: "PRI", Extra: "auto_increment", Comment: ""},
+		{Name: "name", Type: "varchar(100)", Nullable: "NO", Default: "NULL", Key: "MUL", Extra: "", Comment: "display name"},
+		{Name: "value", Type: "varchar(255)", Nullable: "YES", Default: "NULL", Key: "", Extra: "", Comment: ""},
+		{Name: "created_at", Type: "timestamp", Nullable: "YES", Default: "CURRENT_TIMESTAMP", Key: "", Extra: "DEFAULT_GENERATED", Comment: ""},
+	}
+
+	var tableData []TableData
+	for _, item := range columns {
+		tableData = append(tableData, item)
+	}
+	return headers, tableData
+}
+
+func (m *MysqlMock) FetchTableIndexes(ctx context.Context, name string) ([]string, []TableData) {
+	slog.Debug("fetchTableIndexes: Getting mock indexes", "tableName", name)
+	headers := []string{"NAME", "COLUMNS", "UNIQUE", "CARDINALITY", "TYPE"}
+
+	return headers, []TableData{
+		MysqlIndex{Name: "PRIMARY", Columns: "id", Unique: "YES", Cardinality: "50", Type: "BTREE"},
+		MysqlIndex{Name: fmt.Sprintf("idx_%s_name", name), Columns: "name", Unique: "NO", Cardinality: "48", Type: "BTREE"},
+	}
+}
+
+func (m *MysqlMock) FetchTableForeignKeys(ctx context.Context, name string) ([]string, []TableData) {
+	slog.Debug("fetchTableForeignKeys: Getting mock foreign keys", "tableName", name)
+	headers := []string{"NAME", "COLUMNS", "REFERENCES", "REF COLUMNS", "ON UPDATE", "ON DELETE"}
+
+	if name != "orders" {
+		return headers, []TableData{}
+	}
+	return headers, []TableData{
+		MysqlForeignKey{Name: "fk_orders_user", Columns: "user_id", RefTable: "users", RefColumns: "id", OnUpdate: "CASCADE", OnDelete: "RESTRICT"},
+	}
+}
+
+func (m *MysqlMock) FetchTableChecks(ctx context.Context, name string) ([]string, []TableData) {
+	slog.Debug("fetchTableChecks: Getting mock check constraints", "tableName", name)
+	headers := []string{"NAME", "CLAUSE", "ENFORCED"}
+
+	return headers, []TableData{
+		MysqlCheck{Name: fmt.Sprintf("%s_chk_1", name), Clause: "(`id` > 0)", Enforced: "YES"},
+	}
+}
+
+func (m *MysqlMock) FetchTableTriggers(ctx context.Context, name string) ([]string, []TableData) {
+	slog.Debug("fetchTableTriggers: Getting mock triggers", "tableName", name)
+	headers := []string{"NAME", "TIMING", "EVENT", "STATEMENT"}
+
+	return headers, []TableData{
+		MysqlTrigger{Name: fmt.Sprintf("%s_before_insert", name), Timing: "BEFORE", Event: "INSERT", Statement: "SET NEW.created_at = NOW()"},
+	}
+}
+
+func (m *MysqlMock) FetchViews(ctx context.Context) ([]string, []TableData) {
+	slog.Debug("fetchViews: Getting mock views")
+	headers := []string{"NAME", "UPDATABLE", "CHECK OPTION", "SECURITY", "DEFINER"}
+
+	return headers, []TableData{
+		MysqlView{Name: "active_users", Updatable: "YES", CheckOption: "NONE", Security: "DEFINER", Definer: "admin@%"},
+		MysqlView{Name: "order_totals", Updatable: "NO", CheckOption: "NONE", Security: "INVOKER", Definer: "admin@%"},
+	}
+}
+
+func (m *MysqlMock) FetchRoutines(ctx context.Context, kind string) ([]string, []TableData) {
+	slog.Debug("fetchRoutines: Getting mock routines", "kind", kind)
+	headers := []string{"NAME", "TYPE", "RETURNS", "DETERMINISTIC", "SECURITY", "DEFINER", "MODIFIED"}
+
+	if kind == ObjectFunction {
+		return headers, []TableData{
+			MysqlRoutine{Name: "order_tax", Type: ObjectFunction, Returns: "decimal(10,2)", Deterministic: "YES", Security: "DEFINER", Definer: "admin@%", Modified: "2024-01-01 12:00:00"},
+		}
+	}
+	return headers, []TableData{
+		MysqlRoutine{Name: "archive_orders", Type: ObjectProcedure, Returns: "", Deterministic: "NO", Security: "DEFINER", Definer: "admin@%", Modified: "2024-01-01 12:00:00"},
+		MysqlRoutine{Name: "refresh_metrics", Type: ObjectProcedure, Returns: "", Deterministic: "NO", Security: "INVOKER", Definer: "admin@%", Modified: "2024-01-01 12:00:00"},
+	}
+}
+
+func (m *MysqlMock) FetchTriggers(ctx context.Context) ([]string, []TableData) {
+	slog.Debug("fetchTriggers: Getting mock triggers")
+	headers := []string{"NAME", "TABLE", "TIMING", "EVENT", "CREATED"}
+
+	return headers, []TableData{
+		MysqlTriggerSummary{Name: "orders_before_insert", Table: "orders", Timing: "BEFORE", Event: "INSERT", Created: "2024-01-01 12:00:00"},
+		MysqlTriggerSummary{Name: "users_after_update", Table: "users", Timing: "AFTER", Event: "UPDATE", Created: "2024-01-01 12:00:00"},
+	}
+}
+
+func (m *MysqlMock) FetchEvents(ctx context.Context) ([]string, []TableData) {
+	slog.Debug("fetchEvents: Getting mock events")
+	headers := []string{"NAME", "TYPE", "SCHEDULE", "STATUS", "STARTS", "ENDS"}
+
+	return headers, []TableData{
+		MysqlEvent{Name: "purge_sessions", Type: "RECURRING", Schedule: "EVERY 1 HOUR", Status: "ENABLED", Starts: "2024-01-01 00:00:00", Ends: "NULL"},
+	}
+}
+
+func (m *MysqlMock) FetchSequences(ctx context.Context) ([]string, []TableData) {
+	slog.Debug("fetchSequences: Getting mock sequences")
+	return []string{"NAME"}, []TableData{MysqlSequence{Name: "invoice_seq"}}
+}
+
+func (m *MysqlMock) FetchObjectDefinition(ctx context.Context, kind string, name string) string {
+	slog.Debug("fetchObjectDefinition: Getting mock definition", "kind", kind, "name", name)
+
+	switch kind {
+	case ObjectView:
+		return fmt.Sprintf("CREATE ALGORITHM=UNDEFINED DEFINER=`admin`@`%%` SQL SECURITY DEFINER VIEW `%s` AS select `users`.`id` AS `id`,`users`.`username` AS `username` from `users` where (`users`.`id` > 0)", name)
+	case ObjectProcedure:
+		return fmt.Sprintf("CREATE DEFINER=`admin`@`%%` PROCEDURE `%s`()\nBEGIN\n  DELETE FROM orders WHERE order_date < NOW() - INTERVAL 1 YEAR;\nEND", name)
+	case ObjectFunction:
+		return fmt.Sprintf("CREATE DEFINER=`admin`@`%%` FUNCTION `%s`(total DECIMAL(10,2)) RETURNS decimal(10,2)\n    DETERMINISTIC\nRETURN ROUND(total * 0.2, 2)", name)
+	case ObjectTrigger:
+		return fmt.Sprintf("CREATE DEFINER=`admin`@`%%` TRIGGER `%s` BEFORE INSERT ON `orders` FOR EACH ROW SET NEW.order_date = NOW()", name)
+	case ObjectEvent:
+		return fmt.Sprintf("CREATE DEFINER=`admin`@`%%` EVENT `%s` ON SCHEDULE EVERY 1 HOUR DO DELETE FROM sessions WHERE expires_at < NOW()", name)
+	case ObjectSequence:
+		return fmt.Sprintf("CREATE SEQUENCE `%s` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB", name)
+	}
+	return ""
+}
+
+// StreamSqlRows streams mock results, more rows than FetchSqlRows to stand for a full result
+func (m *MysqlMock) StreamSqlRows(ctx context.Context, sqlQuery string, onColumns func(columns []string, types []string) error, onRow func(values []*string) error, args ...any) error {
+	slog.Debug("StreamSqlRows: Streaming mock SQL query", "query", sqlQuery)
+
+	if err := onColumns([]string{"id", "result", "query_executed"}, []string{"BIGINT", "VARCHAR", "TEXT"}); err != nil {
+		return err
+	}
+	for i := 1; i <= 2000; i++ {
+		id := fmt.Sprintf("%d", i)
+		result := fmt.Sprintf("Mock result row %d", i)
+		query := sqlQuery
+		if err := onRow([]*string{&id, &result, &query}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportRows pretends to load every row in a single batch
+func (m *MysqlMock) ImportRows(ctx context.Context, request ImportRequest, progress func(done int)) (int, error) {
+	imported := 0
+	if err := request.Rows(func(row []*string) error {
+		imported++
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	slog.Debug("importRows: Mock import", "table", request.Table, "rows", imported)
+	progress(imported)
+	return imported, nil
+}
@@ -0,0 +1,365 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Objects of a schema compared by DiffSchemas
+const (
+	SchemaObjectTable      = "table"
+	SchemaObjectColumn     = "column"
+	SchemaObjectIndex      = "index"
+	SchemaObjectForeignKey = "foreign key"
+)
+
+// SchemaChange is a difference between the schema of this connection and another. Here and There
+// describe the object on either side and are empty where it is missing
+type SchemaChange struct {
+	Table  string
+	Object string
+	Name   string
+	Here   string
+	There  string
+	// Statements make the other schema match this one, in its dialect. Dropping tables and columns
+	// and changes the dialect can't make in place are comments
+	Statements []string
+}
+
+// DiffSchemas compares the tables, columns, indexes and foreign keys of two schemas. Columns and indexes are
+// matched by name, the primary key whatever its name and foreign keys by their columns and referenced table,
+// as their names are often generated. dialect is that of the other schema
+func DiffSchemas(here []SchemaTable, there []SchemaTable, dialect string) []SchemaChange {
+	var changes []SchemaChange
+	thereTables := map[string]SchemaTable{}
+	for _, table := range there {
+		thereTables[table.Name] = table
+	}
+	hereTables := map[string]bool{}
+	for _, table := range here {
+		hereTables[table.Name] = true
+		other, ok := thereTables[table.Name]
+		if !ok {
+			changes = append(changes, SchemaChange{
+				Table: table.Name, Object: SchemaObjectTable, Name: table.Name,
+				Here:       fmt.Sprintf("%d columns", len(table.Columns)),
+				Statements: createTableStatements(dialect, table),
+			})
+			continue
+		}
+		changes = append(changes, diffColumns(dialect, table, other)...)
+		changes = append(changes, diffIndexes(dialect, table, other)...)
+		changes = append(changes, diffForeignKeys(dialect, table, other)...)
+	}
+	for _, table := range there {
+		if !hereTables[table.Name] {
+			changes = append(changes, SchemaChange{
+				Table: table.Name, Object: SchemaObjectTable, Name: table.Name,
+				There:      fmt.Sprintf("%d columns", len(table.Columns)),
+				Statements: []string{"-- DROP TABLE " + QuoteIdentifier(dialect, table.Name) + ";"},
+			})
+		}
+	}
+	return changes
+}
+
+// AlterScript joins the statements of changes into a script for the editor
+func AlterScript(changes []SchemaChange) string {
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, change.Statements...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffColumns compares the columns of a table, by type, nullability and default
+func diffColumns(dialect string, here SchemaTable, there SchemaTable) []SchemaChange {
+	var changes []SchemaChange
+	table := QuoteIdentifier(dialect, here.Name)
+	thereColumns := map[string]SchemaColumn{}
+	for _, column := range there.Columns {
+		thereColumns[column.Name] = column
+	}
+	hereColumns := map[string]bool{}
+	for _, column := range here.Columns {
+		hereColumns[column.Name] = true
+		change := SchemaChange{Table: here.Name, Object: SchemaObjectColumn, Name: column.Name, Here: columnDefinition(column)}
+		other, ok := thereColumns[column.Name]
+		switch {
+		case !ok:
+			change.Statements = []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, QuoteIdentifier(dialect, column.Name), change.Here)}
+		case !sameColumn(column, other):
+			change.There = columnDefinition(other)
+			change.Statements = []string{alterColumnStatement(dialect, here.Name, column)}
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for _, column := range there.Columns {
+		if !hereColumns[column.Name] {
+			changes = append(changes, SchemaChange{
+				Table: here.Name, Object: SchemaObjectColumn, Name: column.Name, There: columnDefinition(column),
+				Statements: []string{fmt.Sprintf("-- ALTER TABLE %s DROP COLUMN %s;", table, QuoteIdentifier(dialect, column.Name))},
+			})
+		}
+	}
+	return changes
+}
+
+// diffIndexes compares the indexes of a table by columns and uniqueness, a changed index is dropped and created
+func diffIndexes(dialect string, here SchemaTable, there SchemaTable) []SchemaChange {
+	var changes []SchemaChange
+	key := func(index SchemaIndex) string {
+		if index.Primary {
+			return "PRIMARY"
+		}
+		return index.Name
+	}
+	thereIndexes := map[string]SchemaIndex{}
+	for _, index := range there.Indexes {
+		thereIndexes[key(index)] = index
+	}
+	hereIndexes := map[string]bool{}
+	for _, index := range here.Indexes {
+		hereIndexes[key(index)] = true
+		change := SchemaChange{Table: here.Name, Object: SchemaObjectIndex, Name: index.Name, Here: indexDefinition(index)}
+		other, ok := thereIndexes[key(index)]
+		switch {
+		case !ok:
+			change.Statements = []string{createIndexStatement(dialect, here.Name, index)}
+		case indexDefinition(index) != indexDefinition(other):
+			change.There = indexDefinition(other)
+			change.Statements = []string{dropIndexStatement(dialect, here.Name, other), createIndexStatement(dialect, here.Name, index)}
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for _, index := range there.Indexes {
+		if !hereIndexes[key(index)] {
+			changes = append(changes, SchemaChange{
+				Table: here.Name, Object: SchemaObjectIndex, Name: index.Name, There: indexDefinition(index),
+				Statements: []string{dropIndexStatement(dialect, here.Name, index)},
+			})
+		}
+	}
+	return changes
+}
+
+// diffForeignKeys compares the referential actions of the foreign keys of a table,
+// a changed foreign key is dropped and added
+func diffForeignKeys(dialect string, here SchemaTable, there SchemaTable) []SchemaChange {
+	var changes []SchemaChange
+	key := func(foreignKey SchemaForeignKey) string {
+		return strings.Join(foreignKey.Columns, ", ") + " " + foreignKey.RefTable
+	}
+	thereKeys := map[string]SchemaForeignKey{}
+	for _, foreignKey := range there.ForeignKeys {
+		thereKeys[key(foreignKey)] = foreignKey
+	}
+	hereKeys := map[string]bool{}
+	for _, foreignKey := range here.ForeignKeys {
+		hereKeys[key(foreignKey)] = true
+		change := SchemaChange{
+			Table: here.Name, Object: SchemaObjectForeignKey, Name: foreignKey.Name, Here: foreignKeyDefinition(foreignKey),
+		}
+		other, ok := thereKeys[key(foreignKey)]
+		switch {
+		case !ok:
+			change.Statements = []string{addForeignKeyStatement(dialect, here.Name, foreignKey)}
+		case foreignKeyDefinition(foreignKey) != foreignKeyDefinition(other):
+			change.There = foreignKeyDefinition(other)
+			change.Statements = []string{dropForeignKeyStatement(dialect, here.Name, other), addForeignKeyStatement(dialect, here.Name, foreignKey)}
+		default:
+			continue
+		}
+		if change.Name == "" {
+			change.Name = other.Name
+		}
+		changes = append(changes, change)
+	}
+	for _, foreignKey := range there.ForeignKeys {
+		if !hereKeys[key(foreignKey)] {
+			changes = append(changes, SchemaChange{
+				Table: here.Name, Object: SchemaObjectForeignKey, Name: foreignKey.Name, There: foreignKeyDefinition(foreignKey),
+				Statements: []string{dropForeignKeyStatement(dialect, here.Name, foreignKey)},
+			})
+		}
+	}
+	return changes
+}
+
+// sameColumn reports whether two columns have the same type, types in any case, nullability, default and extras
+func sameColumn(a SchemaColumn, b SchemaColumn) bool {
+	return strings.EqualFold(a.Type, b.Type) && a.Nullable == b.Nullable && a.Default == b.Default && strings.EqualFold(a.Extra, b.Extra)
+}
+
+// columnDefinition describes a column as in CREATE TABLE, such as varchar(20) NOT NULL DEFAULT 'x'
+// or int NOT NULL AUTO_INCREMENT
+func columnDefinition(column SchemaColumn) string {
+	definition := column.Type
+	if !column.Nullable {
+		definition += " NOT NULL"
+	}
+	if column.Default != "" && column.Default != "NULL" {
+		definition += " DEFAULT " + column.Default
+	}
+	if column.Extra != "" {
+		definition += " " + column.Extra
+	}
+	return definition
+}
+
+// indexDefinition describes an index such as UNIQUE (email)
+func indexDefinition(index SchemaIndex) string {
+	columns := "(" + strings.Join(index.Columns, ", ") + ")"
+	switch {
+	case index.Primary:
+		return "PRIMARY KEY " + columns
+	case index.Unique:
+		return "UNIQUE " + columns
+	}
+	return columns
+}
+
+// foreignKeyDefinition describes a foreign key such as (user_id) → users (id) ON DELETE CASCADE,
+// the default actions are left out
+func foreignKeyDefinition(foreignKey SchemaForeignKey) string {
+	definition := fmt.Sprintf("(%s) → %s (%s)",
+		strings.Join(foreignKey.Columns, ", "), foreignKey.RefTable, strings.Join(foreignKey.RefColumns, ", "))
+	if action := foreignKey.OnUpdate; action != "" && action != "NO ACTION" && action != "RESTRICT" {
+		definition += " ON UPDATE " + action
+	}
+	if action := foreignKey.OnDelete; action != "" && action != "NO ACTION" && action != "RESTRICT" {
+		definition += " ON DELETE " + action
+	}
+	return definition
+}
+
+// createTableStatements create a table with its columns and primary key, then its indexes.
+// SQLite can only add foreign keys in CREATE TABLE, other dialects add them after
+func createTableStatements(dialect string, table SchemaTable) []string {
+	var definitions []string
+	for _, column := range table.Columns {
+		definitions = append(definitions, QuoteIdentifier(dialect, column.Name)+" "+columnDefinition(column))
+	}
+	var statements []string
+	for _, index := range table.Indexes {
+		if index.Primary {
+			definitions = append(definitions, "PRIMARY KEY ("+quoteColumns(dialect, index.Columns)+")")
+		} else {
+			statements = append(statements, createIndexStatement(dialect, table.Name, index))
+		}
+	}
+	for _, foreignKey := range table.ForeignKeys {
+		if dialect == DialectSqlite {
+			definitions = append(definitions, foreignKeyClause(dialect, foreignKey))
+		} else {
+			statements = append(statements, addForeignKeyStatement(dialect, table.Name, foreignKey))
+		}
+	}
+	create := fmt.Sprintf("CREATE TABLE %s (\n  %s\n);", QuoteIdentifier(dialect, table.Name), strings.Join(definitions, ",\n  "))
+	return append([]string{create}, statements...)
+}
+
+// alterColumnStatement changes the type, nullability and default of a column. SQLite can't alter columns
+func alterColumnStatement(dialect string, table string, column SchemaColumn) string {
+	name := QuoteIdentifier(dialect, column.Name)
+	switch dialect {
+	case DialectPostgres:
+		actions := []string{fmt.Sprintf("ALTER COLUMN %s TYPE %s", name, column.Type)}
+		if column.Nullable {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", name))
+		} else {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", name))
+		}
+		if column.Default == "" || column.Default == "NULL" {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", name))
+		} else {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", name, column.Default))
+		}
+		return fmt.Sprintf("ALTER TABLE %s %s;", QuoteIdentifier(dialect, table), strings.Join(actions, ", "))
+	case DialectSqlite:
+		return fmt.Sprintf("-- SQLite can't alter column %s of %s to %s, the table has to be rebuilt", column.Name, table, columnDefinition(column))
+	default:
+		return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;", QuoteIdentifier(dialect, table), name, columnDefinition(column))
+	}
+}
+
+// createIndexStatement creates an index, or adds the primary key which SQLite can only declare in CREATE TABLE
+func createIndexStatement(dialect string, table string, index SchemaIndex) string {
+	columns := quoteColumns(dialect, index.Columns)
+	switch {
+	case index.Primary && dialect == DialectSqlite:
+		return fmt.Sprintf("-- SQLite can't add the primary key (%s) to %s, the table has to be rebuilt", columns, table)
+	case index.Primary:
+		return fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s);", QuoteIdentifier(dialect, table), columns)
+	case index.Unique:
+		return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s);", QuoteIdentifier(dialect, index.Name), QuoteIdentifier(dialect, table), columns)
+	}
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s);", QuoteIdentifier(dialect, index.Name), QuoteIdentifier(dialect, table), columns)
+}
+
+// dropIndexStatement drops an index or the primary key, named by the other schema
+func dropIndexStatement(dialect string, table string, index SchemaIndex) string {
+	switch {
+	case index.Primary && dialect == DialectSqlite:
+		return fmt.Sprintf("-- SQLite can't drop the primary key of %s, the table has to be rebuilt", table)
+	case index.Primary && dialect == DialectPostgres:
+		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", QuoteIdentifier(dialect, table), QuoteIdentifier(dialect, index.Name))
+	case index.Primary:
+		return fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY;", QuoteIdentifier(dialect, table))
+	case dialect == DialectMysql:
+		return fmt.Sprintf("DROP INDEX %s ON %s;", QuoteIdentifier(dialect, index.Name), QuoteIdentifier(dialect, table))
+	}
+	return fmt.Sprintf("DROP INDEX %s;", QuoteIdentifier(dialect, index.Name))
+}
+
+// addForeignKeyStatement adds a foreign key, which SQLite can only declare in CREATE TABLE
+func addForeignKeyStatement(dialect string, table string, foreignKey SchemaForeignKey) string {
+	if dialect == DialectSqlite {
+		return fmt.Sprintf("-- SQLite can't add the foreign key %s to %s, the table has to be rebuilt", foreignKeyDefinition(foreignKey), table)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD %s;", QuoteIdentifier(dialect, table), foreignKeyClause(dialect, foreignKey))
+}
+
+// dropForeignKeyStatement drops a foreign key named by the other schema
+func dropForeignKeyStatement(dialect string, table string, foreignKey SchemaForeignKey) string {
+	switch dialect {
+	case DialectSqlite:
+		return fmt.Sprintf("-- SQLite can't drop the foreign key %s of %s, the table has to be rebuilt", foreignKeyDefinition(foreignKey), table)
+	case DialectPostgres:
+		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", QuoteIdentifier(dialect, table), QuoteIdentifier(dialect, foreignKey.Name))
+	}
+	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;", QuoteIdentifier(dialect, table), QuoteIdentifier(dialect, foreignKey.Name))
+}
+
+// foreignKeyClause declares a foreign key, named when it has a name
+func foreignKeyClause(dialect string, foreignKey SchemaForeignKey) string {
+	clause := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+		quoteColumns(dialect, foreignKey.Columns), QuoteIdentifier(dialect, foreignKey.RefTable), quoteColumns(dialect, foreignKey.RefColumns))
+	if foreignKey.Name != "" {
+		clause = "CONSTRAINT " + QuoteIdentifier(dialect, foreignKey.Name) + " " + clause
+	}
+	if foreignKey.OnUpdate != "" {
+		clause += " ON UPDATE " + foreignKey.OnUpdate
+	}
+	if foreignKey.OnDelete != "" {
+		clause += " ON DELETE " + foreignKey.OnDelete
+	}
+	return clause
+}
+
+// quoteColumns quotes and joins column names, expressions of functional indexes are kept as they are
+func quoteColumns(dialect string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		if strings.ContainsAny(column, "() ") {
+			quoted[i] = column
+		} else {
+			quoted[i] = QuoteIdentifier(dialect, column)
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
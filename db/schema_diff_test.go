@@ -0,0 +1,165 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	users := SchemaTable{
+		Name: "users",
+		Columns: []SchemaColumn{
+			{Name: "id", Type: "int", Default: "NULL"},
+			{Name: "email", Type: "varchar(255)", Default: "NULL"},
+			{Name: "active", Type: "tinyint", Default: "1"},
+		},
+		Indexes: []SchemaIndex{
+			{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+			{Name: "idx_email", Columns: []string{"email"}, Unique: true},
+		},
+	}
+	orders := SchemaTable{
+		Name:    "orders",
+		Columns: []SchemaColumn{{Name: "id", Type: "int", Default: "NULL"}, {Name: "user_id", Type: "int", Nullable: true, Default: "NULL"}},
+		Indexes: []SchemaIndex{{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true}},
+		ForeignKeys: []SchemaForeignKey{
+			{Name: "fk_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"},
+		},
+	}
+	// the other schema lacks orders, has an old table, a nullable email without its unique index and an extra column
+	drifted := SchemaTable{
+		Name: "users",
+		Columns: []SchemaColumn{
+			{Name: "id", Type: "INT", Default: "NULL"},
+			{Name: "email", Type: "varchar(255)", Nullable: true, Default: "NULL"},
+			{Name: "active", Type: "tinyint", Default: "1"},
+			{Name: "legacy", Type: "text", Nullable: true, Default: "NULL"},
+		},
+		Indexes: []SchemaIndex{
+			{Name: "users_pkey", Columns: []string{"id"}, Unique: true, Primary: true},
+			{Name: "idx_email", Columns: []string{"email"}},
+		},
+	}
+	old := SchemaTable{Name: "old", Columns: []SchemaColumn{{Name: "id", Type: "int", Default: "NULL"}}}
+
+	tests := []struct {
+		name     string
+		dialect  string
+		expected []SchemaChange
+	}{
+		{
+			name:    "mysql",
+			dialect: DialectMysql,
+			expected: []SchemaChange{
+				{Table: "users", Object: SchemaObjectColumn, Name: "email", Here: "varchar(255) NOT NULL", There: "varchar(255)",
+					Statements: []string{"ALTER TABLE `users` MODIFY COLUMN `email` varchar(255) NOT NULL;"}},
+				{Table: "users", Object: SchemaObjectColumn, Name: "legacy", There: "text",
+					Statements: []string{"-- ALTER TABLE `users` DROP COLUMN `legacy`;"}},
+				{Table: "users", Object: SchemaObjectIndex, Name: "idx_email", Here: "UNIQUE (email)", There: "(email)",
+					Statements: []string{"DROP INDEX `idx_email` ON `users`;", "CREATE UNIQUE INDEX `idx_email` ON `users` (`email`);"}},
+				{Table: "orders", Object: SchemaObjectTable, Name: "orders", Here: "2 columns",
+					Statements: []string{
+						"CREATE TABLE `orders` (\n  `id` int NOT NULL,\n  `user_id` int,\n  PRIMARY KEY (`id`)\n);",
+						"ALTER TABLE `orders` ADD CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;",
+					}},
+				{Table: "old", Object: SchemaObjectTable, Name: "old", There: "1 columns",
+					Statements: []string{"-- DROP TABLE `old`;"}},
+			},
+		},
+		{
+			name:    "postgres",
+			dialect: DialectPostgres,
+			expected: []SchemaChange{
+				{Table: "users", Object: SchemaObjectColumn, Name: "email", Here: "varchar(255) NOT NULL", There: "varchar(255)",
+					Statements: []string{`ALTER TABLE "users" ALTER COLUMN "email" TYPE varchar(255), ALTER COLUMN "email" SET NOT NULL, ALTER COLUMN "email" DROP DEFAULT;`}},
+				{Table: "users", Object: SchemaObjectColumn, Name: "legacy", There: "text",
+					Statements: []string{`-- ALTER TABLE "users" DROP COLUMN "legacy";`}},
+				{Table: "users", Object: SchemaObjectIndex, Name: "idx_email", Here: "UNIQUE (email)", There: "(email)",
+					Statements: []string{`DROP INDEX "idx_email";`, `CREATE UNIQUE INDEX "idx_email" ON "users" ("email");`}},
+				{Table: "orders", Object: SchemaObjectTable, Name: "orders", Here: "2 columns",
+					Statements: []string{
+						"CREATE TABLE \"orders\" (\n  \"id\" int NOT NULL,\n  \"user_id\" int,\n  PRIMARY KEY (\"id\")\n);",
+						`ALTER TABLE "orders" ADD CONSTRAINT "fk_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;`,
+					}},
+				{Table: "old", Object: SchemaObjectTable, Name: "old", There: "1 columns",
+					Statements: []string{`-- DROP TABLE "old";`}},
+			},
+		},
+		{
+			name:    "sqlite",
+			dialect: DialectSqlite,
+			expected: []SchemaChange{
+				{Table: "users", Object: SchemaObjectColumn, Name: "email", Here: "varchar(255) NOT NULL", There: "varchar(255)",
+					Statements: []string{"-- SQLite can't alter column email of users to varchar(255) NOT NULL, the table has to be rebuilt"}},
+				{Table: "users", Object: SchemaObjectColumn, Name: "legacy", There: "text",
+					Statements: []string{`-- ALTER TABLE "users" DROP COLUMN "legacy";`}},
+				{Table: "users", Object: SchemaObjectIndex, Name: "idx_email", Here: "UNIQUE (email)", There: "(email)",
+					Statements: []string{`DROP INDEX "idx_email";`, `CREATE UNIQUE INDEX "idx_email" ON "users" ("email");`}},
+				{Table: "orders", Object: SchemaObjectTable, Name: "orders", Here: "2 columns",
+					Statements: []string{
+						"CREATE TABLE \"orders\" (\n  \"id\" int NOT NULL,\n  \"user_id\" int,\n  PRIMARY KEY (\"id\"),\n" +
+							"  CONSTRAINT \"fk_user\" FOREIGN KEY (\"user_id\") REFERENCES \"users\" (\"id\") ON DELETE CASCADE\n);",
+					}},
+				{Table: "old", Object: SchemaObjectTable, Name: "old", There: "1 columns",
+					Statements: []string{`-- DROP TABLE "old";`}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := DiffSchemas([]SchemaTable{users, orders}, []SchemaTable{drifted, old}, tt.dialect)
+			assert.Equal(t, tt.expected, changes)
+		})
+	}
+}
+
+func TestDiffSchemasForeignKeys(t *testing.T) {
+	here := []SchemaTable{{Name: "orders", ForeignKeys: []SchemaForeignKey{
+		{Name: "fk_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"},
+		{Name: "fk_product", Columns: []string{"product_id"}, RefTable: "products", RefColumns: []string{"id"}},
+	}}}
+	// names differ, foreign keys are matched by their columns
+	there := []SchemaTable{{Name: "orders", ForeignKeys: []SchemaForeignKey{
+		{Name: "orders_user_id_fkey", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "NO ACTION"},
+		{Name: "orders_product_id_fkey", Columns: []string{"product_id"}, RefTable: "products", RefColumns: []string{"id"}, OnDelete: "RESTRICT"},
+		{Name: "orders_shop_id_fkey", Columns: []string{"shop_id"}, RefTable: "shops", RefColumns: []string{"id"}},
+	}}}
+
+	changes := DiffSchemas(here, there, DialectPostgres)
+
+	assert.Equal(t, []SchemaChange{
+		{Table: "orders", Object: SchemaObjectForeignKey, Name: "fk_user", Here: "(user_id) → users (id) ON DELETE CASCADE", There: "(user_id) → users (id)",
+			Statements: []string{
+				`ALTER TABLE "orders" DROP CONSTRAINT "orders_user_id_fkey";`,
+				`ALTER TABLE "orders" ADD CONSTRAINT "fk_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;`,
+			}},
+		{Table: "orders", Object: SchemaObjectForeignKey, Name: "orders_shop_id_fkey", There: "(shop_id) → shops (id)",
+			Statements: []string{`ALTER TABLE "orders" DROP CONSTRAINT "orders_shop_id_fkey";`}},
+	}, changes)
+	assert.Equal(t, "ALTER TABLE \"orders\" DROP CONSTRAINT \"orders_user_id_fkey\";\n"+
+		"ALTER TABLE \"orders\" ADD CONSTRAINT \"fk_user\" FOREIGN KEY (\"user_id\") REFERENCES \"users\" (\"id\") ON DELETE CASCADE;\n"+
+		"ALTER TABLE \"orders\" DROP CONSTRAINT \"orders_shop_id_fkey\";", AlterScript(changes))
+}
+
+func TestDiffSchemasColumnAttributes(t *testing.T) {
+	here := []SchemaTable{{Name: "orders", Columns: []SchemaColumn{
+		{Name: "id", Type: "bigint", Default: "NULL", Extra: "AUTO_INCREMENT"},
+		{Name: "status", Type: "varchar(20)", Default: "'pending'"},
+		{Name: "changed_at", Type: "timestamp", Default: "CURRENT_TIMESTAMP", Extra: "ON UPDATE CURRENT_TIMESTAMP"},
+	}}}
+	// the other schema has a narrower id, a nullable status and no ON UPDATE
+	there := []SchemaTable{{Name: "orders", Columns: []SchemaColumn{
+		{Name: "id", Type: "int", Default: "NULL", Extra: "AUTO_INCREMENT"},
+		{Name: "status", Type: "varchar(20)", Nullable: true, Default: "'pending'"},
+		{Name: "changed_at", Type: "timestamp", Default: "CURRENT_TIMESTAMP"},
+	}}}
+
+	changes := DiffSchemas(here, there, DialectMysql)
+
+	assert.Equal(t, "ALTER TABLE `orders` MODIFY COLUMN `id` bigint NOT NULL AUTO_INCREMENT;\n"+
+		"ALTER TABLE `orders` MODIFY COLUMN `status` varchar(20) NOT NULL DEFAULT 'pending';\n"+
+		"ALTER TABLE `orders` MODIFY COLUMN `changed_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;",
+		AlterScript(changes))
+}
@@ -0,0 +1,184 @@
+package db
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Statement is a single statement of a script with its byte range in the script
+type Statement struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// StatementResult is the outcome of executing a single statement
+type StatementResult struct {
+	// result set of statements returning rows
+	Headers []string
+	Data    []TableData
+	// rows changed by other statements
+	RowsAffected int64
+	Duration     time.Duration
+	Err          error
+}
+
+// rowKeywords start statements returning a result set
+var rowKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "SHOW": true, "DESCRIBE": true, "DESC": true, "EXPLAIN": true,
+	"VALUES": true, "TABLE": true, "PRAGMA": true, "CALL": true, "CHECKSUM": true, "HELP": true,
+}
+
+// SplitStatements splits a script on statement delimiters outside of strings, quoted identifiers
+// and comments. A DELIMITER line, as used by the mysql client around routine bodies, changes the
+// delimiter. Statements made of whitespace and comments only are skipped
+func SplitStatements(script string) []Statement {
+	var statements []Statement
+	delimiter := ";"
+	start := 0
+	hasCode := false
+
+	emit := func(end int, next int) {
+		if hasCode {
+			text := strings.TrimSpace(script[start:end])
+			offset := start + strings.Index(script[start:end], text)
+			statements = append(statements, Statement{Text: text, Start: offset, End: next})
+		}
+		start = next
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); {
+		// DELIMITER is a client command on its own line
+		if !hasCode && isLineStart(script, i) {
+			if newDelimiter, next, ok := delimiterCommand(script, i); ok {
+				delimiter = newDelimiter
+				start = next
+				i = next
+				continue
+			}
+		}
+
+		// the lexer skips strings and comments, anything else is scanned a rune at a time
+		// since a delimiter such as $$ or // may end a word or an operator
+		kind, end := scanToken(script, i)
+		switch {
+		case strings.HasPrefix(script[i:], delimiter):
+			emit(i, i+len(delimiter))
+			i += len(delimiter)
+		case kind == TokenWhitespace || kind == TokenComment:
+			i = end
+		case kind == TokenString || kind == TokenQuotedIdentifier:
+			i = end
+			hasCode = true
+		default:
+			_, size := utf8.DecodeRuneInString(script[i:])
+			i += size
+			hasCode = true
+		}
+	}
+	emit(len(script), len(script))
+
+	return statements
+}
+
+// StatementAt returns the statement under the byte offset of a cursor. A cursor between statements
+// belongs to the statement before it, a cursor before the first one to the first one
+func StatementAt(statements []Statement, offset int) (Statement, bool) {
+	if len(statements) == 0 {
+		return Statement{}, false
+	}
+	current := statements[0]
+	for _, statement := range statements {
+		if statement.Start > offset {
+			break
+		}
+		current = statement
+	}
+	return current, true
+}
+
+// ReturnsRows reports whether a statement produces a result set rather than an affected row count
+func ReturnsRows(statement string) bool {
+	for i := 0; i < len(statement); {
+		kind, end := scanToken(statement, i)
+		if kind != TokenWhitespace && kind != TokenComment && statement[i:end] != "(" {
+			return rowKeywords[strings.ToUpper(statement[i:end])]
+		}
+		i = end
+	}
+	return false
+}
+
+// isLineStart reports whether only spaces precede position i on its line
+func isLineStart(script string, i int) bool {
+	lineStart := strings.LastIndexByte(script[:i], '\n') + 1
+	return strings.TrimSpace(script[lineStart:i]) == ""
+}
+
+// delimiterCommand parses a DELIMITER line at position i and returns the new delimiter
+// and the position after the line
+func delimiterCommand(script string, i int) (string, int, bool) {
+	const command = "DELIMITER"
+	if len(script)-i <= len(command) || !strings.EqualFold(script[i:i+len(command)], command) || !unicode.IsSpace(rune(script[i+len(command)])) {
+		return "", 0, false
+	}
+	next := skipLine(script, i)
+	fields := strings.Fields(script[i+len(command) : next])
+	if len(fields) == 0 {
+		return "", 0, false
+	}
+	return fields[0], next, true
+}
+
+// skipLine returns the position after the end of the line at i
+func skipLine(script string, i int) int {
+	if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+		return i + end + 1
+	}
+	return len(script)
+}
+
+// skipQuoted returns the position after the quoted string or identifier starting at i.
+// Quotes are escaped by doubling them, or with a backslash inside strings
+func skipQuoted(script string, i int, quote byte) int {
+	for j := i + 1; j < len(script); j++ {
+		switch script[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			if j+1 < len(script) && script[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(script)
+}
+
+// skipDollarQuoted returns the position after a Postgres $tag$ string starting at i,
+// or after the $ when it does not start one, such as a $1 parameter
+func skipDollarQuoted(script string, i int) int {
+	end := strings.IndexByte(script[i+1:], '$')
+	if end < 0 {
+		return i + 1
+	}
+	tag := script[i : i+end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return i + 1
+		}
+	}
+	if len(tag) > 2 && unicode.IsDigit(rune(tag[1])) {
+		return i + 1
+	}
+	if closing := strings.Index(script[i+len(tag):], tag); closing >= 0 {
+		return i + len(tag) + closing + len(tag)
+	}
+	return len(script)
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected []string
+	}{
+		{
+			name:     "single statement without delimiter",
+			script:   "SELECT 1",
+			expected: []string{"SELECT 1"},
+		},
+		{
+			name:     "several statements",
+			script:   "SELECT 1;\nSELECT 2 ;\n\nUPDATE t SET a = 1;",
+			expected: []string{"SELECT 1", "SELECT 2", "UPDATE t SET a = 1"},
+		},
+		{
+			name:     "delimiters inside strings and identifiers",
+			script:   "SELECT 'a;b', \"c;d\", `e;f`; SELECT 'it''s;', 'back\\';slash'",
+			expected: []string{"SELECT 'a;b', \"c;d\", `e;f`", "SELECT 'it''s;', 'back\\';slash'"},
+		},
+		{
+			name:     "comments",
+			script:   "-- first; not a statement\nSELECT 1; # trailing; comment\n/* block; comment */ SELECT 2;\n-- only a comment;",
+			expected: []string{"-- first; not a statement\nSELECT 1", "# trailing; comment\n/* block; comment */ SELECT 2"},
+		},
+		{
+			name:     "double dash without space is an operator",
+			script:   "SELECT 1--1; SELECT 2",
+			expected: []string{"SELECT 1--1", "SELECT 2"},
+		},
+		{
+			name:   "delimiter command",
+			script: "DELIMITER $$\nCREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND$$\nDELIMITER ;\nCALL p();",
+			expected: []string{
+				"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND",
+				"CALL p()",
+			},
+		},
+		{
+			name:     "postgres dollar quoting",
+			script:   "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql; SELECT $1;",
+			expected: []string{"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql", "SELECT $1"},
+		},
+		{
+			name:     "empty script",
+			script:   " \n;; ",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var texts []string
+			for _, statement := range SplitStatements(tt.script) {
+				texts = append(texts, statement.Text)
+				assert.Equal(t, statement.Text, tt.script[statement.Start:statement.Start+len(statement.Text)])
+			}
+			assert.Equal(t, tt.expected, texts)
+		})
+	}
+}
+
+func TestStatementAt(t *testing.T) {
+	script := "SELECT 1;\n\nSELECT 2;\nSELECT 3"
+	statements := SplitStatements(script)
+
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "SELECT 1"},
+		{8, "SELECT 1"},
+		{10, "SELECT 1"},
+		{11, "SELECT 2"},
+		{len(script), "SELECT 3"},
+	}
+	for _, tt := range tests {
+		statement, ok := StatementAt(statements, tt.offset)
+		assert.True(t, ok)
+		assert.Equal(t, tt.expected, statement.Text, "offset %d", tt.offset)
+	}
+
+	_, ok := StatementAt(nil, 0)
+	assert.False(t, ok)
+}
+
+func TestReturnsRows(t *testing.T) {
+	assert.True(t, ReturnsRows("select * from t"))
+	assert.True(t, ReturnsRows("(SELECT 1) UNION (SELECT 2)"))
+	assert.True(t, ReturnsRows("-- latest first\n/* all */ SELECT * FROM t"))
+	assert.True(t, ReturnsRows("WITH x AS (SELECT 1) SELECT * FROM x"))
+	assert.True(t, ReturnsRows("SHOW TABLES"))
+	assert.False(t, ReturnsRows("UPDATE t SET a = 1"))
+	assert.False(t, ReturnsRows("CREATE TABLE t (id int)"))
+	assert.False(t, ReturnsRows(""))
+}
+
+func TestExecuteSql(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(nil))
+	mock.ExpectExec("UPDATE users SET active = 1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM missing").
+		WillReturnError(errors.New("table missing doesn't exist"))
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+	ctx := context.Background()
+
+	result := mysql.ExecuteSql(ctx, "SELECT id FROM users")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []string{"id"}, result.Headers)
+	assert.Equal(t, []TableData{map[string]string{"id": "1"}, map[string]string{"id": "NULL"}}, result.Data)
+
+	result = mysql.ExecuteSql(ctx, "UPDATE users SET active = 1")
+	assert.NoError(t, result.Err)
+	assert.Nil(t, result.Headers)
+	assert.Equal(t, int64(3), result.RowsAffected)
+
+	result = mysql.ExecuteSql(ctx, "DELETE FROM missing")
+	assert.EqualError(t, result.Err, "table missing doesn't exist")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecuteScript(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectExec("USE shop").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id FROM users").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("DELETE FROM missing").WillReturnError(errors.New("table missing doesn't exist"))
+	// the connection the script changed is closed rather than returned to the pool
+	mock.ExpectClose()
+
+	mysql := &Mysql8{Mysql{DbInstance: mockDB}}
+	var done []int
+	results := mysql.ExecuteScript(context.Background(),
+		[]string{"USE shop", "SELECT id FROM users", "DELETE FROM missing", "SELECT 2"},
+		func(n int) { done = append(done, n) })
+
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, []TableData{map[string]string{"id": "1"}}, results[1].Data)
+	assert.EqualError(t, results[2].Err, "table missing doesn't exist")
+	assert.Equal(t, []int{1, 2, 3}, done)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
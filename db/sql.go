@@ -27,6 +27,11 @@ var (
 		"LINES", "STARTING", "IGNORE", "REPLACE", "DUPLICATE", "LOW_PRIORITY",
 		"HIGH_PRIORITY", "DELAYED", "QUICK", "EXTENDED", "FULL", "MEDIUM",
 		"PARTIAL", "FAST", "CHANGED", "USE_FRM", "FOR", "UPGRADE", "UNION",
+		"AS", "CASE", "WHEN", "THEN", "ELSE", "END", "WITH", "CROSS", "NATURAL",
+		"INTERSECT", "EXCEPT", "WINDOW", "OVER", "PARTITION", "RETURNING", "ASC",
+		"USING", "INTERVAL", "TRUE", "FALSE", "DECLARE", "RETURN", "RETURNS",
+		"ELSEIF", "WHILE", "DO", "LOOP", "REPEAT", "UNTIL", "EACH", "ROW",
+		"DEFINER", "ALGORITHM", "SECURITY",
 	}
 
 	sqlDataTypes = []string{
@@ -52,138 +57,54 @@ var (
 	}
 )
 
-// HighlightSQL applies syntax highlighting to SQL text for tview TextView
-// Uses tview color tags: [color]text[-] format
-func HighlightSQL(sql string) string {
-	if sql == "" {
-		return sql
-	}
+// highlightColors are the tview colors of highlighted token kinds
+var highlightColors = map[TokenKind]string{
+	TokenKeyword:          "lightblue",
+	TokenDataType:         "lightgreen",
+	TokenFunction:         "yellow",
+	TokenString:           "red",
+	TokenQuotedIdentifier: "cyan",
+	TokenNumber:           "magenta",
+	TokenComment:          "gray",
+}
 
-	result := sql
-	protectedAreas := make(map[string]string)
-	placeholderIndex := 0
+// tagPattern matches text tview would read as a color or region tag, as in tview.Escape
+var tagPattern = regexp.MustCompile(`(\[[a-zA-Z0-9_,;: \-\."#]+\[*)\]`)
 
-	// Function to create a placeholder for protected content
-	createPlaceholder := func(content string) string {
-		placeholder := "<<<PLACEHOLDER" + strings.Repeat("_", placeholderIndex) + ">>>"
-		protectedAreas[placeholder] = content
-		placeholderIndex++
-		return placeholder
+// HighlightSQL applies syntax highlighting to SQL text for tview TextView
+// Uses tview color tags: [color]text[-] format, brackets in the text are escaped
+func HighlightSQL(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql) * 2)
+	// plain text is escaped in runs, a tag may span several tokens as in a[i]
+	plain := 0
+	flush := func(end int) {
+		b.WriteString(escapeTags(sql[plain:end]))
 	}
-
-	// Function to restore all placeholders
-	restorePlaceholders := func(text string) string {
-		for placeholder, original := range protectedAreas {
-			text = strings.ReplaceAll(text, placeholder, original)
+	for _, token := range Tokenize(sql) {
+		color, ok := highlightColors[token.Kind]
+		if !ok {
+			continue
 		}
-		return text
-	}
-
-	// First, protect comments from other highlighting
-	// Single-line comments starting with --
-	singleCommentRe := regexp.MustCompile(`--.*`)
-	result = singleCommentRe.ReplaceAllStringFunc(result, func(match string) string {
-		highlighted := "[gray]" + match + "[-]"
-		return createPlaceholder(highlighted)
-	})
-
-	// Multi-line comments /* ... */
-	multiCommentRe := regexp.MustCompile(`(?s)/\*.*?\*/`)
-	result = multiCommentRe.ReplaceAllStringFunc(result, func(match string) string {
-		highlighted := "[gray]" + match + "[-]"
-		return createPlaceholder(highlighted)
-	})
-
-	// Protect string literals from keyword highlighting
-	// Single-quoted strings
-	singleQuoteRe := regexp.MustCompile(`'([^'\\]|\\.)*'`)
-	result = singleQuoteRe.ReplaceAllStringFunc(result, func(match string) string {
-		highlighted := "[red]" + match + "[-]"
-		return createPlaceholder(highlighted)
-	})
-
-	// Double-quoted strings
-	doubleQuoteRe := regexp.MustCompile(`"([^"\\]|\\.)*"`)
-	result = doubleQuoteRe.ReplaceAllStringFunc(result, func(match string) string {
-		highlighted := "[red]" + match + "[-]"
-		return createPlaceholder(highlighted)
-	})
-
-	// Protect backtick identifiers
-	backtickRe := regexp.MustCompile("`([^`]+)`")
-	result = backtickRe.ReplaceAllStringFunc(result, func(match string) string {
-		highlighted := "[cyan]" + match + "[-]"
-		return createPlaceholder(highlighted)
-	})
-
-	// Highlight functions in yellow
-	for _, function := range sqlFunctions {
-		// Match function calls (function name followed by opening parenthesis)
-		pattern := `(?i)\b` + regexp.QuoteMeta(function) + `\s*\(`
-		re := regexp.MustCompile(pattern)
-		result = re.ReplaceAllStringFunc(result, func(match string) string {
-			// Extract just the function name part and highlight it
-			funcPattern := `(?i)\b` + regexp.QuoteMeta(function)
-			funcRe := regexp.MustCompile(funcPattern)
-			return funcRe.ReplaceAllString(match, "[yellow]"+function+"[-]")
-		})
-	}
-
-	// Highlight data types in light green
-	for _, dataType := range sqlDataTypes {
-		pattern := `(?i)\b` + regexp.QuoteMeta(dataType) + `\b`
-		re := regexp.MustCompile(pattern)
-		result = re.ReplaceAllStringFunc(result, func(match string) string {
-			return "[lightgreen]" + match + "[-]"
-		})
+		flush(token.Start)
+		b.WriteString("[" + color + "]")
+		b.WriteString(escapeTags(token.Text))
+		b.WriteString("[-]")
+		plain = token.End
 	}
+	flush(len(sql))
+	return b.String()
+}
 
-	// Highlight SQL keywords in light blue
-	for _, keyword := range sqlKeywords {
-		// Use word boundaries to match whole words only, case-insensitive
-		pattern := `(?i)\b` + regexp.QuoteMeta(keyword) + `\b`
-		re := regexp.MustCompile(pattern)
-		result = re.ReplaceAllStringFunc(result, func(match string) string {
-			return "[lightblue]" + match + "[-]"
-		})
+// escapeTags escapes text tview would read as a tag
+func escapeTags(text string) string {
+	if strings.IndexByte(text, '[') < 0 {
+		return text
 	}
-
-	// Highlight numeric literals in magenta
-	numberRe := regexp.MustCompile(`\b\d+(\.\d+)?\b`)
-	result = numberRe.ReplaceAllStringFunc(result, func(match string) string {
-		return "[magenta]" + match + "[-]"
-	})
-
-	// Restore all protected content
-	result = restorePlaceholders(result)
-
-	return result
+	return tagPattern.ReplaceAllString(text, "$1[]")
 }
 
-// FormatSQL formats SQL with proper indentation and highlighting
-// This is a simple formatter that adds basic indentation
+// FormatSQL pretty prints SQL, keeping the case of keywords, and highlights it
 func FormatSQL(sql string) string {
-	if sql == "" {
-		return sql
-	}
-
-	// Clean up the SQL first
-	sql = strings.TrimSpace(sql)
-	
-	// Add basic formatting
-	// This is a simple approach - for production use, consider a proper SQL formatter
-	lines := strings.Split(sql, "\n")
-	var formattedLines []string
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			formattedLines = append(formattedLines, line)
-		}
-	}
-	
-	formatted := strings.Join(formattedLines, "\n")
-	
-	// Apply syntax highlighting
-	return HighlightSQL(formatted)
-}
\ No newline at end of file
+	return HighlightSQL(PrettyPrintSQL(sql, FormatOptions{}))
+}
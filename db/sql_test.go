@@ -1,6 +1,7 @@
 package db
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -150,12 +151,12 @@ func TestFormatSQL(t *testing.T) {
 		{
 			name:     "simple query",
 			input:    "SELECT * FROM users",
-			expected: "[lightblue]SELECT[-] * [lightblue]FROM[-] users",
+			expected: "[lightblue]SELECT[-] *\n[lightblue]FROM[-] users",
 		},
 		{
 			name:     "query with extra whitespace",
 			input:    "   SELECT   *   FROM   users   ",
-			expected: "[lightblue]SELECT[-]   *   [lightblue]FROM[-]   users",
+			expected: "[lightblue]SELECT[-] *\n[lightblue]FROM[-] users",
 		},
 		{
 			name:     "multiline query",
@@ -219,6 +220,20 @@ func TestHighlightSQLEdgeCases(t *testing.T) {
 				assert.Contains(t, result, `[red]"John's \"nickname\""[-]`)
 			},
 		},
+		{
+			name:  "qualified names are not keywords or data types",
+			input: "SELECT orders.INT_col, t.status, t.select FROM t",
+			validate: func(t *testing.T, result string) {
+				assert.Equal(t, "[lightblue]SELECT[-] orders.INT_col, t.status, t.select [lightblue]FROM[-] t", result)
+			},
+		},
+		{
+			name:  "text resembling placeholders or tags is kept",
+			input: "SELECT '<<<PLACEHOLDER>>>', a[i], b[1] FROM t -- [red]",
+			validate: func(t *testing.T, result string) {
+				assert.Equal(t, "[lightblue]SELECT[-] [red]'<<<PLACEHOLDER>>>'[-], a[i[], b[[magenta]1[-]] [lightblue]FROM[-] t [gray]-- [red[][-]", result)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -246,4 +261,36 @@ func TestSQLKeywordsCoverage(t *testing.T) {
 		}
 		assert.True(t, found, "Common SQL keyword '%s' not found in sqlKeywords list", keyword)
 	}
-}
\ No newline at end of file
+}
+
+// largeDDL builds a schema of many tables, similar to a dump of a real database
+func largeDDL(tables int) string {
+	var b strings.Builder
+	for i := 0; i < tables; i++ {
+		b.WriteString("-- table " + strings.Repeat("x", i%10) + "\n")
+		b.WriteString("CREATE TABLE `orders_" + string(rune('a'+i%26)) + "` (\n")
+		b.WriteString("  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n")
+		b.WriteString("  `user_id` INT NOT NULL,\n")
+		b.WriteString("  `status` VARCHAR(32) NOT NULL DEFAULT 'new',\n")
+		b.WriteString("  `total` DECIMAL(10,2) NOT NULL DEFAULT 0.00,\n")
+		b.WriteString("  `note` TEXT COMMENT 'free text; may contain [tags]',\n")
+		b.WriteString("  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,\n")
+		b.WriteString("  PRIMARY KEY (`id`),\n")
+		b.WriteString("  KEY `idx_user` (`user_id`),\n")
+		b.WriteString("  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE\n")
+		b.WriteString(") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n\n")
+	}
+	return b.String()
+}
+
+func BenchmarkHighlightSQL(b *testing.B) {
+	for _, tables := range []int{1, 100} {
+		ddl := largeDDL(tables)
+		b.Run(fmt.Sprintf("tables=%d", tables), func(b *testing.B) {
+			b.SetBytes(int64(len(ddl)))
+			for i := 0; i < b.N; i++ {
+				HighlightSQL(ddl)
+			}
+		})
+	}
+}
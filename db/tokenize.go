@@ -0,0 +1,217 @@
+package db
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind classifies a token of SQL text
+type TokenKind int
+
+const (
+	TokenWhitespace TokenKind = iota
+	TokenComment
+	TokenString
+	TokenQuotedIdentifier
+	TokenNumber
+	TokenKeyword
+	TokenDataType
+	TokenFunction
+	TokenIdentifier
+	TokenOperator
+	TokenPunctuation
+)
+
+// Token is a lexical token with its byte range in the tokenized text
+type Token struct {
+	Kind  TokenKind
+	Text  string
+	Start int
+	End   int
+}
+
+// operatorChars may combine into multi character operators such as <= or ->>
+const operatorChars = "<>=!|&+-*/%^~:"
+
+var (
+	keywordSet  = wordSet(sqlKeywords)
+	dataTypeSet = wordSet(sqlDataTypes)
+	functionSet = wordSet(sqlFunctions)
+)
+
+// wordSet builds an upper case lookup set of words
+func wordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[strings.ToUpper(word)] = true
+	}
+	return set
+}
+
+// Tokenize splits SQL text into tokens covering all of it. Unterminated strings and comments
+// run to the end of the text so partially typed SQL still tokenizes.
+// A word followed by ( is a function when known as one, otherwise keywords take precedence over data types.
+// Words after a dot are names qualified by a table or schema and stay identifiers
+func Tokenize(sql string) []Token {
+	var tokens []Token
+	for i := 0; i < len(sql); {
+		kind, end := scanToken(sql, i)
+		tokens = append(tokens, Token{Kind: kind, Text: sql[i:end], Start: i, End: end})
+		i = end
+	}
+
+	for i := range tokens {
+		if tokens[i].Kind != TokenIdentifier {
+			continue
+		}
+		// qualified names such as users.status or t.INT_col are never keywords
+		if i > 0 && tokens[i-1].Text == "." {
+			continue
+		}
+		word := strings.ToUpper(tokens[i].Text)
+		switch {
+		case functionSet[word] && nextSignificant(tokens, i) == "(":
+			tokens[i].Kind = TokenFunction
+		case keywordSet[word]:
+			tokens[i].Kind = TokenKeyword
+		case dataTypeSet[word]:
+			tokens[i].Kind = TokenDataType
+		}
+	}
+	return tokens
+}
+
+// scanToken returns the kind and end of the token starting at i
+func scanToken(sql string, i int) (TokenKind, int) {
+	c := sql[i]
+	r, size := utf8.DecodeRuneInString(sql[i:])
+
+	switch {
+	case unicode.IsSpace(r):
+		end := i + size
+		for end < len(sql) {
+			next, nextSize := utf8.DecodeRuneInString(sql[end:])
+			if !unicode.IsSpace(next) {
+				break
+			}
+			end += nextSize
+		}
+		return TokenWhitespace, end
+	case startsLineComment(sql, i):
+		end := strings.IndexByte(sql[i:], '\n')
+		if end < 0 {
+			return TokenComment, len(sql)
+		}
+		return TokenComment, i + end
+	case strings.HasPrefix(sql[i:], "/*"):
+		if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+			return TokenComment, i + end + 4
+		}
+		return TokenComment, len(sql)
+	case c == '\'' || c == '"':
+		return TokenString, skipQuoted(sql, i, c)
+	case c == '`':
+		return TokenQuotedIdentifier, skipQuoted(sql, i, c)
+	case c == '$':
+		if end := skipDollarQuoted(sql, i); end > i+1 {
+			return TokenString, end
+		}
+		return TokenIdentifier, scanWord(sql, i+1)
+	case isDigit(c) || c == '.' && i+1 < len(sql) && isDigit(sql[i+1]):
+		return scanNumber(sql, i)
+	case isWordRune(r) || c == '@':
+		return TokenIdentifier, scanWord(sql, i+size)
+	case strings.ContainsRune("(),;.[]{}", r):
+		return TokenPunctuation, i + size
+	default:
+		end := i + size
+		if strings.ContainsRune(operatorChars, r) {
+			for end < len(sql) && strings.IndexByte(operatorChars, sql[end]) >= 0 && !startsComment(sql, end) {
+				end++
+			}
+		}
+		return TokenOperator, end
+	}
+}
+
+// startsComment reports whether a comment starts at i
+func startsComment(sql string, i int) bool {
+	return startsLineComment(sql, i) || strings.HasPrefix(sql[i:], "/*")
+}
+
+// startsLineComment reports whether a -- or # comment starts at i, -- needs a following space as in MySQL
+func startsLineComment(sql string, i int) bool {
+	return sql[i] == '#' || strings.HasPrefix(sql[i:], "--") && (i+2 == len(sql) || unicode.IsSpace(rune(sql[i+2])))
+}
+
+// scanNumber scans integers, decimals, exponents and hex literals. Digits running into
+// letters, such as 1st, form an identifier as in MySQL
+func scanNumber(sql string, i int) (TokenKind, int) {
+	end := i
+	if strings.HasPrefix(sql[i:], "0x") || strings.HasPrefix(sql[i:], "0X") {
+		end += 2
+		for end < len(sql) && strings.IndexByte("0123456789abcdefABCDEF", sql[end]) >= 0 {
+			end++
+		}
+	} else {
+		for end < len(sql) && isDigit(sql[end]) {
+			end++
+		}
+		if end < len(sql) && sql[end] == '.' {
+			end++
+			for end < len(sql) && isDigit(sql[end]) {
+				end++
+			}
+		}
+		if end+1 < len(sql) && (sql[end] == 'e' || sql[end] == 'E') {
+			exponent := end + 1
+			if sql[exponent] == '+' || sql[exponent] == '-' {
+				exponent++
+			}
+			if exponent < len(sql) && isDigit(sql[exponent]) {
+				end = exponent
+				for end < len(sql) && isDigit(sql[end]) {
+					end++
+				}
+			}
+		}
+	}
+
+	if end < len(sql) {
+		if r, _ := utf8.DecodeRuneInString(sql[end:]); isWordRune(r) {
+			return TokenIdentifier, scanWord(sql, end)
+		}
+	}
+	return TokenNumber, end
+}
+
+// scanWord returns the end of an identifier continuing at i, @ and $ are part of variable names
+func scanWord(sql string, i int) int {
+	for i < len(sql) {
+		r, size := utf8.DecodeRuneInString(sql[i:])
+		if !isWordRune(r) && !unicode.IsDigit(r) && r != '$' && r != '@' {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// nextSignificant returns the text of the first token after i that is not whitespace or a comment
+func nextSignificant(tokens []Token, i int) string {
+	for _, token := range tokens[i+1:] {
+		if token.Kind != TokenWhitespace && token.Kind != TokenComment {
+			return token.Text
+		}
+	}
+	return ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
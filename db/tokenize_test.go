@@ -0,0 +1,117 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Token
+	}{
+		{
+			name:  "keywords, functions, data types and literals",
+			input: "SELECT COUNT(*) FROM t WHERE CAST(x AS DECIMAL) > 1.5e3",
+			expected: []Token{
+				{Kind: TokenKeyword, Text: "SELECT"},
+				{Kind: TokenFunction, Text: "COUNT"},
+				{Kind: TokenPunctuation, Text: "("},
+				{Kind: TokenOperator, Text: "*"},
+				{Kind: TokenPunctuation, Text: ")"},
+				{Kind: TokenKeyword, Text: "FROM"},
+				{Kind: TokenIdentifier, Text: "t"},
+				{Kind: TokenKeyword, Text: "WHERE"},
+				{Kind: TokenFunction, Text: "CAST"},
+				{Kind: TokenPunctuation, Text: "("},
+				{Kind: TokenIdentifier, Text: "x"},
+				{Kind: TokenKeyword, Text: "AS"},
+				{Kind: TokenDataType, Text: "DECIMAL"},
+				{Kind: TokenPunctuation, Text: ")"},
+				{Kind: TokenOperator, Text: ">"},
+				{Kind: TokenNumber, Text: "1.5e3"},
+			},
+		},
+		{
+			name:  "strings, identifiers and comments",
+			input: "`my col` = 'it''s' -- done\n/* block */ \"x\\\"y\" # hash",
+			expected: []Token{
+				{Kind: TokenQuotedIdentifier, Text: "`my col`"},
+				{Kind: TokenOperator, Text: "="},
+				{Kind: TokenString, Text: "'it''s'"},
+				{Kind: TokenComment, Text: "-- done"},
+				{Kind: TokenComment, Text: "/* block */"},
+				{Kind: TokenString, Text: "\"x\\\"y\""},
+				{Kind: TokenComment, Text: "# hash"},
+			},
+		},
+		{
+			name:  "operators and variables",
+			input: "@x:=a<=>b--1 AND $1 <> 0x1F",
+			expected: []Token{
+				{Kind: TokenIdentifier, Text: "@x"},
+				{Kind: TokenOperator, Text: ":="},
+				{Kind: TokenIdentifier, Text: "a"},
+				{Kind: TokenOperator, Text: "<=>"},
+				{Kind: TokenIdentifier, Text: "b"},
+				{Kind: TokenOperator, Text: "--"},
+				{Kind: TokenNumber, Text: "1"},
+				{Kind: TokenKeyword, Text: "AND"},
+				{Kind: TokenIdentifier, Text: "$1"},
+				{Kind: TokenOperator, Text: "<>"},
+				{Kind: TokenNumber, Text: "0x1F"},
+			},
+		},
+		{
+			name:  "qualified names",
+			input: "users.INT_col = t.select",
+			expected: []Token{
+				{Kind: TokenIdentifier, Text: "users"},
+				{Kind: TokenPunctuation, Text: "."},
+				{Kind: TokenIdentifier, Text: "INT_col"},
+				{Kind: TokenOperator, Text: "="},
+				{Kind: TokenIdentifier, Text: "t"},
+				{Kind: TokenPunctuation, Text: "."},
+				{Kind: TokenIdentifier, Text: "select"},
+			},
+		},
+		{
+			name:  "unterminated string runs to the end",
+			input: "SELECT 'abc",
+			expected: []Token{
+				{Kind: TokenKeyword, Text: "SELECT"},
+				{Kind: TokenString, Text: "'abc"},
+			},
+		},
+		{
+			name:  "dollar quoted body",
+			input: "AS $$ SELECT 1; $$",
+			expected: []Token{
+				{Kind: TokenKeyword, Text: "AS"},
+				{Kind: TokenString, Text: "$$ SELECT 1; $$"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := Tokenize(tt.input)
+
+			// tokens cover the input without gaps
+			var rebuilt strings.Builder
+			var significant []Token
+			for _, token := range tokens {
+				assert.Equal(t, tt.input[token.Start:token.End], token.Text)
+				rebuilt.WriteString(token.Text)
+				if token.Kind != TokenWhitespace {
+					significant = append(significant, Token{Kind: token.Kind, Text: token.Text})
+				}
+			}
+			assert.Equal(t, tt.input, rebuilt.String())
+			assert.Equal(t, tt.expected, significant)
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"rel8/db"
+)
+
+// Formats lists export formats accepted by NewWriter
+var Formats = []string{"csv", "tsv", "json", "jsonl", "markdown", "html", "sql"}
+
+// Options configure format specific output, only the sql format uses them
+type Options struct {
+	// target table of INSERT statements
+	Table string
+	// target dialect: mysql, postgres or sqlite
+	Dialect string
+	// one of ModeInsert, ModeReplace, ModeIgnore, ModeUpsert
+	Mode string
+	// conflict target columns for upserts on postgres and sqlite
+	Key []string
+	// rows per INSERT statement
+	BatchSize int
+}
+
+// Writer writes a result set row by row, nil values are SQL NULLs
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []*string) error
+	Close() error
+}
+
+// NewWriter creates a writer for the given format
+func NewWriter(format string, w io.Writer, options Options) (Writer, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return &delimitedWriter{w: w, delimiter: ",", lineEnd: "\r\n"}, nil
+	case "tsv":
+		return &delimitedWriter{w: w, delimiter: "\t", lineEnd: "\n"}, nil
+	case "json":
+		return &jsonWriter{w: w, array: true}, nil
+	case "jsonl":
+		return &jsonWriter{w: w}, nil
+	case "markdown", "md":
+		return &markdownWriter{w: w}, nil
+	case "html":
+		return &htmlWriter{w: w}, nil
+	case "sql":
+		return newSQLWriter(w, options)
+	default:
+		return nil, fmt.Errorf("unknown export format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// ToFile creates a file at path and lets fill write into it using the given format
+func ToFile(path string, format string, options Options, fill func(w Writer) error) error {
+	// validate format and options before touching the file
+	if _, err := NewWriter(format, io.Discard, options); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	writer, err := NewWriter(format, buffered, options)
+	if err != nil {
+		return err
+	}
+
+	if err := fill(writer); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// columnTyper is implemented by writers rendering values by the type of their column
+type columnTyper interface {
+	setColumnTypes(types []string)
+}
+
+// WriteTypedHeader writes the header, passing the type names the driver reports for the columns to writers using them
+func WriteTypedHeader(w Writer, columns []string, types []string) error {
+	if typer, ok := w.(columnTyper); ok {
+		typer.setColumnTypes(types)
+	}
+	return w.WriteHeader(columns)
+}
+
+// WriteTableData writes loaded grid data, treating "NULL" cells as SQL NULLs
+func WriteTableData(w Writer, headers []string, data []db.TableData) error {
+	if err := w.WriteHeader(headers); err != nil {
+		return err
+	}
+	for _, item := range data {
+		if err := w.WriteRow(Values(item, headers)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Values extracts cells of a map or struct row in header order, "NULL" cells become nil
+func Values(item db.TableData, headers []string) []*string {
+	var fields []string
+	if mapData, ok := item.(map[string]string); ok {
+		for _, header := range headers {
+			fields = append(fields, mapData[header])
+		}
+	} else {
+		v := reflect.ValueOf(item)
+		if v.Kind() == reflect.Struct {
+			for i := 0; i < v.NumField(); i++ {
+				fields = append(fields, v.Field(i).String())
+			}
+		}
+	}
+
+	values := make([]*string, len(fields))
+	for i := range fields {
+		if fields[i] != "NULL" {
+			values[i] = &fields[i]
+		}
+	}
+	return values
+}
+
+// delimitedWriter writes CSV per RFC 4180 or TSV. NULL is an empty field, empty string is quoted
+type delimitedWriter struct {
+	w         io.Writer
+	delimiter string
+	lineEnd   string
+}
+
+func (d *delimitedWriter) WriteHeader(columns []string) error {
+	values := make([]*string, len(columns))
+	for i := range columns {
+		values[i] = &columns[i]
+	}
+	return d.WriteRow(values)
+}
+
+func (d *delimitedWriter) WriteRow(values []*string) error {
+	fields := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			fields[i] = d.quote(*value)
+		}
+	}
+	_, err := io.WriteString(d.w, strings.Join(fields, d.delimiter)+d.lineEnd)
+	return err
+}
+
+func (d *delimitedWriter) Close() error {
+	return nil
+}
+
+// quote encloses a field in double quotes when needed, doubling inner quotes
+func (d *delimitedWriter) quote(field string) string {
+	if field == "" || strings.ContainsAny(field, d.delimiter+"\"\r\n") {
+		return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+	}
+	return field
+}
+
+// jsonWriter writes rows as objects keeping column order, either as an array or one per line
+type jsonWriter struct {
+	w       io.Writer
+	array   bool
+	columns []string
+	rows    int
+}
+
+func (j *jsonWriter) WriteHeader(columns []string) error {
+	j.columns = columns
+	if j.array {
+		_, err := io.WriteString(j.w, "[")
+		return err
+	}
+	return nil
+}
+
+func (j *jsonWriter) WriteRow(values []*string) error {
+	var b strings.Builder
+	if j.array {
+		if j.rows > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  ")
+	}
+	b.WriteString("{")
+	for i, column := range j.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		key, _ := json.Marshal(column)
+		b.Write(key)
+		b.WriteString(": ")
+		if i < len(values) && values[i] != nil {
+			value, _ := json.Marshal(*values[i])
+			b.Write(value)
+		} else {
+			b.WriteString("null")
+		}
+	}
+	b.WriteString("}")
+	if !j.array {
+		b.WriteString("\n")
+	}
+	j.rows++
+	_, err := io.WriteString(j.w, b.String())
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if !j.array {
+		return nil
+	}
+	closing := "]\n"
+	if j.rows > 0 {
+		closing = "\n]\n"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
+
+// markdownWriter writes a GitHub flavoured markdown table
+type markdownWriter struct {
+	w io.Writer
+}
+
+func (m *markdownWriter) WriteHeader(columns []string) error {
+	cells := make([]string, len(columns))
+	separators := make([]string, len(columns))
+	for i, column := range columns {
+		cells[i] = markdownEscape(column)
+		separators[i] = "---"
+	}
+	_, err := io.WriteString(m.w, "| "+strings.Join(cells, " | ")+" |\n| "+strings.Join(separators, " | ")+" |\n")
+	return err
+}
+
+func (m *markdownWriter) WriteRow(values []*string) error {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		if value == nil {
+			cells[i] = "*NULL*"
+		} else {
+			cells[i] = markdownEscape(*value)
+		}
+	}
+	_, err := io.WriteString(m.w, "| "+strings.Join(cells, " | ")+" |\n")
+	return err
+}
+
+func (m *markdownWriter) Close() error {
+	return nil
+}
+
+// markdownEscape keeps a value inside its table cell
+func markdownEscape(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// htmlWriter writes a plain HTML table
+type htmlWriter struct {
+	w io.Writer
+}
+
+func (h *htmlWriter) WriteHeader(columns []string) error {
+	var b strings.Builder
+	b.WriteString("<table>\n<thead>\n<tr>")
+	for _, column := range columns {
+		b.WriteString("<th>" + html.EscapeString(column) + "</th>")
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *htmlWriter) WriteRow(values []*string) error {
+	var b strings.Builder
+	b.WriteString("<tr>")
+	for _, value := range values {
+		if value == nil {
+			b.WriteString("<td><em>NULL</em></td>")
+		} else {
+			b.WriteString("<td>" + html.EscapeString(*value) + "</td>")
+		}
+	}
+	b.WriteString("</tr>\n")
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *htmlWriter) Close() error {
+	_, err := io.WriteString(h.w, "</tbody>\n</table>\n")
+	return err
+}
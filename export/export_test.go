@@ -0,0 +1,109 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func ptr(value string) *string {
+	return &value
+}
+
+func render(t *testing.T, format string, columns []string, rows ...[]*string) string {
+	var b strings.Builder
+	w, err := NewWriter(format, &b, Options{})
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteHeader(columns))
+	for _, row := range rows {
+		assert.NoError(t, w.WriteRow(row))
+	}
+	assert.NoError(t, w.Close())
+	return b.String()
+}
+
+func TestWriters(t *testing.T) {
+	columns := []string{"id", "note"}
+	rows := [][]*string{
+		{ptr("1"), ptr(`say "hi", bye`)},
+		{ptr("2"), nil},
+		{ptr("3"), ptr("")},
+		{ptr("4"), ptr("a|b\nc")},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   "csv",
+			expected: "id,note\r\n1,\"say \"\"hi\"\", bye\"\r\n2,\r\n3,\"\"\r\n4,\"a|b\nc\"\r\n",
+		},
+		{
+			format:   "tsv",
+			expected: "id\tnote\n1\t\"say \"\"hi\"\", bye\"\n2\t\n3\t\"\"\n4\t\"a|b\nc\"\n",
+		},
+		{
+			format:   "jsonl",
+			expected: "{\"id\": \"1\", \"note\": \"say \\\"hi\\\", bye\"}\n{\"id\": \"2\", \"note\": null}\n{\"id\": \"3\", \"note\": \"\"}\n{\"id\": \"4\", \"note\": \"a|b\\nc\"}\n",
+		},
+		{
+			format:   "markdown",
+			expected: "| id | note |\n| --- | --- |\n| 1 | say \"hi\", bye |\n| 2 | *NULL* |\n| 3 |  |\n| 4 | a\\|b<br>c |\n",
+		},
+		{
+			format:   "html",
+			expected: "<table>\n<thead>\n<tr><th>id</th><th>note</th></tr>\n</thead>\n<tbody>\n<tr><td>1</td><td>say &#34;hi&#34;, bye</td></tr>\n<tr><td>2</td><td><em>NULL</em></td></tr>\n<tr><td>3</td><td></td></tr>\n<tr><td>4</td><td>a|b\nc</td></tr>\n</tbody>\n</table>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			assert.Equal(t, tt.expected, render(t, tt.format, columns, rows...))
+		})
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	assert.Equal(t, "[]\n", render(t, "json", []string{"id"}))
+	assert.Equal(t, "[\n  {\"b\": \"1\", \"a\": null},\n  {\"b\": \"2\", \"a\": \"x\"}\n]\n",
+		render(t, "json", []string{"b", "a"}, []*string{ptr("1"), nil}, []*string{ptr("2"), ptr("x")}))
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	_, err := NewWriter("xlsx", &strings.Builder{}, Options{})
+	assert.Error(t, err)
+}
+
+func TestValues(t *testing.T) {
+	values := Values(map[string]string{"id": "1", "name": "NULL"}, []string{"id", "name", "missing"})
+	assert.Equal(t, []*string{ptr("1"), nil, ptr("")}, values)
+
+	values = Values(db.MysqlDatabase{Name: "app", Charset: "utf8mb4", Collation: "NULL"}, nil)
+	assert.Equal(t, []*string{ptr("app"), ptr("utf8mb4"), nil}, values)
+}
+
+func TestToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+
+	err := ToFile(path, "csv", Options{}, func(w Writer) error {
+		return WriteTableData(w, []string{"id", "name"}, []db.TableData{
+			map[string]string{"id": "1", "name": "NULL"},
+		})
+	})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "id,name\r\n1,\r\n", string(content))
+
+	// an unknown format leaves the existing file alone
+	assert.Error(t, ToFile(path, "yaml", Options{}, func(w Writer) error { return nil }))
+	content, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "id,name\r\n1,\r\n", string(content))
+}
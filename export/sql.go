@@ -0,0 +1,203 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"rel8/db"
+)
+
+// Insert modes of the sql format
+const (
+	ModeInsert  = "insert"
+	ModeReplace = "replace"
+	ModeIgnore  = "ignore"
+	ModeUpsert  = "upsert"
+)
+
+// defaultBatchSize is the number of rows per multi-row INSERT
+const defaultBatchSize = 100
+
+// numericTypes are the type names MySQL, Postgres and SQLite drivers report for numeric columns, unsigned MySQL types
+// are prefixed with UNSIGNED
+var numericTypes = map[string]bool{
+	"TINYINT": true, "SMALLINT": true, "MEDIUMINT": true, "INT": true, "INTEGER": true, "BIGINT": true,
+	"INT2": true, "INT4": true, "INT8": true, "DECIMAL": true, "NUMERIC": true, "FLOAT": true, "FLOAT4": true,
+	"FLOAT8": true, "DOUBLE": true, "REAL": true, "YEAR": true,
+}
+
+// numberPattern matches plain numbers, values such as NaN or those of SQLite's loosely typed columns stay quoted
+var numberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// sqlWriter writes rows as multi-row INSERT statements for the target dialect
+type sqlWriter struct {
+	w       io.Writer
+	options Options
+	columns []string
+	// numeric tells which columns have a numeric type, their values are written unquoted
+	numeric []bool
+	pending [][]*string
+}
+
+func newSQLWriter(w io.Writer, options Options) (*sqlWriter, error) {
+	if options.Table == "" {
+		return nil, fmt.Errorf("sql export needs a table name, use --table=name")
+	}
+	if options.Dialect == "" {
+		options.Dialect = db.DialectMysql
+	}
+	if options.Dialect != db.DialectMysql && options.Dialect != db.DialectPostgres && options.Dialect != db.DialectSqlite {
+		return nil, fmt.Errorf("unknown dialect %q", options.Dialect)
+	}
+	if options.Mode == "" {
+		options.Mode = ModeInsert
+	}
+	if options.Mode != ModeInsert && options.Mode != ModeReplace && options.Mode != ModeIgnore && options.Mode != ModeUpsert {
+		return nil, fmt.Errorf("unknown insert mode %q", options.Mode)
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	return &sqlWriter{w: w, options: options}, nil
+}
+
+func (s *sqlWriter) setColumnTypes(types []string) {
+	s.numeric = make([]bool, len(types))
+	for i, name := range types {
+		s.numeric[i] = numericTypes[strings.TrimPrefix(strings.ToUpper(name), "UNSIGNED ")]
+	}
+}
+
+func (s *sqlWriter) WriteHeader(columns []string) error {
+	s.columns = columns
+	return nil
+}
+
+func (s *sqlWriter) WriteRow(values []*string) error {
+	s.pending = append(s.pending, values)
+	if len(s.pending) >= s.options.BatchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *sqlWriter) Close() error {
+	return s.flush()
+}
+
+// flush writes pending rows as one statement
+func (s *sqlWriter) flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString(s.statementPrefix())
+	b.WriteString(" " + s.quoteIdentifier(s.options.Table) + " (")
+	for i, column := range s.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(s.quoteIdentifier(column))
+	}
+	b.WriteString(") VALUES")
+
+	for i, row := range s.pending {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  (")
+		for j, value := range row {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(s.quoteValue(value, j < len(s.numeric) && s.numeric[j]))
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(s.statementSuffix())
+	b.WriteString(";\n")
+
+	s.pending = s.pending[:0]
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+// statementPrefix returns the INSERT verb for the mode and dialect
+func (s *sqlWriter) statementPrefix() string {
+	switch {
+	case s.options.Mode == ModeReplace && s.options.Dialect == db.DialectMysql:
+		return "REPLACE INTO"
+	case s.options.Mode == ModeReplace && s.options.Dialect == db.DialectSqlite:
+		return "INSERT OR REPLACE INTO"
+	case s.options.Mode == ModeIgnore && s.options.Dialect == db.DialectMysql:
+		return "INSERT IGNORE INTO"
+	case s.options.Mode == ModeIgnore && s.options.Dialect == db.DialectSqlite:
+		return "INSERT OR IGNORE INTO"
+	default:
+		return "INSERT INTO"
+	}
+}
+
+// statementSuffix returns the conflict clause for the mode and dialect.
+// Postgres has no REPLACE, it is written as an upsert updating every column. MySQL upserts refer to the inserted row by
+// the alias new, VALUES() being deprecated since 8.0.20
+func (s *sqlWriter) statementSuffix() string {
+	switch {
+	case s.options.Mode == ModeIgnore && s.options.Dialect == db.DialectPostgres:
+		return "\nON CONFLICT DO NOTHING"
+	case s.options.Mode == ModeUpsert && s.options.Dialect == db.DialectMysql:
+		var updates []string
+		for _, column := range s.columns {
+			quoted := s.quoteIdentifier(column)
+			updates = append(updates, quoted+" = new."+quoted)
+		}
+		return " AS new\nON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	case s.options.Mode == ModeUpsert || (s.options.Mode == ModeReplace && s.options.Dialect == db.DialectPostgres):
+		var keys, updates []string
+		for _, key := range s.conflictKey() {
+			keys = append(keys, s.quoteIdentifier(key))
+		}
+		for _, column := range s.columns {
+			quoted := s.quoteIdentifier(column)
+			updates = append(updates, quoted+" = EXCLUDED."+quoted)
+		}
+		return "\nON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+	default:
+		return ""
+	}
+}
+
+// conflictKey returns the configured key columns, defaulting to the first column
+func (s *sqlWriter) conflictKey() []string {
+	if len(s.options.Key) > 0 {
+		return s.options.Key
+	}
+	if len(s.columns) > 0 {
+		return s.columns[:1]
+	}
+	return nil
+}
+
+// quoteIdentifier quotes a table or column name for the dialect
+func (s *sqlWriter) quoteIdentifier(name string) string {
+	return db.QuoteIdentifier(s.options.Dialect, name)
+}
+
+// quoteValue renders a value as a string literal for the dialect, nil is NULL and numbers of numeric columns are
+// written as they are. MySQL treats backslash as an escape character by default so it is doubled there
+func (s *sqlWriter) quoteValue(value *string, numeric bool) string {
+	if value == nil {
+		return "NULL"
+	}
+	if numeric && numberPattern.MatchString(*value) {
+		return *value
+	}
+	escaped := *value
+	if s.options.Dialect == db.DialectMysql {
+		escaped = strings.ReplaceAll(escaped, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(escaped, "'", "''") + "'"
+}
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func renderSQL(t *testing.T, options Options, rows ...[]*string) string {
+	var b strings.Builder
+	w, err := NewWriter("sql", &b, options)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteHeader([]string{"id", "name"}))
+	for _, row := range rows {
+		assert.NoError(t, w.WriteRow(row))
+	}
+	assert.NoError(t, w.Close())
+	return b.String()
+}
+
+func TestSQLWriter(t *testing.T) {
+	rows := [][]*string{
+		{ptr("1"), ptr(`O'Brien \ co`)},
+		{ptr("2"), nil},
+		{ptr("3"), ptr("x")},
+	}
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{
+			name:    "mysql insert batched",
+			options: Options{Table: "users", BatchSize: 2},
+			expected: "INSERT INTO `users` (`id`, `name`) VALUES\n  ('1', 'O''Brien \\\\ co'),\n  ('2', NULL);\n" +
+				"INSERT INTO `users` (`id`, `name`) VALUES\n  ('3', 'x');\n",
+		},
+		{
+			name:     "sqlite replace",
+			options:  Options{Table: "users", Dialect: db.DialectSqlite, Mode: ModeReplace},
+			expected: "INSERT OR REPLACE INTO \"users\" (\"id\", \"name\") VALUES\n  ('1', 'O''Brien \\ co'),\n  ('2', NULL),\n  ('3', 'x');\n",
+		},
+		{
+			name:     "mysql upsert",
+			options:  Options{Table: "users", Mode: ModeUpsert, BatchSize: 5},
+			expected: "INSERT INTO `users` (`id`, `name`) VALUES\n  ('1', 'O''Brien \\\\ co'),\n  ('2', NULL),\n  ('3', 'x') AS new\nON DUPLICATE KEY UPDATE `id` = new.`id`, `name` = new.`name`;\n",
+		},
+		{
+			name:     "postgres replace becomes upsert on first column",
+			options:  Options{Table: "users", Dialect: db.DialectPostgres, Mode: ModeReplace},
+			expected: "INSERT INTO \"users\" (\"id\", \"name\") VALUES\n  ('1', 'O''Brien \\ co'),\n  ('2', NULL),\n  ('3', 'x')\nON CONFLICT (\"id\") DO UPDATE SET \"id\" = EXCLUDED.\"id\", \"name\" = EXCLUDED.\"name\";\n",
+		},
+		{
+			name:     "postgres ignore",
+			options:  Options{Table: "users", Dialect: db.DialectPostgres, Mode: ModeIgnore, Key: []string{"name"}},
+			expected: "INSERT INTO \"users\" (\"id\", \"name\") VALUES\n  ('1', 'O''Brien \\ co'),\n  ('2', NULL),\n  ('3', 'x')\nON CONFLICT DO NOTHING;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, renderSQL(t, tt.options, rows...))
+		})
+	}
+}
+
+func TestSQLWriterColumnTypes(t *testing.T) {
+	var b strings.Builder
+	w, err := NewWriter("sql", &b, Options{Table: "prices"})
+	assert.NoError(t, err)
+	assert.NoError(t, WriteTypedHeader(w, []string{"id", "amount", "rate", "code"}, []string{"UNSIGNED BIGINT", "DECIMAL", "float8", "VARCHAR"}))
+	assert.NoError(t, w.WriteRow([]*string{ptr("1"), ptr("-12.50"), ptr("1e-3"), ptr("42")}))
+	assert.NoError(t, w.WriteRow([]*string{ptr("2"), nil, ptr("NaN"), ptr("x")}))
+	assert.NoError(t, w.Close())
+
+	// numbers of numeric columns are unquoted, other values and columns stay string literals
+	assert.Equal(t, "INSERT INTO `prices` (`id`, `amount`, `rate`, `code`) VALUES\n  (1, -12.50, 1e-3, '42'),\n  (2, NULL, 'NaN', 'x');\n", b.String())
+}
+
+func TestSQLWriterEmptyAndInvalid(t *testing.T) {
+	assert.Equal(t, "", renderSQL(t, Options{Table: "users"}))
+
+	_, err := NewWriter("sql", &strings.Builder{}, Options{})
+	assert.EqualError(t, err, "sql export needs a table name, use --table=name")
+
+	_, err = NewWriter("sql", &strings.Builder{}, Options{Table: "t", Dialect: "oracle"})
+	assert.Error(t, err)
+
+	_, err = NewWriter("sql", &strings.Builder{}, Options{Table: "t", Mode: "merge"})
+	assert.Error(t, err)
+}
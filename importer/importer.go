@@ -0,0 +1,358 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// File is an import file read row by row, its rows are read again for the import instead of kept in memory
+type File struct {
+	Path    string
+	Columns []string
+	// number of rows found by Scan
+	RowCount int
+	// field delimiter of delimited files, empty for JSON
+	Delimiter string
+	// line terminator of delimited files, "\n" or "\r\n"
+	LineEnd string
+	// EmptyStrings tells a delimited file has quoted empty fields, LOAD DATA can't tell them from NULLs
+	EmptyStrings bool
+}
+
+// Scan reads a CSV, TSV, JSON array or JSON lines file chosen by its extension once, passing every row to visit.
+// Nil values are SQL NULLs, rows of JSON files only hold the columns seen so far
+func Scan(path string, visit func(row []*string)) (*File, error) {
+	file := &File{Path: path}
+	err := read(file, func(row []*string) error {
+		file.RowCount++
+		visit(row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Rows reads the rows of a scanned file again, each holding all its columns, and stops at the first error of each
+func (f *File) Rows(each func(row []*string) error) error {
+	return read(&File{Path: f.Path}, func(row []*string) error {
+		for len(row) < len(f.Columns) {
+			row = append(row, nil)
+		}
+		return each(row)
+	})
+}
+
+// read parses the file by its extension, filling in its columns and format and passing each row to visit
+func read(file *File, visit func(row []*string) error) error {
+	var readRows func(reader io.Reader, file *File, visit func(row []*string) error) error
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".csv", ".tsv", ".tab":
+		readRows = readDelimited
+	case ".json", ".jsonl", ".ndjson":
+		readRows = readJSON
+	default:
+		return fmt.Errorf("unknown import format of %s, expected .csv, .tsv, .json or .jsonl", file.Path)
+	}
+
+	content, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return readRows(content, file, visit)
+}
+
+// readDelimited parses RFC 4180 content whose first record is the header. Unquoted empty fields are NULLs,
+// as written by the csv and tsv exports, and quoted ones empty strings
+func readDelimited(content io.Reader, file *File, visit func(row []*string) error) error {
+	reader := &recordReader{reader: bufio.NewReader(content), delimiter: ','}
+	if strings.ToLower(filepath.Ext(file.Path)) != ".csv" {
+		reader.delimiter = '\t'
+		reader.lazyQuotes = true
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return err
+	}
+	file.Columns = make([]string, len(header))
+	for i, name := range header {
+		if name != nil {
+			file.Columns[i] = *name
+		}
+	}
+	file.Delimiter = string(reader.delimiter)
+	file.LineEnd = reader.lineEnd
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(row) != len(header) {
+			return fmt.Errorf("line %d has %d fields, expected %d", reader.recordLine, len(row), len(header))
+		}
+		file.EmptyStrings = file.EmptyStrings || reader.emptyString
+		if err := visit(row); err != nil {
+			return err
+		}
+	}
+}
+
+// recordReader reads RFC 4180 records, telling quoted empty fields from unquoted ones which encoding/csv doesn't
+type recordReader struct {
+	reader    *bufio.Reader
+	delimiter rune
+	// lazyQuotes allows quotes inside unquoted fields and text after a closing quote
+	lazyQuotes bool
+	// line terminator of the first line
+	lineEnd string
+	// line of the last line read and the line the last record started on
+	line       int
+	recordLine int
+	// emptyString tells the last record has a quoted empty field
+	emptyString bool
+}
+
+// readLine reads the next line including its terminator, io.EOF once none is left
+func (r *recordReader) readLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	r.line++
+	if r.lineEnd == "" {
+		r.lineEnd = "\n"
+		if strings.HasSuffix(line, "\r\n") {
+			r.lineEnd = "\r\n"
+		}
+	}
+	return line, nil
+}
+
+// Read returns the fields of the next record skipping empty lines, nil for unquoted empty fields
+func (r *recordReader) Read() ([]*string, error) {
+	line, err := r.readLine()
+	for err == nil && strings.TrimRight(line, "\r\n") == "" {
+		line, err = r.readLine()
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.recordLine = r.line
+	r.emptyString = false
+
+	var fields []*string
+	for {
+		// line holds the rest of the record from the start of a field
+		if !strings.HasPrefix(line, `"`) {
+			end := strings.IndexRune(line, r.delimiter)
+			field := line
+			if end >= 0 {
+				field = line[:end]
+			} else {
+				field = strings.TrimSuffix(strings.TrimSuffix(field, "\n"), "\r")
+			}
+			if !r.lazyQuotes && strings.Contains(field, `"`) {
+				return nil, fmt.Errorf("line %d: bare \" in non-quoted field", r.line)
+			}
+			if field == "" {
+				fields = append(fields, nil)
+			} else {
+				fields = append(fields, &field)
+			}
+			if end < 0 {
+				return fields, nil
+			}
+			line = line[end+utf8.RuneLen(r.delimiter):]
+			continue
+		}
+
+		var value strings.Builder
+		line = line[1:]
+		for {
+			quote := strings.IndexByte(line, '"')
+			if quote < 0 {
+				// the quoted field continues on the next line
+				value.WriteString(line)
+				if line, err = r.readLine(); err == io.EOF {
+					return nil, fmt.Errorf("line %d: extraneous or missing \" in quoted field", r.recordLine)
+				} else if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value.WriteString(line[:quote])
+			line = line[quote+1:]
+			if !strings.HasPrefix(line, `"`) {
+				break
+			}
+			value.WriteByte('"')
+			line = line[1:]
+		}
+
+		if end := strings.IndexRune(line, r.delimiter); r.lazyQuotes && end != 0 {
+			// text after the closing quote belongs to the field
+			rest := line
+			if end > 0 {
+				rest = line[:end]
+				line = line[end:]
+			} else {
+				line = ""
+			}
+			value.WriteString(strings.TrimSuffix(strings.TrimSuffix(rest, "\n"), "\r"))
+		}
+		field := value.String()
+		r.emptyString = r.emptyString || field == ""
+		fields = append(fields, &field)
+
+		switch {
+		case strings.HasPrefix(line, string(r.delimiter)):
+			line = line[utf8.RuneLen(r.delimiter):]
+		case strings.TrimRight(line, "\r\n") == "":
+			return fields, nil
+		default:
+			return nil, fmt.Errorf("line %d: extraneous or missing \" in quoted field", r.line)
+		}
+	}
+}
+
+// readJSON parses an array of objects or one object per line.
+// Columns follow key order of the objects, keys missing in a row are NULLs
+func readJSON(content io.Reader, file *File, visit func(row []*string) error) error {
+	buffered := bufio.NewReader(content)
+	array, err := startsArray(buffered)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(buffered)
+	if array {
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	}
+
+	positions := map[string]int{}
+	objects := 0
+	for decoder.More() {
+		object, keys, err := readObject(decoder)
+		if err != nil {
+			return fmt.Errorf("object %d: %w", objects+1, err)
+		}
+		objects++
+		for _, key := range keys {
+			if _, ok := positions[key]; !ok {
+				positions[key] = len(file.Columns)
+				file.Columns = append(file.Columns, key)
+			}
+		}
+
+		row := make([]*string, len(file.Columns))
+		for key, value := range object {
+			row[positions[key]] = value
+		}
+		if err := visit(row); err != nil {
+			return err
+		}
+	}
+	if array {
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	}
+	if len(file.Columns) == 0 {
+		return fmt.Errorf("file has no objects to import")
+	}
+	return nil
+}
+
+// startsArray reports whether the content starts with [ after white space
+func startsArray(content *bufio.Reader) (bool, error) {
+	for {
+		c, err := content.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !unicode.IsSpace(rune(c)) {
+			return c == '[', content.UnreadByte()
+		}
+	}
+}
+
+// readObject decodes one JSON object keeping its key order. Numbers keep their text,
+// booleans become 1 and 0 and nested values are kept as JSON text
+func readObject(decoder *json.Decoder) (map[string]*string, []string, error) {
+	var message json.RawMessage
+	if err := decoder.Decode(&message); err != nil {
+		return nil, nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(message, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	// walk the same bytes again since maps lose key order
+	var keys []string
+	keyDecoder := json.NewDecoder(bytes.NewReader(message))
+	if _, err := keyDecoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	for keyDecoder.More() {
+		token, err := keyDecoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, token.(string))
+		var skip json.RawMessage
+		if err := keyDecoder.Decode(&skip); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	object := make(map[string]*string, len(raw))
+	for key, value := range raw {
+		object[key] = jsonValue(value)
+	}
+	return object, keys, nil
+}
+
+// jsonValue converts a raw JSON value to a column value
+func jsonValue(value json.RawMessage) *string {
+	text := strings.TrimSpace(string(value))
+	switch {
+	case text == "null":
+		return nil
+	case text == "true":
+		text = "1"
+	case text == "false":
+		text = "0"
+	case strings.HasPrefix(text, `"`):
+		var unquoted string
+		if err := json.Unmarshal(value, &unquoted); err == nil {
+			text = unquoted
+		}
+	}
+	return &text
+}
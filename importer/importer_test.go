@@ -0,0 +1,124 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr(value string) *string {
+	return &value
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name                 string
+		file                 string
+		content              string
+		expectedColumns      []string
+		expectedRows         [][]*string
+		expectedLineEnd      string
+		expectedEmptyStrings bool
+		expectedError        string
+	}{
+		{
+			name:            "csv with quotes and nulls",
+			file:            "users.csv",
+			content:         "id,name,note\r\n1,\"Smith, John\",\r\n2,Ann,\"say \"\"hi\"\"\"\r\n",
+			expectedColumns: []string{"id", "name", "note"},
+			expectedRows: [][]*string{
+				{ptr("1"), ptr("Smith, John"), nil},
+				{ptr("2"), ptr("Ann"), ptr(`say "hi"`)},
+			},
+			expectedLineEnd: "\r\n",
+		},
+		{
+			name:            "csv quoted empty strings and multi line fields",
+			file:            "notes.csv",
+			content:         "id,note\n\n1,\"\"\n2,\"two\nlines\"\n",
+			expectedColumns: []string{"id", "note"},
+			expectedRows: [][]*string{
+				{ptr("1"), ptr("")},
+				{ptr("2"), ptr("two\nlines")},
+			},
+			expectedLineEnd:      "\n",
+			expectedEmptyStrings: true,
+		},
+		{
+			name:          "csv unterminated quote",
+			file:          "bad.csv",
+			content:       "id,note\n1,\"open\n",
+			expectedError: "line 2: extraneous or missing \" in quoted field",
+		},
+		{
+			name:            "tsv",
+			file:            "users.tsv",
+			content:         "id\tname\n1\tJohn\n",
+			expectedColumns: []string{"id", "name"},
+			expectedRows:    [][]*string{{ptr("1"), ptr("John")}},
+			expectedLineEnd: "\n",
+		},
+		{
+			name:            "json array keeps key order",
+			file:            "users.json",
+			content:         `[{"name": "John", "id": 1, "active": true}, {"id": 2, "tags": ["a"], "name": null}]`,
+			expectedColumns: []string{"name", "id", "active", "tags"},
+			expectedRows: [][]*string{
+				{ptr("John"), ptr("1"), ptr("1"), nil},
+				{nil, ptr("2"), nil, ptr(`["a"]`)},
+			},
+		},
+		{
+			name:            "json lines",
+			file:            "users.jsonl",
+			content:         "{\"id\": 1.5}\n{\"id\": 2}\n",
+			expectedColumns: []string{"id"},
+			expectedRows:    [][]*string{{ptr("1.5")}, {ptr("2")}},
+		},
+		{
+			name:          "ragged csv",
+			file:          "bad.csv",
+			content:       "id,name\n1\n",
+			expectedError: "line 2 has 1 fields, expected 2",
+		},
+		{
+			name:          "unknown extension",
+			file:          "users.xml",
+			content:       "<users/>",
+			expectedError: "unknown import format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			var scanned [][]*string
+			file, err := Scan(path, func(row []*string) {
+				scanned = append(scanned, row)
+			})
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedColumns, file.Columns)
+			assert.Equal(t, len(tt.expectedRows), file.RowCount)
+			assert.Equal(t, tt.expectedLineEnd, file.LineEnd)
+			assert.Equal(t, tt.expectedEmptyStrings, file.EmptyStrings)
+
+			// reading the rows again pads them to all columns
+			var rows [][]*string
+			assert.NoError(t, file.Rows(func(row []*string) error {
+				rows = append(rows, row)
+				return nil
+			}))
+			assert.Equal(t, tt.expectedRows, rows)
+			assert.Len(t, scanned, len(rows))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"rel8/db"
+)
+
+// Column is a target column with the SQL type inferred from its values
+type Column struct {
+	Name string
+	Type string
+	// count of NULL values
+	Nulls int
+	// first non NULL value, shown in the preview
+	Sample string
+}
+
+// kind is the narrowest value class fitting every value of a column, ordered from narrow to wide
+type kind int
+
+const (
+	kindNull kind = iota
+	kindInteger
+	kindDecimal
+	kindDate
+	kindDatetime
+	kindText
+)
+
+// varcharLimit is the longest value still stored as VARCHAR instead of TEXT
+const varcharLimit = 255
+
+var datetimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04:05.999999", "2006-01-02T15:04:05.999999"}
+
+// InferColumns reads an import file once and derives a column type per column of it for the dialect
+func InferColumns(path string, dialect string) (*File, []Column, error) {
+	var values inference
+	file, err := Scan(path, values.add)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, values.columns(file.Columns, dialect), nil
+}
+
+// inference collects the kinds of the values of each column, one row at a time
+type inference struct {
+	found   []Column
+	kinds   []kind
+	longest []int
+	rows    int
+}
+
+// add classifies the values of a row, columns missing from it are NULLs
+func (in *inference) add(row []*string) {
+	in.grow(len(row))
+	for i := range in.found {
+		if i >= len(row) || row[i] == nil {
+			in.found[i].Nulls++
+			continue
+		}
+		value := *row[i]
+		if in.found[i].Sample == "" {
+			in.found[i].Sample = value
+		}
+		in.longest[i] = max(in.longest[i], len([]rune(value)))
+		in.kinds[i] = widen(in.kinds[i], classify(value))
+	}
+	in.rows++
+}
+
+// grow adds columns first seen after some rows, those rows hold NULLs in them
+func (in *inference) grow(count int) {
+	for len(in.found) < count {
+		in.found = append(in.found, Column{Nulls: in.rows})
+		in.kinds = append(in.kinds, kindNull)
+		in.longest = append(in.longest, 0)
+	}
+}
+
+// columns names the columns and maps their kinds to types of the dialect
+func (in *inference) columns(names []string, dialect string) []Column {
+	in.grow(len(names))
+	columns := make([]Column, len(names))
+	for i, name := range names {
+		columns[i] = in.found[i]
+		columns[i].Name = name
+		columns[i].Type = typeName(in.kinds[i], in.longest[i], dialect)
+	}
+	return columns
+}
+
+// classify returns the narrowest kind of a single value
+func classify(value string) kind {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return kindInteger
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+		return kindDecimal
+	}
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		return kindDate
+	}
+	for _, layout := range datetimeLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return kindDatetime
+		}
+	}
+	return kindText
+}
+
+// widen combines the kind of a column so far with the kind of another value
+func widen(current kind, next kind) kind {
+	switch {
+	case current == kindNull:
+		return next
+	case current == next:
+		return current
+	case current <= kindDecimal && next <= kindDecimal:
+		return kindDecimal
+	case (current == kindDate || current == kindDatetime) && (next == kindDate || next == kindDatetime):
+		return kindDatetime
+	default:
+		return kindText
+	}
+}
+
+// typeName maps a kind to a column type of the dialect
+func typeName(columnKind kind, longest int, dialect string) string {
+	switch columnKind {
+	case kindInteger:
+		if dialect == db.DialectSqlite {
+			return "INTEGER"
+		}
+		return "BIGINT"
+	case kindDecimal:
+		switch dialect {
+		case db.DialectPostgres:
+			return "DOUBLE PRECISION"
+		case db.DialectSqlite:
+			return "REAL"
+		}
+		return "DOUBLE"
+	case kindDate:
+		if dialect == db.DialectSqlite {
+			return "TEXT"
+		}
+		return "DATE"
+	case kindDatetime:
+		switch dialect {
+		case db.DialectPostgres:
+			return "TIMESTAMP"
+		case db.DialectSqlite:
+			return "TEXT"
+		}
+		return "DATETIME"
+	default:
+		if dialect == db.DialectSqlite || longest > varcharLimit {
+			return "TEXT"
+		}
+		return fmt.Sprintf("VARCHAR(%d)", varcharLimit)
+	}
+}
+
+// CreateTableSQL returns a CREATE TABLE statement for the columns
+func CreateTableSQL(dialect string, table string, columns []Column) string {
+	var definitions []string
+	for _, column := range columns {
+		definitions = append(definitions, "  "+db.QuoteIdentifier(dialect, column.Name)+" "+column.Type)
+	}
+	return "CREATE TABLE " + db.QuoteIdentifier(dialect, table) + " (\n" + strings.Join(definitions, ",\n") + "\n)"
+}
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestInferColumns(t *testing.T) {
+	names := []string{"id", "price", "born", "seen", "name", "empty", "long"}
+	var values inference
+	values.add([]*string{ptr("1"), ptr("10"), ptr("2024-01-02"), ptr("2024-01-02"), ptr("John"), nil, ptr(strings.Repeat("x", 300))})
+	values.add([]*string{ptr("2"), ptr("10.5"), ptr("1999-12-31"), ptr("2024-01-02 10:00:00"), ptr("42"), nil, ptr("y")})
+
+	tests := []struct {
+		dialect       string
+		expectedTypes []string
+	}{
+		{db.DialectMysql, []string{"BIGINT", "DOUBLE", "DATE", "DATETIME", "VARCHAR(255)", "VARCHAR(255)", "TEXT"}},
+		{db.DialectPostgres, []string{"BIGINT", "DOUBLE PRECISION", "DATE", "TIMESTAMP", "VARCHAR(255)", "VARCHAR(255)", "TEXT"}},
+		{db.DialectSqlite, []string{"INTEGER", "REAL", "TEXT", "TEXT", "TEXT", "TEXT", "TEXT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			columns := values.columns(names, tt.dialect)
+			var types []string
+			for _, column := range columns {
+				types = append(types, column.Type)
+			}
+			assert.Equal(t, tt.expectedTypes, types)
+		})
+	}
+
+	columns := values.columns(names, db.DialectMysql)
+	assert.Equal(t, Column{Name: "name", Type: "VARCHAR(255)", Sample: "John"}, columns[4])
+	assert.Equal(t, 2, columns[5].Nulls)
+
+	// a key first seen in a later JSON object is NULL in the objects before it
+	path := filepath.Join(t.TempDir(), "users.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("{\"id\": 1}\n{\"id\": 2, \"note\": \"x\"}\n{\"id\": 3}\n"), 0o644))
+	file, columns, err := InferColumns(path, db.DialectMysql)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, file.RowCount)
+	assert.Equal(t, []Column{
+		{Name: "id", Type: "BIGINT", Sample: "1"},
+		{Name: "note", Type: "VARCHAR(255)", Nulls: 2, Sample: "x"},
+	}, columns)
+}
+
+func TestCreateTableSQL(t *testing.T) {
+	columns := []Column{{Name: "id", Type: "BIGINT"}, {Name: "full name", Type: "TEXT"}}
+
+	assert.Equal(t, "CREATE TABLE `people` (\n  `id` BIGINT,\n  `full name` TEXT\n)", CreateTableSQL(db.DialectMysql, "people", columns))
+	assert.Equal(t, "CREATE TABLE \"people\" (\n  \"id\" BIGINT,\n  \"full name\" TEXT\n)", CreateTableSQL(db.DialectPostgres, "people", columns))
+}
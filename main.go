@@ -12,8 +12,16 @@ func main() {
 	server := db.Connect(connectString, useMock)
 
 	stateManager := model.NewContextualStateManager(server, *model.Initial, 20)
+	stateManager.SetProfiles(config.Profiles())
+	stateManager.SetReplicationLag(config.ReplicationLag())
 	view := view.NewView(stateManager)
 	view.OnStateTransition(model.StateTransition{*model.Initial, *model.Initial})
+	// feed server rates into the header
+	stopMetrics := stateManager.MonitorMetrics(view.UpdateMetrics)
+	defer stopMetrics()
+	// flag replication lag and errors in the header
+	stopReplication := stateManager.MonitorReplication(view.UpdateReplication)
+	defer stopReplication()
 
 	// Add a callback to notify view (synchronous to avoid race conditions)
 	stateManager.AddSyncCallback(func(transition model.StateTransition) {
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"context"
+	"strings"
+
+	"rel8/db"
+)
+
+// bindAndRun runs sql right away without placeholders, otherwise after asking for their values.
+// run gets the statement with the placeholders of the driver and the values to bind to them
+func (csm *ContextualStateManager) bindAndRun(ctx context.Context, sql string, run func(ctx context.Context, sql string, args []any)) {
+	dialect := csm.server.Dialect()
+	params := db.FindBindParams(dialect, sql)
+	if len(params) == 0 {
+		run(ctx, sql, nil)
+		return
+	}
+
+	hints := csm.bindHints(ctx, sql, params)
+	fields := make([]FormField, len(params))
+	for i, param := range params {
+		fields[i] = FormField{Label: param.Name, Hint: hints[i], Value: csm.bindValues[param.Name]}
+	}
+	csm.PushState(ctx, State{
+		Mode:       Form,
+		StatusText: "bind parameters, NULL binds null",
+		FormFields: fields,
+		submit: func(ctx context.Context, values []string) {
+			byName := make(map[string]string, len(params))
+			for i, param := range params {
+				byName[param.Name] = values[i]
+				csm.bindValues[param.Name] = values[i]
+			}
+			bound, args := db.BindSQL(dialect, sql, byName)
+			run(ctx, bound, args)
+		},
+	})
+}
+
+// bindHints describes the value expected by every placeholder, the column it is compared with
+// and the type of that column in the tables of the statement
+func (csm *ContextualStateManager) bindHints(ctx context.Context, sql string, params []db.BindParam) []string {
+	hints := make([]string, len(params))
+	var columnTypes map[string]string
+	for i, param := range params {
+		if param.Column == "" {
+			continue
+		}
+		if columnTypes == nil {
+			columnTypes = csm.columnTypes(ctx, db.ReferencedTables(sql))
+		}
+		hints[i] = strings.TrimSpace(param.Column + " " + columnTypes[strings.ToLower(param.Column)])
+	}
+	return hints
+}
+
+// columnTypes maps the lower case column names of tables to their types, the first table wins
+func (csm *ContextualStateManager) columnTypes(ctx context.Context, tables []string) map[string]string {
+	types := map[string]string{}
+	for _, table := range tables {
+		_, columns := csm.server.FetchTableColumns(ctx, table)
+		for _, data := range columns {
+			column, ok := data.(db.MysqlColumn)
+			if !ok {
+				continue
+			}
+			if _, seen := types[strings.ToLower(column.Name)]; !seen {
+				types[strings.ToLower(column.Name)] = column.Type
+			}
+		}
+	}
+	return types
+}
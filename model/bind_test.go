@@ -0,0 +1,73 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventSQLModeBindsParameters(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	enter := tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+	sql := "SELECT * FROM users WHERE id = :id AND name LIKE ? AND 1 = ?"
+
+	stateManager.PushState(ctx, State{Mode: SQL})
+	stateManager.HandleEvent(&Event{Event: enter, Text: sql})
+
+	form := stateManager.GetCurrentState()
+	assert.Equal(t, Form, form.Mode)
+	assert.Equal(t, "bind parameters, NULL binds null", form.StatusText)
+	assert.Equal(t, []FormField{
+		{Label: ":id", Hint: "id int"},
+		{Label: "?1", Hint: "name varchar(100)"},
+		{Label: "?2"},
+	}, form.FormFields)
+
+	stateManager.HandleEvent(&Event{Event: enter, Values: []string{"42", "A%", "NULL"}})
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == Browse }, time.Second, 10*time.Millisecond)
+	result := stateManager.GetCurrentState()
+	assert.Equal(t, Browse, result.Mode)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ? AND name LIKE ? AND 1 = ?", result.Query)
+	assert.Equal(t, []any{"42", "A%", nil}, result.QueryArgs)
+
+	// running the query again offers the last values
+	stateManager.PopState(ctx)
+	stateManager.HandleEvent(&Event{Event: enter, Text: sql})
+	assert.Equal(t, "42", stateManager.GetCurrentState().FormFields[0].Value)
+	assert.Equal(t, "NULL", stateManager.GetCurrentState().FormFields[2].Value)
+
+	// without placeholders the query runs right away
+	stateManager.PopState(ctx)
+	stateManager.HandleEvent(&Event{Event: enter, Text: "SELECT ':id'"})
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Query == "SELECT ':id'" }, time.Second, 10*time.Millisecond)
+	assert.Nil(t, stateManager.GetCurrentState().QueryArgs)
+}
+
+func TestHandleEventEditorBindsParameters(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	script := "SELECT * FROM users WHERE id = ?;\nUPDATE users SET name = ? WHERE id = ?;"
+	stateManager.PushState(ctx, State{Mode: Editor})
+
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModNone), Text: script, Cursor: 3})
+	assert.Equal(t, []FormField{{Label: "?1", Hint: "id int"}}, stateManager.GetCurrentState().FormFields)
+
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Values: []string{"7"}})
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == Browse }, time.Second, 10*time.Millisecond)
+	result := stateManager.GetCurrentState()
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", result.Query)
+	assert.Equal(t, []any{"7"}, result.QueryArgs)
+	assert.Equal(t, "10 rows in 1.0ms", result.StatusText)
+
+	// a script is not bound
+	stateManager.PopState(ctx)
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyF29, 0, tcell.ModNone), Text: script})
+	editor := stateManager.GetCurrentState()
+	assert.Equal(t, Editor, editor.Mode)
+	assert.Equal(t, "placeholders are bound in a single statement, F5 runs the one under the cursor", editor.StatusText)
+}
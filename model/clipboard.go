@@ -0,0 +1,110 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"rel8/db"
+	"rel8/export"
+)
+
+// SetClipboardWriter sets the function receiving copied text
+func (csm *ContextualStateManager) SetClipboardWriter(write func(text string)) {
+	csm.mu.Lock()
+	defer csm.mu.Unlock()
+	csm.clipboardWriter = write
+}
+
+// copyToClipboard handles the key following the y prefix:
+// in browse mode and on table tabs c copies the cell, y the row as TSV, j the row as JSON,
+// a the whole result as TSV and A the whole result as JSON.
+// In detail mode and on text tabs y copies the displayed text
+func (csm *ContextualStateManager) copyToClipboard(ctx context.Context, ev *Event) {
+	state := csm.GetCurrentState()
+	if ev.Event.Rune() == 0 {
+		return
+	}
+
+	var text, what string
+	switch state.Mode {
+	case Detail:
+		if ev.Event.Rune() == 'y' {
+			text, what = state.DetailText, "text"
+		}
+	case Tabbed:
+		if state.SelectedTab < 0 || state.SelectedTab >= len(state.Tabs) {
+			break
+		}
+		tab := state.Tabs[state.SelectedTab]
+		if tab.TableHeaders != nil {
+			text, what = copyFromTable(State{TableHeaders: tab.TableHeaders, TableData: tab.TableData}, ev.Row-1, ev.Column, ev.Event.Rune())
+		} else if ev.Event.Rune() == 'y' {
+			text, what = tab.DetailText, "text"
+		}
+	case Browse:
+		text, what = copyFromTable(state, ev.Row-1, ev.Column, ev.Event.Rune())
+	}
+	if what == "" {
+		return
+	}
+
+	csm.mu.RLock()
+	write := csm.clipboardWriter
+	csm.mu.RUnlock()
+	if write != nil {
+		write(text)
+	}
+
+	if state.Mode == Browse {
+		state.SelectedDataIndex = ev.Row - 1
+		state.StatusText = fmt.Sprintf("copied %s to clipboard", what)
+		csm.ReplaceState(ctx, state)
+	}
+}
+
+// copyFromTable renders the part of table data selected by key, returning text and its description
+func copyFromTable(state State, row int, column int, key rune) (string, string) {
+	if row < 0 || row >= len(state.TableData) {
+		return "", ""
+	}
+	item := state.TableData[row]
+
+	switch key {
+	case 'c':
+		values := export.Values(item, state.TableHeaders)
+		if column < 0 || column >= len(values) {
+			return "", ""
+		}
+		if values[column] == nil {
+			return "NULL", "cell"
+		}
+		return *values[column], "cell"
+	case 'y':
+		return renderRows("tsv", state.TableHeaders, []db.TableData{item}, false), "row"
+	case 'j':
+		return renderRows("jsonl", state.TableHeaders, []db.TableData{item}, true), "row as JSON"
+	case 'a':
+		return renderRows("tsv", state.TableHeaders, state.TableData, true), fmt.Sprintf("%d rows", len(state.TableData))
+	case 'A':
+		return renderRows("json", state.TableHeaders, state.TableData, true), fmt.Sprintf("%d rows as JSON", len(state.TableData))
+	}
+	return "", ""
+}
+
+// renderRows formats rows with an export writer, header controls whether the header is written
+func renderRows(format string, headers []string, data []db.TableData, header bool) string {
+	var b strings.Builder
+	w, err := export.NewWriter(format, &b, export.Options{})
+	if err != nil {
+		return ""
+	}
+	if header {
+		w.WriteHeader(headers)
+	}
+	for _, item := range data {
+		w.WriteRow(export.Values(item, headers))
+	}
+	w.Close()
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestCopyFromTable(t *testing.T) {
+	state := State{
+		Mode:         Browse,
+		TableHeaders: []string{"id", "note"},
+		TableData: []db.TableData{
+			map[string]string{"id": "1", "note": "tab\there"},
+			map[string]string{"id": "2", "note": "NULL"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		row, column  int
+		key          rune
+		expectedText string
+		expectedWhat string
+	}{
+		{"cell", 0, 1, 'c', "tab\there", "cell"},
+		{"NULL cell", 1, 1, 'c', "NULL", "cell"},
+		{"row as TSV", 0, 0, 'y', "1\t\"tab\there\"", "row"},
+		{"row as JSON", 1, 0, 'j', `{"id": "2", "note": null}`, "row as JSON"},
+		{"all as TSV", 0, 0, 'a', "id\tnote\n1\t\"tab\there\"\n2\t", "2 rows"},
+		{"all as JSON", 0, 0, 'A', "[\n  {\"id\": \"1\", \"note\": \"tab\\there\"},\n  {\"id\": \"2\", \"note\": null}\n]", "2 rows as JSON"},
+		{"unknown key", 0, 0, 'x', "", ""},
+		{"row out of range", 5, 0, 'y', "", ""},
+		{"column out of range", 0, 7, 'c', "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, what := copyFromTable(state, tt.row, tt.column, tt.key)
+			assert.Equal(t, tt.expectedText, text)
+			assert.Equal(t, tt.expectedWhat, what)
+		})
+	}
+}
+
+func TestHandleEventCopyPrefix(t *testing.T) {
+	var copied []string
+	browseState := State{
+		Mode:         Browse,
+		TableMode:    TableRow,
+		TableHeaders: []string{"id", "name"},
+		TableData: []db.TableData{
+			map[string]string{"id": "1", "name": "alice"},
+			map[string]string{"id": "2", "name": "bob"},
+		},
+	}
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, browseState, 10)
+	stateManager.SetClipboardWriter(func(text string) {
+		copied = append(copied, text)
+	})
+
+	press := func(r rune) *tcell.EventKey {
+		return stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), Row: 2, Column: 1})
+	}
+
+	// y waits for the second key
+	assert.Nil(t, press('y'))
+	assert.Empty(t, copied)
+	assert.Nil(t, press('c'))
+	assert.Equal(t, []string{"bob"}, copied)
+	assert.Equal(t, "copied cell to clipboard", stateManager.GetCurrentState().StatusText)
+	assert.Equal(t, 1, stateManager.GetCurrentState().SelectedDataIndex)
+
+	// escape cancels the prefix
+	press('y')
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)})
+	assert.NotNil(t, press('c'))
+	assert.Len(t, copied, 1)
+
+	// a key other than a letter ends the prefix and is handled as usual
+	press('y')
+	assert.NotNil(t, stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone), Row: 2}))
+	assert.NotNil(t, press('c'))
+	assert.Len(t, copied, 1)
+
+	// detail mode copies the raw definition
+	stateManager.ReplaceState(t.Context(), State{Mode: Detail, DetailText: "CREATE TABLE t (id INT)"})
+	press('y')
+	press('y')
+	assert.Equal(t, "CREATE TABLE t (id INT)", copied[1])
+
+	// tabbed mode copies from the visible tab
+	stateManager.ReplaceState(t.Context(), State{Mode: Tabbed, DetailText: "CREATE TABLE t (id INT)", SelectedTab: 1, Tabs: []Tab{
+		{Title: "Columns", TableHeaders: []string{"NAME", "TYPE"}, TableData: []db.TableData{map[string]string{"NAME": "id", "TYPE": "int"}}},
+		{Title: "Comment", DetailText: "ids only"},
+	}})
+	press('y')
+	press('y')
+	assert.Equal(t, "ids only", copied[2])
+
+	stateManager.selectTab(t.Context(), 1)
+	press('y')
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone), Row: 1, Column: 1})
+	assert.Equal(t, "int", copied[3])
+}
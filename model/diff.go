@@ -0,0 +1,290 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"rel8/db"
+	"rel8/export"
+)
+
+const diffUsage = "usage: diff [result number|--rerun] [--key=col,...]"
+
+// DiffKind tells how a row of a diff differs between the two results
+type DiffKind int
+
+const (
+	DiffAdded DiffKind = iota + 1
+	DiffRemoved
+	DiffChanged
+)
+
+// diffMarks are shown in the DIFF column
+var diffMarks = map[DiffKind]string{DiffAdded: "+", DiffRemoved: "-", DiffChanged: "~"}
+
+// RowDiff marks a row of a diff and the columns that changed
+type RowDiff struct {
+	Kind    DiffKind
+	Changed map[string]bool
+}
+
+// diffCounts sums up a diff
+type diffCounts struct {
+	added, removed, changed, unchanged int
+}
+
+// diffCommand compares the current result with an earlier one: a result number, the latest pinned result by
+// default, or with --rerun the same query run again now in the background. Rows are matched by --key, the primary
+// key of the browsed table or an id column. The current result tells why they can't be compared
+func (csm *ContextualStateManager) diffCommand(ctx context.Context, args []string) {
+	current := csm.GetCurrentState()
+	if !isResult(current) {
+		csm.pushOrReport(ctx, State{}, "diff compares query results, run a query first")
+		return
+	}
+
+	var keys []string
+	rerun := false
+	other := -1
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		switch {
+		case name == "--key" && value != "":
+			keys = strings.Split(value, ",")
+		case name == "--rerun":
+			rerun = true
+		default:
+			number, err := strconv.Atoi(arg)
+			if err != nil {
+				csm.pushOrReport(ctx, State{}, diffUsage)
+				return
+			}
+			other = number
+		}
+	}
+
+	if rerun {
+		csm.startRerunDiff(ctx, current, keys)
+		return
+	}
+	state, status := csm.createStateWithDiff(ctx, current, other, keys)
+	csm.pushOrReport(ctx, state, status)
+}
+
+// createStateWithDiff compares a result with the one numbered other, or the latest pinned result when other
+// is -1. On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithDiff(ctx context.Context, current State, other int, keys []string) (State, string) {
+	before := State{}
+	if other >= 0 {
+		found := false
+		for _, result := range csm.results() {
+			if result.resultID == other {
+				before, found = result, true
+			}
+		}
+		if !found {
+			return State{}, fmt.Sprintf("no result %d", other)
+		}
+	} else {
+		csm.mu.RLock()
+		for _, pinned := range csm.pinned {
+			if pinned.resultID != current.resultID {
+				before = pinned
+			}
+		}
+		csm.mu.RUnlock()
+		if before.resultID == 0 {
+			return State{}, "pin a result with p to compare with, or give its number or --rerun"
+		}
+	}
+	return csm.compareResults(ctx, before, current, keys, fmt.Sprintf("result %d with %d", current.resultID, before.resultID))
+}
+
+// startRerunDiff runs the query of a result again in the background, bounded like a watched query, and compares
+// the rows with those shown. The result tells that its query runs, then why the rows can't be compared
+func (csm *ContextualStateManager) startRerunDiff(ctx context.Context, current State, keys []string) {
+	resultID := current.resultID
+	owns := func(state State) bool { return isResult(state) && state.resultID == resultID }
+	current.StatusText = fmt.Sprintf("running the query of result %d again", resultID)
+	csm.ReplaceState(ctx, current)
+
+	go func() {
+		rerunCtx, cancel := context.WithTimeout(context.Background(), watchTimeout)
+		defer cancel()
+
+		after := current
+		after.TableHeaders, after.TableData = csm.server.FetchSqlRows(rerunCtx, current.Query, current.QueryArgs...)
+		if message, failed := queryError(after.TableHeaders, after.TableData); failed {
+			csm.setStatusWhere(owns, "rerun failed: "+message)
+			return
+		}
+		diffState, status := csm.compareResults(rerunCtx, current, after, keys, fmt.Sprintf("result %d rerun", resultID))
+		if status != "" {
+			csm.setStatusWhere(owns, status)
+			return
+		}
+		csm.queueUpdate(func() {
+			csm.PushState(context.Background(), diffState)
+		})
+	}()
+}
+
+// queryError returns the message of the single Error row FetchSqlRows returns when a query fails
+func queryError(headers []string, data []db.TableData) (string, bool) {
+	if !slices.Equal(headers, []string{"Error"}) || len(data) != 1 {
+		return "", false
+	}
+	row, ok := data[0].(map[string]string)
+	return row["Error"], ok
+}
+
+// compareResults diffs the rows of two results, described as compared in the status. Without keys they are
+// picked by diffKeys. On failure it returns a status text instead
+func (csm *ContextualStateManager) compareResults(ctx context.Context, before State, after State, keys []string, compared string) (State, string) {
+	if len(keys) == 0 {
+		keys = csm.diffKeys(ctx, before, after)
+	}
+	for _, key := range keys {
+		if !slices.Contains(before.TableHeaders, key) || !slices.Contains(after.TableHeaders, key) {
+			return State{}, fmt.Sprintf("key column %s is not in both results", key)
+		}
+	}
+
+	headers, data, marks, counts := diffRows(before, after, keys)
+	newState := newBrowseState(ResultDiff, headers, data)
+	newState.Diff = marks
+
+	by := "by " + strings.Join(keys, ", ")
+	if len(keys) == 0 {
+		by = "by whole rows"
+	}
+	newState.StatusText = fmt.Sprintf("%s %s: %d added, %d removed, %d changed, %d unchanged",
+		compared, by, counts.added, counts.removed, counts.changed, counts.unchanged)
+	return newState, ""
+}
+
+// diffKeys picks the columns matching rows of two results: the primary key of the browsed table,
+// otherwise an id column, otherwise none and rows are compared whole
+func (csm *ContextualStateManager) diffKeys(ctx context.Context, before State, after State) []string {
+	if after.SourceTable != "" && after.SourceTable == before.SourceTable {
+		_, indexes := csm.server.FetchTableIndexes(ctx, after.SourceTable)
+		for _, data := range indexes {
+			if index, ok := data.(db.MysqlIndex); ok && index.Name == "PRIMARY" {
+				return strings.Split(index.Columns, ", ")
+			}
+		}
+	}
+	if slices.Contains(before.TableHeaders, "id") && slices.Contains(after.TableHeaders, "id") {
+		return []string{"id"}
+	}
+	return nil
+}
+
+// diffRows matches the rows of two results by keys and returns the added and changed rows of after,
+// followed by the removed rows of before. Without keys rows are matched by the columns of both.
+// A DIFF column leads and changed cells show old → new
+func diffRows(before State, after State, keys []string) ([]string, []db.TableData, []RowDiff, diffCounts) {
+	columns := append([]string{}, after.TableHeaders...)
+	var common []string
+	for _, header := range before.TableHeaders {
+		if slices.Contains(columns, header) {
+			common = append(common, header)
+		} else {
+			columns = append(columns, header)
+		}
+	}
+	if len(keys) == 0 {
+		keys = common
+	}
+
+	beforeRows := rowMaps(before)
+	beforeIndex := map[string]int{}
+	beforeSeen := map[string]int{}
+	for i, row := range beforeRows {
+		beforeIndex[rowKey(row, keys, beforeSeen)] = i
+	}
+
+	var data []db.TableData
+	var marks []RowDiff
+	var counts diffCounts
+	matched := make([]bool, len(beforeRows))
+	seen := map[string]int{}
+	for _, row := range rowMaps(after) {
+		i, ok := beforeIndex[rowKey(row, keys, seen)]
+		if !ok {
+			counts.added++
+			data = append(data, diffRow(DiffAdded, row, columns))
+			marks = append(marks, RowDiff{Kind: DiffAdded})
+			continue
+		}
+		matched[i] = true
+
+		changed := map[string]bool{}
+		shown := map[string]string{}
+		for _, column := range columns {
+			shown[column] = row[column]
+		}
+		for _, column := range common {
+			if old := beforeRows[i][column]; old != row[column] {
+				changed[column] = true
+				shown[column] = old + " → " + row[column]
+			}
+		}
+		if len(changed) == 0 {
+			counts.unchanged++
+			continue
+		}
+		counts.changed++
+		data = append(data, diffRow(DiffChanged, shown, columns))
+		marks = append(marks, RowDiff{Kind: DiffChanged, Changed: changed})
+	}
+
+	for i, row := range beforeRows {
+		if !matched[i] {
+			counts.removed++
+			data = append(data, diffRow(DiffRemoved, row, columns))
+			marks = append(marks, RowDiff{Kind: DiffRemoved})
+		}
+	}
+	return append([]string{"DIFF"}, columns...), data, marks, counts
+}
+
+// rowMaps returns the rows of a result as maps of column to value
+func rowMaps(state State) []map[string]string {
+	rows := make([]map[string]string, len(state.TableData))
+	for i, item := range state.TableData {
+		row := make(map[string]string, len(state.TableHeaders))
+		for j, value := range export.Values(item, state.TableHeaders) {
+			if value == nil {
+				row[state.TableHeaders[j]] = "NULL"
+			} else {
+				row[state.TableHeaders[j]] = *value
+			}
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// rowKey joins the key values of a row, rows sharing a key are told apart by their occurrence counted in seen
+func rowKey(row map[string]string, keys []string, seen map[string]int) string {
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = row[key]
+	}
+	key := strings.Join(values, "\x00")
+	seen[key]++
+	return fmt.Sprintf("%s\x00#%d", key, seen[key])
+}
+
+// diffRow builds a row of the diff grid
+func diffRow(kind DiffKind, row map[string]string, columns []string) map[string]string {
+	shown := map[string]string{"DIFF": diffMarks[kind]}
+	for _, column := range columns {
+		shown[column] = row[column]
+	}
+	return shown
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestDiffRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   State
+		after    State
+		keys     []string
+		headers  []string
+		data     []db.TableData
+		marks    []RowDiff
+		expected diffCounts
+	}{
+		{
+			name: "by key",
+			before: State{TableHeaders: []string{"id", "name"}, TableData: []db.TableData{
+				map[string]string{"id": "1", "name": "ann"},
+				map[string]string{"id": "2", "name": "bob"},
+				map[string]string{"id": "3", "name": "cy"},
+			}},
+			after: State{TableHeaders: []string{"id", "name"}, TableData: []db.TableData{
+				map[string]string{"id": "1", "name": "ann"},
+				map[string]string{"id": "2", "name": "rob"},
+				map[string]string{"id": "4", "name": "dee"},
+			}},
+			keys:    []string{"id"},
+			headers: []string{"DIFF", "id", "name"},
+			data: []db.TableData{
+				map[string]string{"DIFF": "~", "id": "2", "name": "bob → rob"},
+				map[string]string{"DIFF": "+", "id": "4", "name": "dee"},
+				map[string]string{"DIFF": "-", "id": "3", "name": "cy"},
+			},
+			marks: []RowDiff{
+				{Kind: DiffChanged, Changed: map[string]bool{"name": true}},
+				{Kind: DiffAdded},
+				{Kind: DiffRemoved},
+			},
+			expected: diffCounts{added: 1, removed: 1, changed: 1, unchanged: 1},
+		},
+		{
+			name: "whole rows with duplicates and a dropped column",
+			before: State{TableHeaders: []string{"name", "age"}, TableData: []db.TableData{
+				map[string]string{"name": "ann", "age": "30"},
+				map[string]string{"name": "ann", "age": "30"},
+			}},
+			after: State{TableHeaders: []string{"name"}, TableData: []db.TableData{
+				map[string]string{"name": "ann"},
+			}},
+			headers: []string{"DIFF", "name", "age"},
+			data: []db.TableData{
+				map[string]string{"DIFF": "-", "name": "ann", "age": "30"},
+			},
+			marks:    []RowDiff{{Kind: DiffRemoved}},
+			expected: diffCounts{removed: 1, unchanged: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, data, marks, counts := diffRows(tt.before, tt.after, tt.keys)
+			assert.Equal(t, tt.headers, headers)
+			assert.Equal(t, tt.data, data)
+			assert.Equal(t, tt.marks, marks)
+			assert.Equal(t, tt.expected, counts)
+		})
+	}
+}
+
+func TestHandleEventDiffCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	diff := func(command string) State {
+		stateManager.PushState(ctx, State{Mode: Command})
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: command})
+		return stateManager.GetCurrentState()
+	}
+
+	assert.Equal(t, "diff compares query results, run a query first", diff("diff").StatusText)
+
+	stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT 1"))
+	assert.Equal(t, "pin a result with p to compare with, or give its number or --rerun", diff("diff").StatusText)
+	assert.Equal(t, diffUsage, diff("diff latest").StatusText)
+	assert.Equal(t, "no result 7", diff("diff 7").StatusText)
+
+	// the query runs again in the background
+	assert.Equal(t, "running the query of result 1 again", diff("diff --rerun --key=name").StatusText)
+	assert.Eventually(t, func() bool {
+		return stateManager.GetCurrentState().StatusText == "key column name is not in both results"
+	}, time.Second, 10*time.Millisecond)
+
+	// the same query run again is unchanged
+	diff("diff --rerun")
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().TableMode == ResultDiff }, time.Second, 10*time.Millisecond)
+	rerun := stateManager.GetCurrentState()
+	assert.Equal(t, "result 1 rerun by id: 0 added, 0 removed, 0 changed, 10 unchanged", rerun.StatusText)
+	assert.Empty(t, rerun.TableData)
+	stateManager.PopState(ctx)
+
+	// the mock echoes the query, so every row changed
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone), Row: 1})
+	stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT 2"))
+	pinned := diff("diff")
+	assert.Equal(t, []string{"DIFF", "id", "result", "query_executed"}, pinned.TableHeaders)
+	assert.Equal(t, "result 2 with 1 by id: 0 added, 0 removed, 10 changed, 0 unchanged", pinned.StatusText)
+	assert.Equal(t, "SELECT 1 → SELECT 2", pinned.TableData[0].(map[string]string)["query_executed"])
+	assert.Equal(t, RowDiff{Kind: DiffChanged, Changed: map[string]bool{"query_executed": true}}, pinned.Diff[0])
+	stateManager.PopState(ctx)
+
+	numbered := diff("diff 1 --key=result")
+	assert.Equal(t, "result 2 with 1 by result: 0 added, 0 removed, 10 changed, 0 unchanged", numbered.StatusText)
+}
+
+// failingServer fails queries once broken
+type failingServer struct {
+	db.MysqlMock
+	broken atomic.Bool
+}
+
+func (s *failingServer) FetchSqlRows(ctx context.Context, sqlQuery string, args ...any) ([]string, []db.TableData) {
+	if s.broken.Load() {
+		return []string{"Error"}, []db.TableData{map[string]string{"Error": "Lock wait timeout exceeded"}}
+	}
+	return s.MysqlMock.FetchSqlRows(ctx, sqlQuery, args...)
+}
+
+func TestHandleEventDiffRerunFails(t *testing.T) {
+	server := &failingServer{}
+	stateManager := NewContextualStateManager(server, *Initial, 10)
+	ctx := context.Background()
+	stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT 1"))
+	server.broken.Store(true)
+
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "diff --rerun"})
+
+	// the error is reported rather than compared with
+	assert.Eventually(t, func() bool {
+		return stateManager.GetCurrentState().StatusText == "rerun failed: Lock wait timeout exceeded"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, TableRow, stateManager.GetCurrentState().TableMode)
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"rel8/db"
+)
+
+// topLimit is how many statements :top lists
+const topLimit = 100
+
+// createStateWithTop lists the normalised statements that took the most time in total, with their calls,
+// average latency and rows. On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithTop(ctx context.Context) (State, string) {
+	digests, err := csm.server.FetchStatementDigests(ctx, topLimit)
+	if err != nil {
+		slog.Error("reading statement digests failed", "error", err)
+		return State{}, fmt.Sprintf("reading statements failed: %v", err)
+	}
+
+	headers := []string{"QUERY", "CALLS", "TOTAL", "AVG", "ROWS EXAMINED", "ROWS SENT", "DB"}
+	data := []db.TableData{}
+	for _, digest := range digests {
+		examined := ""
+		if digest.RowsExamined >= 0 {
+			examined = strconv.FormatInt(digest.RowsExamined, 10)
+		}
+		data = append(data, map[string]string{
+			"QUERY": summarizeStatement(digest.Text), "CALLS": strconv.FormatInt(digest.Calls, 10),
+			"TOTAL": formatDuration(digest.TotalTime), "AVG": formatDuration(digest.AvgTime),
+			"ROWS EXAMINED": examined, "ROWS SENT": strconv.FormatInt(digest.RowsSent, 10), "DB": digest.Db,
+		})
+	}
+
+	newState := newBrowseState(TopStatements, headers, data)
+	newState.digests = digests
+	newState.StatusText = fmt.Sprintf("%s by total time, Enter shows the statement, x explains a sample",
+		pluralize(len(digests), "statement"))
+	if len(digests) == 0 {
+		newState.StatusText = "no statements recorded yet"
+	}
+	return newState, ""
+}
+
+// selectedDigest returns the statement of a grid row
+func selectedDigest(state State, row int) (db.StatementDigest, bool) {
+	if row < 1 || row > len(state.digests) {
+		return db.StatementDigest{}, false
+	}
+	return state.digests[row-1], true
+}
+
+// describeDigest shows a statement with its timing, followed by its sample when the server keeps one
+func describeDigest(digest db.StatementDigest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s in %s, %s on average", pluralize(int(digest.Calls), "call"),
+		formatDuration(digest.TotalTime), formatDuration(digest.AvgTime))
+	if digest.RowsExamined >= 0 {
+		fmt.Fprintf(&b, ", %s examined", pluralize(int(digest.RowsExamined), "row"))
+	}
+	fmt.Fprintf(&b, ", %s sent\n\n%s\n", pluralize(int(digest.RowsSent), "row"), db.PrettyPrintSQL(digest.Text, db.FormatOptions{}))
+	if digest.Sample != "" {
+		b.WriteString("\nsample:\n" + db.PrettyPrintSQL(digest.Sample, db.FormatOptions{}) + "\n")
+	}
+	return b.String()
+}
+
+// explainDigest shows the plan of the sample of a statement. Without a sample the normalised statement is
+// explained, asking for the values of its placeholders first
+func (csm *ContextualStateManager) explainDigest(ctx context.Context, digest db.StatementDigest) {
+	statement := digest.Sample
+	if statement == "" {
+		statement = digest.Text
+	}
+	csm.explain(ctx, statement, false)
+}
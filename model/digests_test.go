@@ -0,0 +1,53 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventTopCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "top"})
+
+	top := stateManager.GetCurrentState()
+	assert.Equal(t, TopStatements, top.TableMode)
+	assert.Equal(t, "2 statements by total time, Enter shows the statement, x explains a sample", top.StatusText)
+	assert.Equal(t, map[string]string{
+		"QUERY": "SELECT * FROM `orders` WHERE `user_id` = ?", "CALLS": "5200", "TOTAL": "2.6s", "AVG": "0.5ms",
+		"ROWS EXAMINED": "52000", "ROWS SENT": "52000", "DB": "shop",
+	}, top.TableData[1])
+
+	// Enter shows the whole statement and its sample
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Row: 1})
+	detail := stateManager.GetCurrentState()
+	assert.Equal(t, Detail, detail.Mode)
+	assert.Contains(t, detail.DetailText, "24 calls in 7.49s, 312.0ms on average, 2880000 rows examined, 19200 rows sent")
+	assert.Contains(t, detail.DetailText, "sample:\nSELECT")
+	stateManager.PopState(ctx)
+
+	// x explains the sample
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), Row: 1})
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == Plan }, time.Second, 10*time.Millisecond)
+	plan := stateManager.GetCurrentState()
+	assert.Equal(t, Plan, plan.Mode)
+	assert.Equal(t, "plan of SELECT customer_id, SUM(total) FROM orders GROUP BY custo..., 1 full table scan", plan.StatusText)
+	stateManager.PopState(ctx)
+
+	// without a sample the values of the placeholders are asked for
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), Row: 2})
+	form := stateManager.GetCurrentState()
+	assert.Equal(t, Form, form.Mode)
+	assert.Len(t, form.FormFields, 1)
+}
+
+func TestDescribeDigestWithoutExaminedRows(t *testing.T) {
+	text := describeDigest(db.StatementDigest{Text: "SELECT 1", Calls: 1, RowsExamined: -1, RowsSent: 1})
+	assert.Equal(t, "1 call in 0.0ms, 0.0ms on average, 1 row sent\n\nSELECT 1\n", text)
+}
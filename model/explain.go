@@ -0,0 +1,57 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"rel8/db"
+)
+
+// isAnalyzeKey reports whether the explain key asks for an analyzed plan, Ctrl-F6 which terminals
+// report either as a modifier or as F30
+func isAnalyzeKey(event *tcell.EventKey) bool {
+	return event.Key() == tcell.KeyF30 || event.Modifiers()&tcell.ModCtrl != 0
+}
+
+// explain shows the plan of a statement as a tree, asking for the values of its placeholders first.
+// analyze also runs the statement, in a transaction that is rolled back, to show actual rows and timing
+func (csm *ContextualStateManager) explain(ctx context.Context, statement string, analyze bool) {
+	if strings.TrimSpace(statement) == "" {
+		return
+	}
+	csm.bindAndRun(ctx, statement, func(ctx context.Context, sql string, args []any) {
+		status := "explaining "
+		if analyze {
+			status = "analyzing "
+		}
+		csm.runInBackground(ctx, status+summarizeStatement(sql), func(ctx context.Context) State {
+			return csm.createStateWithPlan(ctx, sql, analyze, args...)
+		})
+	})
+}
+
+// createStateWithPlan reads the plan of a statement, on failure the state tells why
+func (csm *ContextualStateManager) createStateWithPlan(ctx context.Context, sql string, analyze bool, args ...any) State {
+	newState := State{Mode: Plan}
+	plan, err := csm.server.FetchPlan(ctx, sql, analyze, args...)
+	if err != nil {
+		slog.Error("explain failed", "error", err, "statement", sql)
+		newState.StatusText = fmt.Sprintf("explain failed: %v", err)
+		return newState
+	}
+	newState.Plan = &plan
+	newState.StatusText = "plan of " + summarizeStatement(sql)
+	switch {
+	case analyze && csm.server.Dialect() == db.DialectSqlite:
+		newState.StatusText = "SQLite plans can't be analyzed, " + newState.StatusText
+	case analyze:
+		newState.StatusText = "analyzed " + newState.StatusText + ", changes rolled back"
+	}
+	if scans := plan.FullScans(); scans > 0 {
+		newState.StatusText += ", " + pluralize(scans, "full table scan")
+	}
+	return newState
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventExplain(t *testing.T) {
+	script := "SELECT 1;\nSELECT * FROM users u JOIN orders o ON o.user_id = u.id;"
+
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Editor})
+
+	stateManager.HandleEvent(&Event{
+		Event:  tcell.NewEventKey(tcell.KeyF6, 0, tcell.ModNone),
+		Text:   script,
+		Cursor: len(script),
+	})
+
+	// the plan is read in the background
+	assert.Equal(t, "explaining SELECT * FROM users u JOIN orders o ON o.user_id = u.id", stateManager.GetCurrentState().StatusText)
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == Plan }, time.Second, 10*time.Millisecond)
+	plan := stateManager.GetCurrentState()
+	assert.Equal(t, Plan, plan.Mode)
+	assert.Equal(t, "plan of SELECT * FROM users u JOIN orders o ON o.user_id = u.id, 1 full table scan", plan.StatusText)
+	assert.Equal(t, "query block #1", plan.Plan.Operation)
+
+	// keys go to the tree
+	key := tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+	assert.Equal(t, key, stateManager.HandleEvent(&Event{Event: key}))
+
+	// the SQL prompt explains its text after asking for placeholders
+	stateManager.PushState(ctx, State{Mode: SQL})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyF30, 0, tcell.ModNone), Text: "SELECT * FROM users WHERE id = ?"})
+	assert.Equal(t, Form, stateManager.GetCurrentState().Mode)
+	stateManager.HandleEvent(&Event{Event: key, Values: []string{"1"}})
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == Plan }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "analyzed plan of SELECT * FROM users WHERE id = ?, changes rolled back, 1 full table scan",
+		stateManager.GetCurrentState().StatusText)
+
+	// nothing to explain
+	stateManager.PushState(ctx, State{Mode: SQL})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyF6, 0, tcell.ModNone), Text: " "})
+	assert.Equal(t, SQL, stateManager.GetCurrentState().Mode)
+}
+
+func TestIsAnalyzeKey(t *testing.T) {
+	assert.False(t, isAnalyzeKey(tcell.NewEventKey(tcell.KeyF6, 0, tcell.ModNone)))
+	assert.True(t, isAnalyzeKey(tcell.NewEventKey(tcell.KeyF6, 0, tcell.ModCtrl)))
+	assert.True(t, isAnalyzeKey(tcell.NewEventKey(tcell.KeyF30, 0, tcell.ModNone)))
+}
+
+// slowPlanServer reads plans once released
+type slowPlanServer struct {
+	db.MysqlMock
+	release chan struct{}
+}
+
+func (s *slowPlanServer) FetchPlan(ctx context.Context, statement string, analyze bool, args ...any) (db.PlanNode, error) {
+	<-s.release
+	if err := ctx.Err(); err != nil {
+		return db.PlanNode{}, err
+	}
+	return s.MysqlMock.FetchPlan(ctx, statement, analyze, args...)
+}
+
+func TestHandleEventExplainInBackground(t *testing.T) {
+	server := &slowPlanServer{release: make(chan struct{})}
+	stateManager := NewContextualStateManager(server, *Initial, 10)
+	stateManager.PushState(context.Background(), State{Mode: SQL})
+
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyF30, 0, tcell.ModNone), Text: "DELETE FROM users"})
+
+	// the analyzed statement outlasts the event, whose context is done once it is handled
+	assert.Equal(t, "analyzing DELETE FROM users", stateManager.GetCurrentState().StatusText)
+	close(server.release)
+
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == Plan }, time.Second, 10*time.Millisecond)
+	assert.Contains(t, stateManager.GetCurrentState().StatusText, "analyzed plan of DELETE FROM users, changes rolled back")
+}
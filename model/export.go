@@ -0,0 +1,114 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"rel8/export"
+)
+
+// exportTimeout bounds streaming of a full result to a file
+const exportTimeout = 10 * time.Minute
+
+// exportProgressRows is how many streamed rows pass between progress reports
+const exportProgressRows = 10000
+
+// exportJob is a full query streamed to a file in the background by the state it was started from
+type exportJob struct {
+	path string
+}
+
+const exportUsage = "usage: export <format> <path> [--all] [--table=name] [--dialect=mysql|postgres|sqlite] [--mode=insert|replace|ignore|upsert] [--key=col,...] [--batch=n]"
+
+// exportState writes table data of the state to a file and returns a status text.
+// Arguments are: format path [options], where --all streams the full query instead of loaded rows
+// in the background, reporting progress on the state, and the remaining options configure the sql format
+func (csm *ContextualStateManager) exportState(state *State, args []string) string {
+	if len(args) < 2 {
+		return exportUsage
+	}
+	format, path := args[0], args[1]
+	streamAll := false
+	options := export.Options{Table: state.SourceTable, Dialect: csm.server.Dialect()}
+
+	for _, arg := range args[2:] {
+		name, value, _ := strings.Cut(arg, "=")
+		switch name {
+		case "--all":
+			streamAll = true
+		case "--table":
+			options.Table = value
+		case "--dialect":
+			options.Dialect = value
+		case "--mode":
+			options.Mode = value
+		case "--key":
+			options.Key = strings.Split(value, ",")
+		case "--batch":
+			batchSize, err := strconv.Atoi(value)
+			if err != nil {
+				return exportUsage
+			}
+			options.BatchSize = batchSize
+		default:
+			return exportUsage
+		}
+	}
+
+	if state.Mode != Browse || len(state.TableHeaders) == 0 {
+		return "nothing to export"
+	}
+	if streamAll && state.Query == "" {
+		return "no underlying query to stream, export without --all"
+	}
+
+	if !streamAll {
+		err := export.ToFile(path, format, options, func(w export.Writer) error {
+			return export.WriteTableData(w, state.TableHeaders, state.TableData)
+		})
+		return exportResult(format, path, len(state.TableData), err)
+	}
+
+	job := &exportJob{path: path}
+	state.exportJob = job
+	query, queryArgs := state.Query, state.QueryArgs
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+
+		rowCount := 0
+		err := export.ToFile(path, format, options, func(w export.Writer) error {
+			return csm.server.StreamSqlRows(ctx, query, func(columns []string, types []string) error {
+				return export.WriteTypedHeader(w, columns, types)
+			}, func(values []*string) error {
+				rowCount++
+				if rowCount%exportProgressRows == 0 {
+					csm.setStatusWhere(job.owns, fmt.Sprintf("exporting %d rows to %s", rowCount, path))
+				}
+				return w.WriteRow(values)
+			}, queryArgs...)
+		})
+		csm.setStatusWhere(job.owns, exportResult(format, path, rowCount, err))
+	}()
+	return "exporting to " + path
+}
+
+// owns reports whether a state started the export
+func (job *exportJob) owns(state State) bool {
+	return state.exportJob == job
+}
+
+// exportResult logs the outcome of an export and describes it as a status text
+func exportResult(format string, path string, rowCount int, err error) string {
+	if err != nil {
+		slog.Error("export failed", "error", err, "format", format, "path", path)
+		return fmt.Sprintf("export failed: %v", err)
+	}
+
+	slog.Info("export complete", "format", format, "path", path, "rows", rowCount)
+	return fmt.Sprintf("exported %d rows to %s", rowCount, path)
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventExportCommand(t *testing.T) {
+	dir := t.TempDir()
+	server := &db.MysqlMock{}
+
+	tests := []struct {
+		name           string
+		command        string
+		expectedStatus string
+		expectedLines  int
+	}{
+		{
+			name:           "export loaded rows",
+			command:        "export csv " + filepath.Join(dir, "loaded.csv"),
+			expectedStatus: "exported 10 rows to " + filepath.Join(dir, "loaded.csv"),
+			expectedLines:  11,
+		},
+		{
+			// streamed in the background, the result follows on the grid
+			name:           "stream full query",
+			command:        "export jsonl " + filepath.Join(dir, "all.jsonl") + " --all",
+			expectedStatus: "exported 2000 rows to " + filepath.Join(dir, "all.jsonl"),
+			expectedLines:  2000,
+		},
+		{
+			name:           "missing path",
+			command:        "export csv",
+			expectedStatus: exportUsage,
+		},
+		{
+			name:           "unknown format",
+			command:        "export xml " + filepath.Join(dir, "out.xml"),
+			expectedStatus: "export failed: unknown export format \"xml\", expected one of csv, tsv, json, jsonl, markdown, html, sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateManager := NewContextualStateManager(server, *Initial, 10)
+			ctx := context.Background()
+			stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT * FROM users"))
+			stateManager.PushState(ctx, State{Mode: Command})
+
+			stateManager.HandleEvent(&Event{
+				Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone),
+				Text:  tt.command,
+			})
+
+			assert.Eventually(t, func() bool {
+				return stateManager.GetCurrentState().StatusText == tt.expectedStatus
+			}, time.Second, 10*time.Millisecond)
+			assert.Equal(t, Browse, stateManager.GetCurrentState().Mode)
+			assert.Len(t, stateManager.GetHistory(), 2)
+
+			if tt.expectedLines > 0 {
+				path := strings.Fields(tt.command)[2]
+				content, err := os.ReadFile(path)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedLines, strings.Count(string(content), "\n"))
+			}
+		})
+	}
+}
+
+func TestExportStateWithoutQuery(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	state := State{Mode: Browse, TableMode: DatabaseTable, TableHeaders: []string{"NAME"}}
+
+	status := stateManager.exportState(&state, []string{"csv", filepath.Join(t.TempDir(), "t.csv"), "--all"})
+	assert.Equal(t, "no underlying query to stream, export without --all", status)
+
+	status = stateManager.exportState(&State{Mode: Browse}, []string{"csv", "t.csv"})
+	assert.Equal(t, "nothing to export", status)
+}
+
+func TestExportStateSQL(t *testing.T) {
+	tablesState := State{
+		Mode:      Browse,
+		TableMode: DatabaseTable,
+		TableData: []db.TableData{db.MysqlTable{Name: "users"}},
+	}
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, tablesState, 10)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "fixtures.sql")
+
+	// the source table is taken from table rows state
+	state := stateManager.createStateWithTableRows(ctx, &Event{Row: 1})
+	state.TableData = state.TableData[:3]
+	assert.Equal(t, "users", state.SourceTable)
+
+	status := stateManager.exportState(&state, []string{"sql", path, "--dialect=sqlite", "--mode=ignore", "--batch=2"})
+	assert.Equal(t, "exported 3 rows to "+path, status)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "INSERT OR IGNORE INTO \"users\""))
+
+	// arbitrary SQL results need an explicit table
+	state.SourceTable = ""
+	status = stateManager.exportState(&state, []string{"sql", path})
+	assert.Equal(t, "export failed: sql export needs a table name, use --table=name", status)
+
+	status = stateManager.exportState(&state, []string{"sql", path, "--batch=many"})
+	assert.Equal(t, exportUsage, status)
+}
+
+func TestExportStatusDoesNotCarryOver(t *testing.T) {
+	tablesState := State{
+		Mode:       Browse,
+		TableMode:  DatabaseTable,
+		TableData:  []db.TableData{db.MysqlTable{Name: "users"}},
+		StatusText: "exported 1 rows to tables.csv",
+	}
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, tablesState, 10)
+	ctx := context.Background()
+
+	assert.Empty(t, stateManager.createStateWithTableRows(ctx, &Event{Row: 1}).StatusText)
+	assert.Empty(t, stateManager.createStateWithTableDescr(ctx, &Event{Row: 1}).StatusText)
+	assert.Empty(t, stateManager.createStateWithObjectDefinition(ctx, &Event{Row: 1}, db.ObjectView).StatusText)
+}
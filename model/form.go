@@ -0,0 +1,20 @@
+package model
+
+import (
+	"context"
+)
+
+// submitForm leaves the form and runs its action with the entered values,
+// missing values are empty
+func (csm *ContextualStateManager) submitForm(ctx context.Context, values []string) {
+	form := csm.GetCurrentState()
+	if form.submit == nil {
+		return
+	}
+	for len(values) < len(form.FormFields) {
+		values = append(values, "")
+	}
+
+	csm.PopState(ctx)
+	form.submit(ctx, values)
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"rel8/db"
+	"rel8/importer"
+)
+
+// importTimeout bounds loading of a whole file
+const importTimeout = 30 * time.Minute
+
+const importUsage = "usage: import <path> <table> [--create] [--batch=n]"
+
+// importJob is an import previewed by a state, it runs once
+type importJob struct {
+	request db.ImportRequest
+	// rows found in the file by the preview
+	total   int
+	started atomic.Bool
+}
+
+// createStateWithImportPreview reads a file row by row and creates a state previewing its column mapping.
+// Arguments are: path table [options], where --create creates the table from inferred types.
+// On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithImportPreview(args []string) (State, string) {
+	if len(args) < 2 {
+		return State{}, importUsage
+	}
+	path, table := args[0], args[1]
+	create := false
+	batchSize := 0
+
+	for _, arg := range args[2:] {
+		name, value, _ := strings.Cut(arg, "=")
+		switch name {
+		case "--create":
+			create = true
+		case "--batch":
+			var err error
+			if batchSize, err = strconv.Atoi(value); err != nil {
+				return State{}, importUsage
+			}
+		default:
+			return State{}, importUsage
+		}
+	}
+
+	dialect := csm.server.Dialect()
+	file, columns, err := importer.InferColumns(path, dialect)
+	if err != nil {
+		slog.Error("import failed", "error", err, "path", path)
+		return State{}, fmt.Sprintf("import failed: %v", err)
+	}
+
+	request := db.ImportRequest{
+		Table:     table,
+		Columns:   file.Columns,
+		Rows:      file.Rows,
+		Delimiter: file.Delimiter,
+		LineEnd:   file.LineEnd,
+		BatchSize: batchSize,
+	}
+	if !file.EmptyStrings {
+		// LOAD DATA would turn empty strings into NULLs
+		request.Path = path
+	}
+	action := "import"
+	if create {
+		request.CreateSQL = importer.CreateTableSQL(dialect, table, columns)
+		action = "create table and import"
+	}
+
+	var tableData []db.TableData
+	for _, column := range columns {
+		tableData = append(tableData, map[string]string{
+			"COLUMN": column.Name,
+			"TYPE":   column.Type,
+			"NULLS":  strconv.Itoa(column.Nulls),
+			"SAMPLE": column.Sample,
+		})
+	}
+
+	newState := newBrowseState(ImportPreview, []string{"COLUMN", "TYPE", "NULLS", "SAMPLE"}, tableData)
+	newState.StatusText = fmt.Sprintf("%d rows from %s into %s, enter to %s", file.RowCount, path, table, action)
+	newState.importJob = &importJob{request: request, total: file.RowCount}
+	return newState, ""
+}
+
+// startImport loads the previewed file in the background, reporting progress in the header and the result on
+// the preview
+func (csm *ContextualStateManager) startImport(ctx context.Context) {
+	newState := csm.GetCurrentState()
+	job := newState.importJob
+	if job == nil || !job.started.CompareAndSwap(false, true) {
+		return
+	}
+
+	table, total := job.request.Table, job.total
+	newState.StatusText = fmt.Sprintf("importing %d rows into %s", total, table)
+	csm.ReplaceState(ctx, newState)
+	csm.reportProgress(fmt.Sprintf("importing 0/%d rows into %s", total, table))
+
+	go func() {
+		importCtx, cancel := context.WithTimeout(context.Background(), importTimeout)
+		defer cancel()
+		defer csm.reportProgress("")
+
+		imported, err := csm.server.ImportRows(importCtx, job.request, func(done int) {
+			csm.reportProgress(fmt.Sprintf("importing %d/%d rows into %s", done, total, table))
+		})
+		if err != nil {
+			slog.Error("import failed", "error", err, "table", table)
+			csm.setImportStatus(job, fmt.Sprintf("import failed: %v", err))
+			return
+		}
+
+		slog.Info("import complete", "table", table, "rows", imported)
+		csm.setImportStatus(job, fmt.Sprintf("imported %d rows into %s", imported, table))
+	}()
+}
+
+// setImportStatus shows the result of an import on the state previewing the job
+func (csm *ContextualStateManager) setImportStatus(job *importJob, text string) {
+	csm.setStatusWhere(func(state State) bool { return state.importJob == job }, text)
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventImportCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("id,name\n1,John\n2,\n"), 0o644))
+
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	var progress []string
+	var progressMu sync.Mutex
+	stateManager.SetProgressListener(func(text string) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		progress = append(progress, text)
+	})
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Command})
+
+	stateManager.HandleEvent(&Event{
+		Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone),
+		Text:  "import " + path + " people --create",
+	})
+
+	preview := stateManager.GetCurrentState()
+	assert.Equal(t, Browse, preview.Mode)
+	assert.Equal(t, ImportPreview, preview.TableMode)
+	assert.Equal(t, []string{"COLUMN", "TYPE", "NULLS", "SAMPLE"}, preview.TableHeaders)
+	assert.Equal(t, []db.TableData{
+		map[string]string{"COLUMN": "id", "TYPE": "BIGINT", "NULLS": "0", "SAMPLE": "1"},
+		map[string]string{"COLUMN": "name", "TYPE": "VARCHAR(255)", "NULLS": "1", "SAMPLE": "John"},
+	}, preview.TableData)
+	assert.Equal(t, "2 rows from "+path+" into people, enter to create table and import", preview.StatusText)
+	assert.Contains(t, preview.importJob.request.CreateSQL, "CREATE TABLE `people`")
+	assert.Equal(t, path, preview.importJob.request.Path)
+
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Row: 1})
+	assert.Eventually(t, func() bool {
+		return stateManager.GetCurrentState().StatusText == "imported 2 rows into people"
+	}, time.Second, 10*time.Millisecond)
+
+	// progress goes to the header, which is cleared once the import ended
+	assert.Eventually(t, func() bool {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		return len(progress) > 0 && progress[len(progress)-1] == ""
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "importing 0/2 rows into people", progress[0])
+
+	// a second enter does not import again
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Row: 1})
+	assert.Equal(t, "imported 2 rows into people", stateManager.GetCurrentState().StatusText)
+}
+
+func TestImportPreviewEmptyStrings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("id,name\n1,\"\"\n2,\n"), 0o644))
+
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	preview, status := stateManager.createStateWithImportPreview([]string{path, "people"})
+	assert.Empty(t, status)
+	// the quoted empty name is a value, only the unquoted one is NULL
+	assert.Equal(t, map[string]string{"COLUMN": "name", "TYPE": "VARCHAR(255)", "NULLS": "1", "SAMPLE": ""}, preview.TableData[1])
+	// LOAD DATA would load the empty string as NULL, so rows are inserted
+	assert.Empty(t, preview.importJob.request.Path)
+}
+
+func TestHandleEventImportCommandErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		command        string
+		expectedStatus string
+	}{
+		{name: "missing table", command: "import people.csv", expectedStatus: importUsage},
+		{name: "unknown option", command: "import people.csv people --drop", expectedStatus: importUsage},
+		{name: "missing file", command: "import /nonexistent/people.csv people", expectedStatus: "import failed: open /nonexistent/people.csv: no such file or directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+			stateManager.PushState(context.Background(), State{Mode: Command})
+
+			stateManager.HandleEvent(&Event{
+				Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone),
+				Text:  tt.command,
+			})
+
+			currentState := stateManager.GetCurrentState()
+			assert.Equal(t, Browse, currentState.Mode)
+			assert.Equal(t, tt.expectedStatus, currentState.StatusText)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"rel8/db"
+)
+
+// createStateWithLocks lists the sessions blocking others as a tree, each followed by the sessions waiting
+// for its locks, indented under it. On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithLocks(ctx context.Context) (State, string) {
+	waits, err := csm.server.FetchLockWaits(ctx)
+	if err != nil {
+		slog.Error("reading lock waits failed", "error", err)
+		return State{}, fmt.Sprintf("reading locks failed: %v", err)
+	}
+
+	headers := []string{"SESSION", "USER", "BLOCKS", "WAIT", "LOCK", "MODE", "QUERY"}
+	newState := newBrowseState(Locks, headers, []db.TableData{})
+	if len(waits) == 0 {
+		newState.StatusText = "no session waits for a lock"
+		return newState, ""
+	}
+
+	byBlocker := map[string][]db.LockWait{}
+	waiting := map[string]bool{}
+	var blockers []string
+	for _, wait := range waits {
+		if _, seen := byBlocker[wait.BlockingID]; !seen {
+			blockers = append(blockers, wait.BlockingID)
+		}
+		byBlocker[wait.BlockingID] = append(byBlocker[wait.BlockingID], wait)
+		waiting[wait.WaitingID] = true
+	}
+
+	// chains start at blockers that wait for nothing, then at any blocker left over, as sessions waiting for
+	// each other are reached from no such blocker
+	var roots []string
+	for _, blocker := range blockers {
+		if !waiting[blocker] {
+			roots = append(roots, blocker)
+		}
+	}
+	roots = append(roots, blockers...)
+
+	blocks := func(id string) string {
+		if count := len(byBlocker[id]); count > 0 {
+			return strconv.Itoa(count)
+		}
+		return ""
+	}
+	shown := map[string]bool{}
+	var walk func(blocker string, depth int)
+	walk = func(blocker string, depth int) {
+		for _, wait := range byBlocker[blocker] {
+			newState.TableData = append(newState.TableData, map[string]string{
+				"SESSION": strings.Repeat("  ", depth-1) + "└ " + wait.WaitingID, "USER": wait.WaitingUser,
+				"BLOCKS": blocks(wait.WaitingID), "WAIT": wait.Seconds + "s", "LOCK": wait.Object, "MODE": wait.Mode,
+				"QUERY": wait.WaitingQuery,
+			})
+			newState.lockBlockers = append(newState.lockBlockers, blocker)
+			if !shown[wait.WaitingID] {
+				shown[wait.WaitingID] = true
+				walk(wait.WaitingID, depth+1)
+			}
+		}
+	}
+	for _, root := range roots {
+		if shown[root] {
+			continue
+		}
+		shown[root] = true
+		first := byBlocker[root][0]
+		newState.TableData = append(newState.TableData, map[string]string{
+			"SESSION": root, "USER": first.BlockingUser, "BLOCKS": blocks(root), "QUERY": first.BlockingQuery,
+		})
+		newState.lockBlockers = append(newState.lockBlockers, root)
+		walk(root, 1)
+	}
+
+	newState.StatusText = fmt.Sprintf("%d sessions wait for locks, Enter shows the blocker in the process list", len(waiting))
+	return newState, ""
+}
+
+// showBlocker opens the process list on the session blocking the one of a grid row, a blocker on itself
+func (csm *ContextualStateManager) showBlocker(ctx context.Context, row int) {
+	blockers := csm.GetCurrentState().lockBlockers
+	if row < 1 || row > len(blockers) {
+		return
+	}
+	csm.updateCurrentStateSelection(row - 1)
+	csm.showProcesses(ctx, blockers[row-1])
+}
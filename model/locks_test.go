@@ -0,0 +1,100 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+// lockServer reports fixed lock waits
+type lockServer struct {
+	db.MysqlMock
+	waits []db.LockWait
+	err   error
+}
+
+func (s *lockServer) FetchLockWaits(ctx context.Context) ([]db.LockWait, error) {
+	return s.waits, s.err
+}
+
+func TestHandleEventLocksCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "locks"})
+
+	locks := stateManager.GetCurrentState()
+	assert.Equal(t, Locks, locks.TableMode)
+	assert.Equal(t, []string{"SESSION", "USER", "BLOCKS", "WAIT", "LOCK", "MODE", "QUERY"}, locks.TableHeaders)
+	// 9 blocks 7 which blocks 42
+	assert.Equal(t, []db.TableData{
+		map[string]string{"SESSION": "9", "USER": "batch", "BLOCKS": "1", "QUERY": "NULL"},
+		map[string]string{"SESSION": "└ 7", "USER": "app", "BLOCKS": "1", "WAIT": "30s", "LOCK": "shop.orders PRIMARY",
+			"MODE": "RECORD X,REC_NOT_GAP", "QUERY": "UPDATE orders SET total = 0 WHERE id = 1"},
+		map[string]string{"SESSION": "  └ 42", "USER": "report", "BLOCKS": "", "WAIT": "12s", "LOCK": "shop.orders PRIMARY",
+			"MODE": "RECORD S,REC_NOT_GAP", "QUERY": "SELECT * FROM orders WHERE id = 1 FOR SHARE"},
+	}, locks.TableData)
+	assert.Equal(t, "2 sessions wait for locks, Enter shows the blocker in the process list", locks.StatusText)
+
+	// Enter on a waiting session selects its blocker in the process list
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Row: 3})
+	processes := stateManager.GetCurrentState()
+	assert.Equal(t, Processes, processes.TableMode)
+	assert.Equal(t, 1, processes.SelectedDataIndex)
+	assert.Equal(t, "session 7, d shows the query, Ctrl-K kills", processes.StatusText)
+	stateManager.stopLiveWatch()
+	stateManager.PopState(ctx)
+
+	// the blocker of the chain is no longer connected
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Row: 1})
+	assert.Equal(t, "session 9 has ended, 2 sessions, d shows the query, Ctrl-K kills", stateManager.GetCurrentState().StatusText)
+	stateManager.stopLiveWatch()
+}
+
+func TestCreateStateWithLocks(t *testing.T) {
+	server := &lockServer{}
+	stateManager := NewContextualStateManager(server, *Initial, 10)
+	ctx := context.Background()
+
+	_, status := stateManager.createStateWithLocks(ctx)
+	assert.Empty(t, status)
+	empty, _ := stateManager.createStateWithLocks(ctx)
+	assert.Equal(t, "no session waits for a lock", empty.StatusText)
+
+	server.err = errors.New("access denied")
+	_, status = stateManager.createStateWithLocks(ctx)
+	assert.Equal(t, "reading locks failed: access denied", status)
+
+	// sessions waiting for each other are shown once
+	server.err = nil
+	server.waits = []db.LockWait{
+		{WaitingID: "1", BlockingID: "2", Seconds: "3"},
+		{WaitingID: "2", BlockingID: "1", Seconds: "4"},
+	}
+	deadlock, _ := stateManager.createStateWithLocks(ctx)
+	var sessions []string
+	for _, row := range deadlock.TableData {
+		sessions = append(sessions, row.(map[string]string)["SESSION"])
+	}
+	assert.Equal(t, []string{"2", "└ 1", "  └ 2"}, sessions)
+	assert.Equal(t, []string{"2", "2", "1"}, deadlock.lockBlockers)
+
+	// sessions waiting for each other are shown next to a chain
+	server.waits = []db.LockWait{
+		{WaitingID: "7", BlockingID: "9", Seconds: "5"},
+		{WaitingID: "X", BlockingID: "Y", Seconds: "3"},
+		{WaitingID: "Y", BlockingID: "X", Seconds: "4"},
+	}
+	both, _ := stateManager.createStateWithLocks(ctx)
+	sessions = nil
+	for _, row := range both.TableData {
+		sessions = append(sessions, row.(map[string]string)["SESSION"])
+	}
+	assert.Equal(t, []string{"9", "└ 7", "Y", "└ X", "  └ Y"}, sessions)
+	assert.Equal(t, []string{"9", "9", "Y", "Y", "X"}, both.lockBlockers)
+	assert.Equal(t, "3 sessions wait for locks, Enter shows the blocker in the process list", both.StatusText)
+}
@@ -1,8 +1,12 @@
 package model
 
 import (
+	"context"
+	"time"
+
 	"github.com/gdamore/tcell/v2"
 	"rel8/db"
+	"rel8/queries"
 )
 
 type State struct {
@@ -12,11 +16,65 @@ type State struct {
 	TableHeaders      []string
 	TableData         []db.TableData
 	SelectedDataIndex int
+	// query producing table data, without row limit, and the values bound to its placeholders
+	Query     string
+	QueryArgs []any
+	// table the rows were read from, empty for arbitrary SQL
+	SourceTable string
 
 	// in details mode
 	DetailText string
 
+	// in tabbed mode
+	Tabs        []Tab
+	SelectedTab int
+
 	CommandText string
+
+	// short feedback of the last action, such as an export result
+	StatusText string
+
+	// in editor mode, text to load into the editor, such as a saved query
+	EditorText string
+
+	// in plan mode, the query plan shown as a tree
+	Plan *db.PlanNode
+
+	// in table mode, the query results to switch between, set when there is more than one
+	ResultTabs     []Tab
+	SelectedResult int
+	// another result shown beside this one
+	SplitResult *Tab
+	// identifies the result of a query, 0 for other states
+	resultID int
+	// how often the query is re-run while the result is watched, 0 when not watched
+	WatchInterval time.Duration
+	// in a result diff, how each row of TableData differs
+	Diff []RowDiff
+
+	// in form mode, values asked for before submit runs with them
+	FormFields []FormField
+	submit     func(ctx context.Context, values []string)
+
+	// import previewed by the state, started with Enter
+	importJob *importJob
+	// full query export started from the state, which shows its progress
+	exportJob *exportJob
+	// schema comparison started from the state, which shows why it failed
+	schemaDiffJob *schemaDiffJob
+	// saved queries listed by the state, in the order of TableData
+	savedQueries []queries.Query
+	// statements making another schema match the one of the connection
+	alterScript string
+	// in the lock tree, the session blocking the one of each row, or the row's own session for the head of a chain
+	lockBlockers []string
+	// in the status grid, the filter and mode of the list and the status it was read from
+	status *statusView
+	// column the rows were sorted by with o, and whether largest first
+	sortColumn     int
+	sortDescending bool
+	// statements listed by :top, in the order of TableData
+	digests []db.StatementDigest
 }
 
 var Quit = &State{Mode: QuitMode} // Use special mode to identify quit state
@@ -30,9 +88,20 @@ const (
 	SQL
 	Detail
 	Editor
+	Tabbed
+	Form
+	Plan
 	QuitMode Mode = -1
 )
 
+// Tab is a single page of a tabbed state, either a table or a text when TableHeaders is nil
+type Tab struct {
+	Title        string
+	TableHeaders []string
+	TableData    []db.TableData
+	DetailText   string
+}
+
 type TableMode int
 
 const (
@@ -40,10 +109,52 @@ const (
 	DatabaseTable
 	Database
 	TableRow
+	DatabaseView
+	DatabaseProcedure
+	DatabaseFunction
+	DatabaseTrigger
+	DatabaseEvent
+	DatabaseSequence
+	ImportPreview
+	SavedQueries
+	ResultDiff
+	SchemaDiff
+	Processes
+	Locks
+	ServerStatus
+	ServerVariables
+	TableSizes
+	TopStatements
+	Users
+	TableAccess
+	Replication
 )
 
+// objectKinds maps table modes listing schema objects to their kind for definition lookup
+var objectKinds = map[TableMode]string{
+	DatabaseView:      db.ObjectView,
+	DatabaseProcedure: db.ObjectProcedure,
+	DatabaseFunction:  db.ObjectFunction,
+	DatabaseTrigger:   db.ObjectTrigger,
+	DatabaseEvent:     db.ObjectEvent,
+	DatabaseSequence:  db.ObjectSequence,
+}
+
 type Event struct {
-	Event *tcell.EventKey
-	Text  string
-	Row   int
+	Event  *tcell.EventKey
+	Text   string
+	Row    int
+	Column int
+	// byte offset of the cursor in Text, in editor mode
+	Cursor int
+	// field values, in form mode
+	Values []string
+}
+
+// FormField is a value asked for by a form
+type FormField struct {
+	Label string
+	// Hint describes the expected value, such as its type
+	Hint  string
+	Value string
 }
@@ -3,6 +3,7 @@ package model
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gdamore/tcell/v2"
@@ -349,8 +350,9 @@ func TestHandleEventSQLMode(t *testing.T) {
 
 			result := stateManager.HandleEvent(event)
 
+			// the query runs in the background
+			assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == tt.expectedMode }, time.Second, 10*time.Millisecond)
 			currentState := stateManager.GetCurrentState()
-			assert.Equal(t, tt.expectedMode, currentState.Mode)
 
 			// For escape and enter in SQL mode, result should be nil
 			if tt.key == tcell.KeyEscape || tt.key == tcell.KeyEnter {
@@ -359,7 +361,7 @@ func TestHandleEventSQLMode(t *testing.T) {
 
 			// If SQL query was executed, verify the state was pushed
 			if tt.key == tcell.KeyEnter && tt.text != "" {
-				assert.Equal(t, 1, mockCb.callCount) // Should have pushed a new state
+				assert.Equal(t, 2, mockCb.callCount) // Should have shown the query runs, then pushed a new state
 				assert.Equal(t, Browse, currentState.Mode)
 				assert.Equal(t, TableRow, currentState.TableMode)
 				
@@ -567,6 +569,24 @@ func TestExtractNameFromSelection(t *testing.T) {
 			expectedName:  "",
 			expectError:   true,
 		},
+		{
+			name:          "error with an empty list",
+			state:         State{},
+			selectedIndex: 0,
+			expectedName:  "",
+			expectError:   true,
+		},
+		{
+			name: "error without a selected row",
+			state: State{
+				TableData: []db.TableData{
+					db.MysqlTable{Name: "users", Type: "BASE TABLE", Engine: "InnoDB", Rows: "100", Size: "1MB"},
+				},
+			},
+			selectedIndex: -1,
+			expectedName:  "",
+			expectError:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -604,7 +624,7 @@ func TestHandleEventTableDescription(t *testing.T) {
 	mockCb := &mockCallback{}
 	stateManager.AddSyncCallback(mockCb.callback)
 
-	// Mock the SHOW CREATE TABLE query for 'd' rune
+	// Mock the structured schema queries and SHOW CREATE TABLE query for 'd' rune
 	createTableSQL := `CREATE TABLE test_table (
   id INT PRIMARY KEY
 )`
@@ -613,6 +633,23 @@ func TestHandleEventTableDescription(t *testing.T) {
 	mock.ExpectQuery("SHOW CREATE TABLE").
 		WithArgs().
 		WillReturnRows(rows)
+	mock.ExpectQuery("FROM information_schema.COLUMNS").
+		WithArgs("test_table").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d", "e", "f", "g"}).
+			AddRow("id", "int", "NO", nil, "PRI", "", ""))
+	mock.ExpectQuery("FROM information_schema.STATISTICS").
+		WithArgs("test_table").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d", "e"}).
+			AddRow("PRIMARY", "id", "YES", 1, "BTREE"))
+	mock.ExpectQuery("FROM information_schema.KEY_COLUMN_USAGE").
+		WithArgs("test_table").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d", "e", "f"}))
+	mock.ExpectQuery("FROM information_schema.TABLE_CONSTRAINTS").
+		WithArgs("test_table").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c"}))
+	mock.ExpectQuery("FROM information_schema.TRIGGERS").
+		WithArgs("test_table").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d"}))
 
 	event := &Event{
 		Event: tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
@@ -621,14 +658,32 @@ func TestHandleEventTableDescription(t *testing.T) {
 
 	result := stateManager.HandleEvent(event)
 
-	// Should have pushed a new Detail state
+	// Should have pushed a new tabbed describe state
 	currentState := stateManager.GetCurrentState()
-	assert.Equal(t, Detail, currentState.Mode)
+	assert.Equal(t, Tabbed, currentState.Mode)
 	assert.Equal(t, createTableSQL, currentState.DetailText)
 	assert.Equal(t, 1, mockCb.callCount)
 	assert.Nil(t, result)
 
+	var titles []string
+	for _, tab := range currentState.Tabs {
+		titles = append(titles, tab.Title)
+	}
+	assert.Equal(t, []string{"Columns", "Indexes", "Foreign Keys", "Checks", "Triggers", "DDL"}, titles)
+	assert.Len(t, currentState.Tabs[0].TableData, 1)
+	assert.Nil(t, currentState.Tabs[5].TableHeaders)
+	assert.Equal(t, createTableSQL, currentState.Tabs[5].DetailText)
+
 	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Tab and Shift-Tab cycle through tabs in place
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone)})
+	assert.Equal(t, 1, stateManager.GetCurrentState().SelectedTab)
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone)})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone)})
+	assert.Equal(t, 5, stateManager.GetCurrentState().SelectedTab)
+	assert.Len(t, stateManager.GetHistory(), 2)
+	assert.Equal(t, 4, mockCb.callCount)
 }
 
 func TestHandleEventEscapeKey(t *testing.T) {
@@ -692,3 +747,62 @@ func TestUpdateCurrentStateSelection(t *testing.T) {
 	currentState := stateManager.GetCurrentState()
 	assert.Equal(t, 5, currentState.SelectedDataIndex)
 }
+
+func TestHandleEventObjectCommands(t *testing.T) {
+	server := &db.MysqlMock{}
+
+	tests := []struct {
+		command       string
+		expectedTable TableMode
+	}{
+		{"views", DatabaseView},
+		{"procs", DatabaseProcedure},
+		{"functions", DatabaseFunction},
+		{"triggers", DatabaseTrigger},
+		{"events", DatabaseEvent},
+		{"sequences", DatabaseSequence},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			stateManager := NewContextualStateManager(server, State{Mode: Command}, 10)
+
+			stateManager.HandleEvent(&Event{
+				Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone),
+				Text:  tt.command,
+			})
+
+			currentState := stateManager.GetCurrentState()
+			assert.Equal(t, Browse, currentState.Mode)
+			assert.Equal(t, tt.expectedTable, currentState.TableMode)
+			assert.NotEmpty(t, currentState.TableData)
+
+			// describe shows the object definition
+			stateManager.HandleEvent(&Event{
+				Event: tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				Row:   1,
+			})
+
+			currentState = stateManager.GetCurrentState()
+			assert.Equal(t, Detail, currentState.Mode)
+			assert.Contains(t, currentState.DetailText, "CREATE")
+		})
+	}
+}
+
+func TestObjectDefinitionFormatting(t *testing.T) {
+	server := &db.MysqlMock{}
+
+	// a view definition on one line is formatted
+	headers, data := server.FetchViews(t.Context())
+	stateManager := NewContextualStateManager(server, newBrowseState(DatabaseView, headers, data), 10)
+	state := stateManager.createStateWithObjectDefinition(t.Context(), &Event{Row: 1}, db.ObjectView)
+	assert.Contains(t, state.DetailText, " AS\nselect\n  `users`.`id` AS `id`,\n")
+
+	// a routine keeps the layout it was created with
+	headers, data = server.FetchRoutines(t.Context(), db.ObjectProcedure)
+	stateManager = NewContextualStateManager(server, newBrowseState(DatabaseProcedure, headers, data), 10)
+	state = stateManager.createStateWithObjectDefinition(t.Context(), &Event{Row: 1}, db.ObjectProcedure)
+	name := data[0].(db.MysqlRoutine).Name
+	assert.Equal(t, server.FetchObjectDefinition(t.Context(), db.ObjectProcedure, name), state.DetailText)
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"rel8/db"
+)
+
+// processRefreshInterval is how often the process list is read again while it is shown
+const processRefreshInterval = 2 * time.Second
+
+// showProcesses lists the sessions of the server and keeps the list refreshing, or reports why there is none.
+// The session of selectID is selected when it is listed
+func (csm *ContextualStateManager) showProcesses(ctx context.Context, selectID string) {
+	if csm.server.Dialect() == db.DialectSqlite {
+		current := csm.GetCurrentState()
+		current.StatusText = "sqlite has no sessions to list"
+		csm.ReplaceState(ctx, current)
+		return
+	}
+
+	headers, data := csm.server.FetchProcesses(ctx)
+	newState := newBrowseState(Processes, headers, data)
+	newState.WatchInterval = processRefreshInterval
+	newState.StatusText = fmt.Sprintf("%d sessions, d shows the query, Ctrl-K kills", len(data))
+	if selectID != "" {
+		newState.StatusText = fmt.Sprintf("session %s has ended, %s", selectID, newState.StatusText)
+		for i, item := range data {
+			if process, ok := item.(db.MysqlProcess); ok && process.ID == selectID {
+				newState.SelectedDataIndex = i
+				newState.StatusText = fmt.Sprintf("session %s, d shows the query, Ctrl-K kills", selectID)
+			}
+		}
+	}
+	csm.startLiveWatch(newState, processRefreshInterval)
+	csm.PushState(ctx, newState)
+}
+
+// selectedProcess returns the session of a grid row
+func selectedProcess(state State, row int) (db.MysqlProcess, bool) {
+	if row < 1 || row > len(state.TableData) {
+		return db.MysqlProcess{}, false
+	}
+	process, ok := state.TableData[row-1].(db.MysqlProcess)
+	return process, ok
+}
+
+// describeProcess shows a session with its full query
+func describeProcess(process db.MysqlProcess) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "session %s of %s from %s", process.ID, process.User, process.Host)
+	if process.Db != "" && process.Db != "NULL" {
+		fmt.Fprintf(&b, " on %s", process.Db)
+	}
+	fmt.Fprintf(&b, "\n%s for %ss\n", process.State, process.Time)
+	if process.Query != "" && process.Query != "NULL" {
+		b.WriteString("\n" + db.PrettyPrintSQL(process.Query, db.FormatOptions{}))
+	}
+	return b.String()
+}
+
+// askToKillProcess asks to confirm ending a session before killing it
+func (csm *ContextualStateManager) askToKillProcess(ctx context.Context, process db.MysqlProcess) {
+	csm.PushState(ctx, State{
+		Mode:       Form,
+		StatusText: fmt.Sprintf("kill session %s of %s", process.ID, process.User),
+		FormFields: []FormField{{Label: "Kill", Hint: "y to end the session and its query", Value: "n"}},
+		submit: func(ctx context.Context, values []string) {
+			newState := csm.GetCurrentState()
+			if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[0])), "y") {
+				newState.StatusText = "kept session " + process.ID
+				csm.ReplaceState(ctx, newState)
+				return
+			}
+
+			if err := csm.server.KillSession(ctx, process.ID); err != nil {
+				slog.Error("killing session failed", "id", process.ID, "error", err)
+				newState.StatusText = fmt.Sprintf("kill failed: %v", err)
+			} else {
+				slog.Info("killed session", "id", process.ID, "user", process.User)
+				newState.TableHeaders, newState.TableData = csm.server.FetchProcesses(ctx)
+				newState.StatusText = "killed session " + process.ID
+			}
+			csm.ReplaceState(ctx, newState)
+		},
+	})
+}
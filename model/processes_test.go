@@ -0,0 +1,66 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventProcCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	key := func(key tcell.Key, r rune, row int) {
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(key, r, tcell.ModNone), Row: row})
+	}
+
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "proc"})
+
+	list := stateManager.GetCurrentState()
+	assert.Equal(t, Processes, list.TableMode)
+	assert.Equal(t, []string{"ID", "USER", "HOST", "DB", "STATE", "TIME", "QUERY"}, list.TableHeaders)
+	assert.Len(t, list.TableData, 2)
+	assert.Equal(t, "2 sessions, d shows the query, Ctrl-K kills", list.StatusText)
+	assert.Equal(t, processRefreshInterval, list.WatchInterval)
+	job := stateManager.liveWatch
+	assert.NotNil(t, job)
+
+	// the list refreshes in place
+	assert.True(t, stateManager.refreshWatched(job))
+	assert.Contains(t, stateManager.GetCurrentState().StatusText, "watching every 2s, refreshed ")
+
+	// d describes the session with its full query
+	key(tcell.KeyRune, 'd', 1)
+	detail := stateManager.GetCurrentState()
+	assert.Equal(t, Detail, detail.Mode)
+	assert.Contains(t, detail.DetailText, "session 42 of report from 10.0.0.7:51234 on shop\nexecuting for 312s\n")
+	assert.Contains(t, detail.DetailText, "GROUP BY")
+	stateManager.PopState(ctx)
+
+	// Ctrl-K asks first
+	key(tcell.KeyCtrlK, 0, 2)
+	form := stateManager.GetCurrentState()
+	assert.Equal(t, Form, form.Mode)
+	assert.Equal(t, "kill session 7 of app", form.StatusText)
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Values: []string{"n"}})
+	assert.Equal(t, "kept session 7", stateManager.GetCurrentState().StatusText)
+
+	key(tcell.KeyCtrlK, 0, 2)
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Values: []string{"y"}})
+	killed := stateManager.GetCurrentState()
+	assert.Equal(t, Processes, killed.TableMode)
+	assert.Equal(t, "killed session 7", killed.StatusText)
+
+	// leaving the list stops refreshing it
+	stateManager.PopState(ctx)
+	assert.False(t, stateManager.refreshWatched(job))
+	close(job.stop)
+}
+
+func TestDescribeProcess(t *testing.T) {
+	idle := db.MysqlProcess{ID: "7", User: "app", Host: "localhost", Db: "NULL", State: "Sleep", Time: "3", Query: "NULL"}
+	assert.Equal(t, "session 7 of app from localhost\nSleep for 3s\n", describeProcess(idle))
+}
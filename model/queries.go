@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"rel8/db"
+	"rel8/queries"
+)
+
+// SetQueryLibrary sets where saved queries are stored, by default under the user configuration directory
+func (csm *ContextualStateManager) SetQueryLibrary(library queries.Library) {
+	csm.queryLibrary = &library
+}
+
+// library returns the library of saved queries
+func (csm *ContextualStateManager) library() (queries.Library, error) {
+	if csm.queryLibrary != nil {
+		return *csm.queryLibrary, nil
+	}
+	dir, err := queries.DefaultDir()
+	if err != nil {
+		return queries.Library{}, err
+	}
+	return queries.Library{Dir: dir}, nil
+}
+
+// createStateWithSavedQueries lists the shared queries and those of the connection.
+// On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithSavedQueries() (State, string) {
+	library, err := csm.library()
+	if err != nil {
+		return State{}, fmt.Sprintf("queries failed: %v", err)
+	}
+	saved, err := library.List(csm.server.ConnectionName())
+	if err != nil {
+		slog.Error("listing saved queries failed", "error", err, "dir", library.Dir)
+		return State{}, fmt.Sprintf("queries failed: %v", err)
+	}
+
+	tableData := []db.TableData{}
+	for _, query := range saved {
+		tableData = append(tableData, map[string]string{
+			"NAME":       query.Name,
+			"CONNECTION": query.Connection,
+			"PARAMS":     strings.Join(queries.Params(query.SQL), ", "),
+			"SQL":        summarizeStatement(query.SQL),
+		})
+	}
+
+	newState := newBrowseState(SavedQueries, []string{"NAME", "CONNECTION", "PARAMS", "SQL"}, tableData)
+	newState.savedQueries = saved
+	if len(saved) == 0 {
+		newState.StatusText = "no saved queries in " + library.Dir + ", Ctrl-S in the editor saves one"
+	}
+	return newState, ""
+}
+
+// selectedQuery returns the saved query of a grid row
+func selectedQuery(state State, row int) (queries.Query, bool) {
+	if row < 1 || row > len(state.savedQueries) {
+		return queries.Query{}, false
+	}
+	return state.savedQueries[row-1], true
+}
+
+// runSavedQuery runs a saved query, its ${name} placeholders become bind parameters asked for before it runs
+func (csm *ContextualStateManager) runSavedQuery(ctx context.Context, query queries.Query) {
+	csm.runScript(ctx, queries.NamedPlaceholders(query.SQL))
+}
+
+// askToSaveQuery asks for a name to save the editor text under, shared or kept for the connection
+func (csm *ContextualStateManager) askToSaveQuery(ctx context.Context, text string) {
+	connection := csm.server.ConnectionName()
+	csm.PushState(ctx, State{
+		Mode:       Form,
+		StatusText: "save query",
+		FormFields: []FormField{
+			{Label: "Name", Hint: "file name without .sql"},
+			{Label: "Connection only", Hint: "y to keep it for " + connection, Value: "n"},
+		},
+		submit: func(ctx context.Context, values []string) {
+			scope := ""
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[1])), "y") {
+				scope = connection
+			}
+
+			newState := csm.GetCurrentState()
+			library, err := csm.library()
+			if err == nil {
+				_, err = library.Save(values[0], scope, text)
+			}
+			if err != nil {
+				slog.Error("saving query failed", "error", err)
+				newState.StatusText = fmt.Sprintf("save failed: %v", err)
+			} else {
+				newState.StatusText = "saved query " + strings.TrimSpace(values[0])
+			}
+			csm.ReplaceState(ctx, newState)
+		},
+	})
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+	"rel8/queries"
+)
+
+func TestHandleEventQueriesCommand(t *testing.T) {
+	library := queries.Library{Dir: t.TempDir()}
+	_, err := library.Save("active users", "", "SELECT * FROM users WHERE active = 1")
+	assert.NoError(t, err)
+	_, err = library.Save("orders of", "mock", "SELECT * FROM orders WHERE user_id = ${user_id}")
+	assert.NoError(t, err)
+	_, err = library.Save("elsewhere", "other", "SELECT 2")
+	assert.NoError(t, err)
+
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	stateManager.SetQueryLibrary(library)
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Command})
+
+	stateManager.HandleEvent(&Event{
+		Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone),
+		Text:  "queries",
+	})
+
+	list := stateManager.GetCurrentState()
+	assert.Equal(t, Browse, list.Mode)
+	assert.Equal(t, SavedQueries, list.TableMode)
+	assert.Equal(t, []string{"NAME", "CONNECTION", "PARAMS", "SQL"}, list.TableHeaders)
+	assert.Equal(t, []db.TableData{
+		map[string]string{"NAME": "active users", "CONNECTION": "", "PARAMS": "", "SQL": "SELECT * FROM users WHERE active = 1"},
+		map[string]string{"NAME": "orders of", "CONNECTION": "mock", "PARAMS": "user_id", "SQL": "SELECT * FROM orders WHERE user_id = ${user_id}"},
+	}, list.TableData)
+
+	// e opens the query in the editor
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone), Row: 1})
+	assert.Equal(t, Editor, stateManager.GetCurrentState().Mode)
+	assert.Equal(t, "SELECT * FROM users WHERE active = 1", stateManager.GetCurrentState().EditorText)
+	stateManager.PopState(ctx)
+
+	// enter runs a query without parameters right away
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Row: 1})
+	assert.Eventually(t, func() bool {
+		return stateManager.GetCurrentState().Query == "SELECT * FROM users WHERE active = 1"
+	}, time.Second, 10*time.Millisecond)
+	stateManager.PopState(ctx)
+
+	// and asks for the parameters of the others, which are bound
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Row: 2})
+	form := stateManager.GetCurrentState()
+	assert.Equal(t, Form, form.Mode)
+	assert.Equal(t, "bind parameters, NULL binds null", form.StatusText)
+	assert.Equal(t, []FormField{{Label: ":user_id", Hint: "user_id"}}, form.FormFields)
+
+	// keys other than enter go to the form
+	key := tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone)
+	assert.Equal(t, key, stateManager.HandleEvent(&Event{Event: key}))
+
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Values: []string{"42' OR '1'='1"}})
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Query != "" }, time.Second, 10*time.Millisecond)
+	result := stateManager.GetCurrentState()
+	assert.Equal(t, Browse, result.Mode)
+	assert.Equal(t, "SELECT * FROM orders WHERE user_id = ?", result.Query)
+	assert.Equal(t, []any{"42' OR '1'='1"}, result.QueryArgs)
+
+	// the form is gone from the stack
+	stateManager.PopState(ctx)
+	assert.Equal(t, SavedQueries, stateManager.GetCurrentState().TableMode)
+}
+
+func TestHandleEventQueriesCommandEmpty(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "queries")
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	stateManager.SetQueryLibrary(queries.Library{Dir: dir})
+	stateManager.PushState(context.Background(), State{Mode: Command})
+
+	stateManager.HandleEvent(&Event{
+		Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone),
+		Text:  "queries",
+	})
+
+	list := stateManager.GetCurrentState()
+	assert.Equal(t, SavedQueries, list.TableMode)
+	assert.Empty(t, list.TableData)
+	assert.Equal(t, "no saved queries in "+dir+", Ctrl-S in the editor saves one", list.StatusText)
+}
+
+func TestHandleEventEditorSavesQuery(t *testing.T) {
+	library := queries.Library{Dir: t.TempDir()}
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	stateManager.SetQueryLibrary(library)
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Editor})
+
+	save := func(values ...string) {
+		stateManager.HandleEvent(&Event{
+			Event: tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl),
+			Text:  "SELECT 1",
+		})
+		form := stateManager.GetCurrentState()
+		assert.Equal(t, Form, form.Mode)
+		assert.Equal(t, "save query", form.StatusText)
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Values: values})
+	}
+
+	save("one", "y")
+	editor := stateManager.GetCurrentState()
+	assert.Equal(t, Editor, editor.Mode)
+	assert.Equal(t, "saved query one", editor.StatusText)
+	assert.Equal(t, "SELECT 1", editor.EditorText)
+	content, err := os.ReadFile(filepath.Join(library.Dir, "mock", "one.sql"))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT 1", string(content))
+
+	save("one", "y")
+	assert.Equal(t, "save failed: query one already exists", stateManager.GetCurrentState().StatusText)
+
+	// shared by default
+	save("two")
+	assert.FileExists(t, filepath.Join(library.Dir, "two.sql"))
+
+	// an empty editor has nothing to save
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl), Text: " \n"})
+	assert.Equal(t, Editor, stateManager.GetCurrentState().Mode)
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"rel8/db"
+)
+
+const (
+	// replicationInterval is how often replication is checked for the header
+	replicationInterval = 10 * time.Second
+	// defaultReplicationLag is the lag in seconds reported as a problem unless set otherwise,
+	// replicas of a busy source are often a second behind
+	defaultReplicationLag = 2
+)
+
+// replicationRows lists the replication channels, LAG is blank when unknown
+func replicationRows(links []db.ReplicationLink) ([]string, []db.TableData) {
+	data := []db.TableData{}
+	for _, link := range links {
+		lag := ""
+		if link.LagSeconds >= 0 {
+			lag = strconv.FormatInt(link.LagSeconds, 10) + "s"
+		}
+		data = append(data, map[string]string{
+			"ROLE": link.Role, "HOST": link.Host, "STATE": link.State, "LAG": lag, "ERROR": link.LastError,
+		})
+	}
+	return []string{"ROLE", "HOST", "STATE", "LAG", "ERROR"}, data
+}
+
+// replicationProblem names the worst problem of the replication channels, an error before a stopped channel
+// before a lag of lagSeconds or more, or returns an empty string when replication is healthy
+func replicationProblem(links []db.ReplicationLink, lagSeconds int64) string {
+	var lag int64
+	stopped := false
+	for _, link := range links {
+		if link.LastError != "" {
+			return "replication error"
+		}
+		stopped = stopped || !link.Running
+		lag = max(lag, link.LagSeconds)
+	}
+	switch {
+	case stopped:
+		return "replication stopped"
+	case lag >= lagSeconds:
+		return fmt.Sprintf("replica lag %ds", lag)
+	}
+	return ""
+}
+
+// createStateWithReplication lists the replication channels of the server, refreshed while shown.
+// On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithReplication(ctx context.Context) (State, string) {
+	links, err := csm.server.FetchReplication(ctx)
+	if err != nil {
+		slog.Error("reading replication failed", "error", err)
+		return State{}, fmt.Sprintf("reading replication failed: %v", err)
+	}
+
+	headers, data := replicationRows(links)
+	newState := newBrowseState(Replication, headers, data)
+	newState.WatchInterval = processRefreshInterval
+	newState.StatusText = pluralize(len(links), "replication channel")
+	if problem := replicationProblem(links, csm.replicationLag); problem != "" {
+		newState.StatusText += ", " + problem
+	}
+	if len(links) == 0 {
+		newState.StatusText = "the server does not replicate"
+	}
+	csm.startLiveWatch(newState, processRefreshInterval)
+	return newState, ""
+}
+
+// SetReplicationLag sets the lag in seconds from which replication is reported as a problem, a lag below 1
+// keeps the default
+func (csm *ContextualStateManager) SetReplicationLag(seconds int64) {
+	if seconds >= 1 {
+		csm.replicationLag = seconds
+	}
+}
+
+// MonitorReplication checks the replication of the server every few seconds and hands a problem such as lag or
+// an error to listener on the UI goroutine when it changes, an empty problem once replication is healthy again.
+// It runs until stop is called, SQLite is not checked
+func (csm *ContextualStateManager) MonitorReplication(listener func(problem string)) (stop func()) {
+	done := make(chan struct{})
+	stop = func() { close(done) }
+	if csm.server.Dialect() == db.DialectSqlite {
+		return stop
+	}
+
+	go func() {
+		ticker := time.NewTicker(replicationInterval)
+		defer ticker.Stop()
+		reported := ""
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), replicationInterval)
+			links, err := csm.server.FetchReplication(ctx)
+			cancel()
+			if err != nil {
+				// such as a user without the privilege to read replica status
+				slog.Debug("checking replication failed", "error", err)
+			} else if problem := replicationProblem(links, csm.replicationLag); problem != reported {
+				reported = problem
+				csm.queueUpdate(func() { listener(problem) })
+			}
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return stop
+}
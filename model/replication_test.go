@@ -0,0 +1,66 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestReplicationProblem(t *testing.T) {
+	tests := []struct {
+		name     string
+		links    []db.ReplicationLink
+		expected string
+	}{
+		{name: "no replication", expected: ""},
+		{name: "up to date", links: []db.ReplicationLink{{Running: true}}, expected: ""},
+		{name: "unknown lag", links: []db.ReplicationLink{{Running: true, LagSeconds: -1}}, expected: ""},
+		{name: "lag below the threshold", links: []db.ReplicationLink{{Running: true, LagSeconds: 1}}, expected: ""},
+		{name: "lag", links: []db.ReplicationLink{{Running: true, LagSeconds: 3}, {Running: true, LagSeconds: 12}},
+			expected: "replica lag 12s"},
+		{name: "stopped before lag", links: []db.ReplicationLink{{Running: true, LagSeconds: 3}, {LagSeconds: -1}},
+			expected: "replication stopped"},
+		{name: "error before stopped", links: []db.ReplicationLink{{LagSeconds: -1}, {LastError: "Duplicate entry"}},
+			expected: "replication error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, replicationProblem(tt.links, defaultReplicationLag))
+		})
+	}
+}
+
+func TestHandleEventReplicationCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "replication"})
+
+	replication := stateManager.GetCurrentState()
+	assert.Equal(t, Replication, replication.TableMode)
+	assert.Equal(t, []string{"ROLE", "HOST", "STATE", "LAG", "ERROR"}, replication.TableHeaders)
+	assert.Equal(t, []db.TableData{map[string]string{
+		"ROLE": "source", "HOST": "db-primary:3306", "STATE": "IO Yes, SQL Yes", "LAG": "0s", "ERROR": "",
+	}}, replication.TableData)
+	assert.Equal(t, "1 replication channel", replication.StatusText)
+	assert.Equal(t, processRefreshInterval, replication.WatchInterval)
+
+	// the channels refresh while shown
+	job := stateManager.liveWatch
+	assert.True(t, stateManager.refreshWatched(job))
+	assert.Regexp(t, `^watching every 2s, refreshed `, stateManager.GetCurrentState().StatusText)
+	stateManager.stopLiveWatch()
+	stateManager.PopState(ctx)
+}
+
+func TestMonitorReplicationWithoutReplication(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.Mysql8{Mysql: db.Mysql{DriverName: "sqlite3"}}, *Initial, 10)
+	stop := stateManager.MonitorReplication(func(problem string) {
+		t.Error("sqlite does not replicate")
+	})
+	stop()
+}
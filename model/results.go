@@ -0,0 +1,161 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// resultTitleLength is the longest query text shown in a result tab title
+const resultTitleLength = 24
+
+// isResult reports whether a state shows the rows of a query, results are switched between as tabs
+func isResult(state State) bool {
+	return state.Mode == Browse && state.TableMode == TableRow && state.Query != ""
+}
+
+// numberResultLocked gives a new result its id, called with the lock held
+func (csm *ContextualStateManager) numberResultLocked(state *State) {
+	if isResult(*state) && state.resultID == 0 {
+		csm.lastResultID++
+		state.resultID = csm.lastResultID
+	}
+}
+
+// resultsLocked returns the pinned results and those in the history in the order they were run,
+// called with the lock held
+func (csm *ContextualStateManager) resultsLocked() []State {
+	var results []State
+	seen := map[int]bool{}
+	for _, state := range append(append([]State{}, csm.pinned...), csm.stateStack...) {
+		if isResult(state) && !seen[state.resultID] {
+			seen[state.resultID] = true
+			results = append(results, state)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].resultID < results[j].resultID })
+	return results
+}
+
+// decorateTopLocked sets the result tabs of the current state, shown when there is more than one result.
+// Called with the lock held
+func (csm *ContextualStateManager) decorateTopLocked() {
+	top := &csm.stateStack[len(csm.stateStack)-1]
+	top.ResultTabs, top.SelectedResult = nil, 0
+	if !isResult(*top) {
+		return
+	}
+	results := csm.resultsLocked()
+	if len(results) < 2 {
+		return
+	}
+	for i, result := range results {
+		title := fmt.Sprintf("%d %s", result.resultID, summarizeQuery(result.Query))
+		if csm.isPinnedLocked(result.resultID) {
+			title = "*" + title
+		}
+		top.ResultTabs = append(top.ResultTabs, Tab{Title: title})
+		if result.resultID == top.resultID {
+			top.SelectedResult = i
+		}
+	}
+}
+
+// isPinnedLocked reports whether a result is pinned, called with the lock held
+func (csm *ContextualStateManager) isPinnedLocked(resultID int) bool {
+	for _, pinned := range csm.pinned {
+		if pinned.resultID == resultID {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeQuery shortens a query for a tab title
+func summarizeQuery(query string) string {
+	text := strings.Join(strings.Fields(query), " ")
+	if runes := []rune(text); len(runes) > resultTitleLength {
+		return string(runes[:resultTitleLength-3]) + "..."
+	}
+	return text
+}
+
+// results returns the pinned results and those in the history in the order they were run
+func (csm *ContextualStateManager) results() []State {
+	csm.mu.RLock()
+	defer csm.mu.RUnlock()
+	return csm.resultsLocked()
+}
+
+// switchResult shows the result offset tabs away from the current one, Escape returns to it
+func (csm *ContextualStateManager) switchResult(ctx context.Context, offset int) {
+	current := csm.GetCurrentState()
+	results := csm.results()
+	for i, result := range results {
+		if result.resultID == current.resultID {
+			target := results[(i+offset+len(results))%len(results)]
+			if target.resultID != current.resultID {
+				csm.PushState(ctx, target)
+			}
+			return
+		}
+	}
+}
+
+// togglePin pins the current result so it is kept after leaving it, or unpins it
+func (csm *ContextualStateManager) togglePin(ctx context.Context) {
+	current := csm.GetCurrentState()
+	csm.mu.Lock()
+	pinned := csm.isPinnedLocked(current.resultID)
+	if pinned {
+		kept := csm.pinned[:0]
+		for _, state := range csm.pinned {
+			if state.resultID != current.resultID {
+				kept = append(kept, state)
+			}
+		}
+		csm.pinned = kept
+	} else {
+		csm.pinned = append(csm.pinned, current)
+	}
+	csm.mu.Unlock()
+
+	if pinned {
+		current.StatusText = fmt.Sprintf("unpinned result %d", current.resultID)
+	} else {
+		current.StatusText = fmt.Sprintf("pinned result %d", current.resultID)
+	}
+	csm.ReplaceState(ctx, current)
+}
+
+// toggleSplit shows the previous result beside the current one, the next when there is none before it,
+// or closes the split
+func (csm *ContextualStateManager) toggleSplit(ctx context.Context) {
+	current := csm.GetCurrentState()
+	if current.SplitResult != nil {
+		current.SplitResult = nil
+		current.StatusText = ""
+		csm.ReplaceState(ctx, current)
+		return
+	}
+
+	results := csm.results()
+	current.StatusText = "no other result to compare with"
+	for i, result := range results {
+		if result.resultID != current.resultID || len(results) < 2 {
+			continue
+		}
+		other := results[i+1:]
+		if i > 0 {
+			other = results[i-1:]
+		}
+		current.SplitResult = &Tab{
+			Title:        fmt.Sprintf("%d %s", other[0].resultID, summarizeQuery(other[0].Query)),
+			TableHeaders: other[0].TableHeaders,
+			TableData:    other[0].TableData,
+		}
+		current.StatusText = ""
+	}
+	csm.ReplaceState(ctx, current)
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventResultTabs(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	key := func(r rune) {
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), Row: 1})
+	}
+	titles := func() []string {
+		var titles []string
+		for _, tab := range stateManager.GetCurrentState().ResultTabs {
+			titles = append(titles, tab.Title)
+		}
+		return titles
+	}
+
+	// a single result has no tabs and nothing to compare with
+	stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT 1"))
+	assert.Nil(t, stateManager.GetCurrentState().ResultTabs)
+	key('|')
+	assert.Equal(t, "no other result to compare with", stateManager.GetCurrentState().StatusText)
+	assert.Nil(t, stateManager.GetCurrentState().SplitResult)
+
+	stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT  name\nFROM users WHERE active = 1"))
+	assert.Equal(t, []string{"1 SELECT 1", "2 SELECT name FROM user..."}, titles())
+	assert.Equal(t, 1, stateManager.GetCurrentState().SelectedResult)
+
+	// switching shows the other result, Escape returns
+	key('[')
+	assert.Equal(t, "SELECT 1", stateManager.GetCurrentState().Query)
+	assert.Equal(t, 0, stateManager.GetCurrentState().SelectedResult)
+
+	key('p')
+	assert.Equal(t, "pinned result 1", stateManager.GetCurrentState().StatusText)
+	assert.Equal(t, []string{"*1 SELECT 1", "2 SELECT name FROM user..."}, titles())
+
+	key('|')
+	split := stateManager.GetCurrentState().SplitResult
+	assert.Equal(t, "2 SELECT name FROM user...", split.Title)
+	assert.Equal(t, []string{"id", "result", "query_executed"}, split.TableHeaders)
+	key('|')
+	assert.Nil(t, stateManager.GetCurrentState().SplitResult)
+
+	// leaving results keeps the pinned one
+	stateManager.PopState(ctx)
+	assert.Equal(t, 1, stateManager.GetCurrentState().SelectedResult)
+	stateManager.PopState(ctx)
+	stateManager.PopState(ctx)
+	assert.Nil(t, stateManager.GetCurrentState().ResultTabs)
+
+	stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT 3"))
+	assert.Equal(t, []string{"*1 SELECT 1", "3 SELECT 3"}, titles())
+	key(']')
+	assert.Equal(t, "SELECT 1", stateManager.GetCurrentState().Query)
+	key('p')
+	assert.Equal(t, "unpinned result 1", stateManager.GetCurrentState().StatusText)
+	assert.Equal(t, []string{"1 SELECT 1", "3 SELECT 3"}, titles())
+}
+
+func TestSummarizeQuery(t *testing.T) {
+	assert.Equal(t, "SELECT 1", summarizeQuery(" SELECT\n 1 "))
+	assert.Equal(t, "SELECT * FROM orders ...", summarizeQuery("SELECT * FROM orders o JOIN users u"))
+}
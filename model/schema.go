@@ -0,0 +1,130 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"rel8/db"
+)
+
+// schemaDiffTimeout bounds connecting to a profile and reading both schemas
+const schemaDiffTimeout = 30 * time.Second
+
+// SetProfiles sets the connection strings of other databases by profile name, their schema is compared with :schemadiff
+func (csm *ContextualStateManager) SetProfiles(profiles map[string]string) {
+	csm.profiles = profiles
+}
+
+// schemaDiffJob is a schema comparison run in the background for the state it was started from
+type schemaDiffJob struct {
+	profile string
+}
+
+// owns reports whether a state started the comparison
+func (job *schemaDiffJob) owns(state State) bool {
+	return state.schemaDiffJob == job
+}
+
+// startSchemaDiff compares the schema with that of a profile in the background and shows the differences once
+// read. The current state tells that the comparison runs, or why it failed
+func (csm *ContextualStateManager) startSchemaDiff(ctx context.Context, args []string) {
+	newState := csm.GetCurrentState()
+	if len(args) != 1 {
+		newState.StatusText = "usage: schemadiff <profile>, profiles: " + csm.profileNames()
+		csm.ReplaceState(ctx, newState)
+		return
+	}
+	profile := strings.ToLower(args[0])
+	connStr, ok := csm.profiles[profile]
+	if !ok {
+		newState.StatusText = fmt.Sprintf("no profile %s, profiles: %s", args[0], csm.profileNames())
+		csm.ReplaceState(ctx, newState)
+		return
+	}
+
+	job := &schemaDiffJob{profile: profile}
+	newState.schemaDiffJob = job
+	newState.StatusText = "comparing schema with " + profile
+	csm.ReplaceState(ctx, newState)
+
+	go func() {
+		diffCtx, cancel := context.WithTimeout(context.Background(), schemaDiffTimeout)
+		defer cancel()
+
+		diffState, status := csm.createStateWithSchemaDiff(diffCtx, profile, connStr)
+		if status != "" {
+			csm.setStatusWhere(job.owns, status)
+			return
+		}
+		csm.queueUpdate(func() {
+			csm.PushState(context.Background(), diffState)
+		})
+	}()
+}
+
+// createStateWithSchemaDiff compares the schema of the connection with that of a profile: tables, columns,
+// indexes and foreign keys. The state keeps the ALTER statements making the profile match, opened in the
+// editor with e. On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithSchemaDiff(ctx context.Context, profile string, connStr string) (State, string) {
+	here, err := csm.server.FetchSchema(ctx)
+	if err != nil {
+		slog.Error("reading schema failed", "error", err)
+		return State{}, fmt.Sprintf("reading schema failed: %v", err)
+	}
+	other, err := csm.openProfile(ctx, connStr)
+	if err != nil {
+		slog.Error("connecting to profile failed", "profile", profile, "error", err)
+		return State{}, fmt.Sprintf("connecting to %s failed: %v", profile, err)
+	}
+	if other.Db() != nil {
+		defer other.Db().Close()
+	}
+	there, err := other.FetchSchema(ctx)
+	if err != nil {
+		slog.Error("reading schema of profile failed", "profile", profile, "error", err)
+		return State{}, fmt.Sprintf("reading schema of %s failed: %v", profile, err)
+	}
+
+	changes := db.DiffSchemas(here, there, other.Dialect())
+	thereHeader := strings.ToUpper(profile)
+	var tableData []db.TableData
+	for _, change := range changes {
+		difference := "differs"
+		switch {
+		case change.There == "":
+			difference = "missing"
+		case change.Here == "":
+			difference = "extra"
+		}
+		tableData = append(tableData, map[string]string{
+			"TABLE": change.Table, "OBJECT": change.Object, "NAME": change.Name, "CHANGE": difference,
+			"HERE": change.Here, thereHeader: change.There,
+		})
+	}
+
+	newState := newBrowseState(SchemaDiff, []string{"TABLE", "OBJECT", "NAME", "CHANGE", "HERE", thereHeader}, tableData)
+	newState.alterScript = db.AlterScript(changes)
+	if len(changes) == 0 {
+		newState.StatusText = fmt.Sprintf("schema of %s matches %s", csm.server.ConnectionName(), profile)
+	} else {
+		newState.StatusText = fmt.Sprintf("%d differences from %s, e opens the statements making it match", len(changes), profile)
+	}
+	return newState, ""
+}
+
+// profileNames lists the configured profiles
+func (csm *ContextualStateManager) profileNames() string {
+	if len(csm.profiles) == 0 {
+		return "none, set DB_PROFILE_<NAME>"
+	}
+	names := make([]string, 0, len(csm.profiles))
+	for name := range csm.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
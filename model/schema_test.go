@@ -0,0 +1,90 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+// driftedServer is a profile whose users table lacks the email column
+type driftedServer struct {
+	db.MysqlMock
+}
+
+func (s *driftedServer) FetchSchema(ctx context.Context) ([]db.SchemaTable, error) {
+	schema, _ := s.MysqlMock.FetchSchema(ctx)
+	users := &schema[1]
+	users.Columns = users.Columns[:2]
+	users.Indexes = users.Indexes[:1]
+	return schema, nil
+}
+
+func TestHandleEventSchemaDiffCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	stateManager.SetProfiles(map[string]string{"prod": "mysql://prod/shop", "down": "mysql://down/shop"})
+	stateManager.openProfile = func(ctx context.Context, connStr string) (db.DatabaseServer, error) {
+		if connStr == "mysql://down/shop" {
+			return nil, errors.New("connection refused")
+		}
+		return &driftedServer{}, nil
+	}
+	ctx := context.Background()
+	command := func(text string) State {
+		stateManager.PushState(ctx, State{Mode: Command})
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: text})
+		return stateManager.GetCurrentState()
+	}
+
+	assert.Equal(t, "usage: schemadiff <profile>, profiles: down, prod", command("schemadiff").StatusText)
+	assert.Equal(t, "no profile staging, profiles: down, prod", command("schemadiff staging").StatusText)
+	assert.Equal(t, "comparing schema with down", command("schemadiff down").StatusText)
+	assert.Eventually(t, func() bool {
+		return stateManager.GetCurrentState().StatusText == "connecting to down failed: connection refused"
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "comparing schema with prod", command("schemadiff PROD").StatusText)
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().TableMode == SchemaDiff }, time.Second, 10*time.Millisecond)
+	diff := stateManager.GetCurrentState()
+	assert.Equal(t, []string{"TABLE", "OBJECT", "NAME", "CHANGE", "HERE", "PROD"}, diff.TableHeaders)
+	assert.Equal(t, []db.TableData{
+		map[string]string{"TABLE": "users", "OBJECT": "column", "NAME": "email", "CHANGE": "missing", "HERE": "varchar(255)", "PROD": ""},
+		map[string]string{"TABLE": "users", "OBJECT": "index", "NAME": "idx_users_email", "CHANGE": "missing", "HERE": "UNIQUE (email)", "PROD": ""},
+	}, diff.TableData)
+	assert.Equal(t, "2 differences from prod, e opens the statements making it match", diff.StatusText)
+
+	// e opens the statements in the editor
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone), Row: 1})
+	editor := stateManager.GetCurrentState()
+	assert.Equal(t, Editor, editor.Mode)
+	assert.Equal(t, "ALTER TABLE `users` ADD COLUMN `email` varchar(255);\n"+
+		"CREATE UNIQUE INDEX `idx_users_email` ON `users` (`email`);", editor.EditorText)
+}
+
+func TestHandleEventSchemaDiffCommandMatching(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	stateManager.openProfile = func(ctx context.Context, connStr string) (db.DatabaseServer, error) {
+		return &db.MysqlMock{}, nil
+	}
+	ctx := context.Background()
+
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "schemadiff prod"})
+	assert.Equal(t, "no profile prod, profiles: none, set DB_PROFILE_<NAME>", stateManager.GetCurrentState().StatusText)
+
+	stateManager.SetProfiles(map[string]string{"prod": "mysql://prod/shop"})
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "schemadiff prod"})
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().TableMode == SchemaDiff }, time.Second, 10*time.Millisecond)
+	diff := stateManager.GetCurrentState()
+	assert.Empty(t, diff.TableData)
+	assert.Equal(t, "schema of mock matches prod", diff.StatusText)
+
+	// nothing to open in the editor
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone), Row: 1})
+	assert.Equal(t, SchemaDiff, stateManager.GetCurrentState().TableMode)
+}
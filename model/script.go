@@ -0,0 +1,191 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"rel8/db"
+)
+
+// scriptTimeout bounds execution of the statements run from the editor
+const scriptTimeout = 5 * time.Minute
+
+// summaryStatementLength is the longest statement text shown in the script summary
+const summaryStatementLength = 60
+
+// runScript runs the statements of text, a single statement shows its own result and several a summary
+func (csm *ContextualStateManager) runScript(ctx context.Context, text string) {
+	csm.runStatements(ctx, db.SplitStatements(text))
+}
+
+// runStatements runs a single statement, asking for the values of its placeholders first,
+// or several showing a summary. Placeholders are only bound in a single statement
+func (csm *ContextualStateManager) runStatements(ctx context.Context, statements []db.Statement) {
+	status := ""
+	switch len(statements) {
+	case 0:
+		status = "nothing to run"
+	case 1:
+		csm.bindAndRun(ctx, statements[0].Text, func(ctx context.Context, sql string, args []any) {
+			csm.runInBackground(ctx, "running "+summarizeStatement(sql), func(ctx context.Context) State {
+				return csm.createStateWithStatementResult(ctx, db.Statement{Text: sql}, args...)
+			})
+		})
+		return
+	default:
+		for _, statement := range statements {
+			if len(db.FindBindParams(csm.server.Dialect(), statement.Text)) > 0 {
+				status = "placeholders are bound in a single statement, F5 runs the one under the cursor"
+				break
+			}
+		}
+	}
+	if status != "" {
+		newState := csm.GetCurrentState()
+		newState.StatusText = status
+		csm.ReplaceState(ctx, newState)
+		return
+	}
+
+	csm.startScript(ctx, statements)
+}
+
+// startScript runs several statements in the background, reporting progress in the header, and shows their
+// results once all ran. The editor tells that the script runs meanwhile
+func (csm *ContextualStateManager) startScript(ctx context.Context, statements []db.Statement) {
+	csm.runInBackground(ctx, fmt.Sprintf("running %d statements", len(statements)), func(ctx context.Context) State {
+		defer csm.reportProgress("")
+		return csm.createStateWithScriptResults(ctx, statements, func(done int) {
+			csm.reportProgress(fmt.Sprintf("ran %d/%d statements", done, len(statements)))
+		})
+	})
+}
+
+// runInBackground shows status on the current state and pushes the state made by work once it is done. work gets
+// scriptTimeout rather than the context of the event, which ends after a few seconds
+func (csm *ContextualStateManager) runInBackground(ctx context.Context, status string, work func(ctx context.Context) State) {
+	newState := csm.GetCurrentState()
+	newState.StatusText = status
+	csm.ReplaceState(ctx, newState)
+
+	go func() {
+		workCtx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+		defer cancel()
+
+		resultState := work(workCtx)
+		csm.queueUpdate(func() {
+			csm.PushState(context.Background(), resultState)
+		})
+	}()
+}
+
+// createStateWithStatementResult executes a single statement with bind arguments and shows its result set,
+// or its affected row count, with timing in the status
+func (csm *ContextualStateManager) createStateWithStatementResult(ctx context.Context, statement db.Statement, args ...any) State {
+	result := csm.server.ExecuteSql(ctx, statement.Text, args...)
+
+	newState := State{Mode: Browse, TableMode: TableRow}
+	switch {
+	case result.Err != nil:
+		newState.TableHeaders = []string{"Error"}
+		newState.TableData = []db.TableData{map[string]string{"Error": result.Err.Error()}}
+	case result.Headers != nil:
+		newState.TableHeaders = result.Headers
+		newState.TableData = result.Data
+		newState.Query = statement.Text
+		newState.QueryArgs = args
+	default:
+		newState.TableHeaders = []string{"Statement", "Rows Affected"}
+		newState.TableData = []db.TableData{map[string]string{
+			"Statement":     statement.Text,
+			"Rows Affected": fmt.Sprintf("%d", result.RowsAffected),
+		}}
+	}
+	newState.StatusText = describeResult(result) + " in " + formatDuration(result.Duration)
+
+	return newState
+}
+
+// createStateWithScriptResults executes statements in order on a single connection, stopping at the first error.
+// The first tab summarizes every statement, each executed statement gets a tab of its own
+func (csm *ContextualStateManager) createStateWithScriptResults(ctx context.Context, statements []db.Statement, progress func(done int)) State {
+	texts := make([]string, len(statements))
+	for i, statement := range statements {
+		texts[i] = statement.Text
+	}
+	results := csm.server.ExecuteScript(ctx, texts, progress)
+
+	summary := Tab{Title: "Summary", TableHeaders: []string{"#", "STATEMENT", "RESULT", "TIME"}}
+	var tabs []Tab
+	for i, statement := range statements {
+		row := map[string]string{
+			"#":         fmt.Sprintf("%d", i+1),
+			"STATEMENT": summarizeStatement(statement.Text),
+			"RESULT":    "skipped",
+			"TIME":      "",
+		}
+		if i >= len(results) {
+			summary.TableData = append(summary.TableData, row)
+			continue
+		}
+
+		result := results[i]
+		row["RESULT"] = describeResult(result)
+		row["TIME"] = formatDuration(result.Duration)
+		summary.TableData = append(summary.TableData, row)
+
+		tab := Tab{Title: fmt.Sprintf("#%d", i+1)}
+		if result.Err == nil && result.Headers != nil {
+			tab.TableHeaders = result.Headers
+			tab.TableData = result.Data
+		} else {
+			tab.DetailText = row["RESULT"] + " in " + row["TIME"] + "\n\n" + statement.Text
+		}
+		tabs = append(tabs, tab)
+	}
+
+	return State{
+		Mode:        Tabbed,
+		Tabs:        append([]Tab{summary}, tabs...),
+		SelectedTab: 0,
+	}
+}
+
+// describeResult summarizes the outcome of a statement
+func describeResult(result db.StatementResult) string {
+	switch {
+	case result.Err != nil:
+		return "error: " + result.Err.Error()
+	case result.Headers != nil:
+		return pluralize(len(result.Data), "row")
+	default:
+		return pluralize(int(result.RowsAffected), "row") + " affected"
+	}
+}
+
+// pluralize formats a count with its noun
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// formatDuration shows milliseconds for fast statements and rounded durations otherwise
+func formatDuration(duration time.Duration) string {
+	if duration < time.Second {
+		return fmt.Sprintf("%.1fms", float64(duration.Microseconds())/1000)
+	}
+	return duration.Round(10 * time.Millisecond).String()
+}
+
+// summarizeStatement collapses a statement to one line, cut to summaryStatementLength
+func summarizeStatement(statement string) string {
+	text := strings.Join(strings.Fields(statement), " ")
+	if runes := []rune(text); len(runes) > summaryStatementLength {
+		return string(runes[:summaryStatementLength-3]) + "..."
+	}
+	return text
+}
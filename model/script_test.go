@@ -0,0 +1,154 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventEditorRunsStatementUnderCursor(t *testing.T) {
+	script := "SELECT 1;\nUPDATE users SET active = 1;"
+
+	tests := []struct {
+		name            string
+		cursor          int
+		expectedHeaders []string
+		expectedQuery   string
+		expectedStatus  string
+	}{
+		{
+			name:            "query shows rows",
+			cursor:          3,
+			expectedHeaders: []string{"id", "result", "query_executed"},
+			expectedQuery:   "SELECT 1",
+			expectedStatus:  "10 rows in 1.0ms",
+		},
+		{
+			name:            "update shows affected rows",
+			cursor:          len(script),
+			expectedHeaders: []string{"Statement", "Rows Affected"},
+			expectedStatus:  "1 row affected in 1.0ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+			stateManager.PushState(context.Background(), State{Mode: Editor})
+
+			stateManager.HandleEvent(&Event{
+				Event:  tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModNone),
+				Text:   script,
+				Cursor: tt.cursor,
+			})
+
+			assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == Browse }, time.Second, 10*time.Millisecond)
+			currentState := stateManager.GetCurrentState()
+			assert.Equal(t, Browse, currentState.Mode)
+			assert.Equal(t, tt.expectedHeaders, currentState.TableHeaders)
+			assert.Equal(t, tt.expectedQuery, currentState.Query)
+			assert.Equal(t, tt.expectedStatus, currentState.StatusText)
+		})
+	}
+}
+
+// slowServer runs statements once released
+type slowServer struct {
+	db.MysqlMock
+	release chan struct{}
+}
+
+func (s *slowServer) ExecuteSql(ctx context.Context, statement string, args ...any) db.StatementResult {
+	<-s.release
+	if err := ctx.Err(); err != nil {
+		return db.StatementResult{Err: err}
+	}
+	return s.MysqlMock.ExecuteSql(ctx, statement, args...)
+}
+
+func TestHandleEventEditorRunsStatementInBackground(t *testing.T) {
+	server := &slowServer{release: make(chan struct{})}
+	stateManager := NewContextualStateManager(server, *Initial, 10)
+	stateManager.PushState(context.Background(), State{Mode: Editor})
+
+	stateManager.HandleEvent(&Event{
+		Event: tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModNone),
+		Text:  "UPDATE users SET active = 1",
+	})
+
+	// the statement outlasts the event, whose context is done once it is handled
+	editor := stateManager.GetCurrentState()
+	assert.Equal(t, Editor, editor.Mode)
+	assert.Equal(t, "running UPDATE users SET active = 1", editor.StatusText)
+	close(server.release)
+
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == Browse }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "1 row affected in 1.0ms", stateManager.GetCurrentState().StatusText)
+}
+
+func TestHandleEventEditorRunsScript(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectExec("UPDATE users SET active = 1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM missing").
+		WillReturnError(errors.New("table missing doesn't exist"))
+
+	stateManager := NewContextualStateManager(&db.Mysql8{db.Mysql{DbInstance: mockDB}}, *Initial, 10)
+	stateManager.PushState(context.Background(), State{Mode: Editor})
+
+	stateManager.HandleEvent(&Event{
+		Event: tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModCtrl),
+		Text:  "SELECT id FROM users;\nUPDATE users SET active = 1;\nDELETE FROM missing;\nSELECT 2;",
+	})
+
+	// the script runs in the background, the editor tells meanwhile
+	assert.Eventually(t, func() bool {
+		return stateManager.GetCurrentState().Mode == Tabbed
+	}, time.Second, 10*time.Millisecond)
+	history := stateManager.GetHistory()
+	assert.Equal(t, "running 4 statements", history[len(history)-2].StatusText)
+
+	currentState := stateManager.GetCurrentState()
+	assert.Equal(t, Tabbed, currentState.Mode)
+	assert.Equal(t, 0, currentState.SelectedTab)
+	assert.Len(t, currentState.Tabs, 4)
+
+	summary := currentState.Tabs[0]
+	assert.Equal(t, "Summary", summary.Title)
+	var results []string
+	for _, row := range summary.TableData {
+		results = append(results, row.(map[string]string)["RESULT"])
+	}
+	assert.Equal(t, []string{"2 rows", "2 rows affected", "error: table missing doesn't exist", "skipped"}, results)
+
+	assert.Equal(t, "#1", currentState.Tabs[1].Title)
+	assert.Equal(t, []string{"id"}, currentState.Tabs[1].TableHeaders)
+	assert.Nil(t, currentState.Tabs[2].TableHeaders)
+	assert.Contains(t, currentState.Tabs[2].DetailText, "2 rows affected in ")
+	assert.Contains(t, currentState.Tabs[3].DetailText, "DELETE FROM missing")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "0.3ms", formatDuration(300*time.Microsecond))
+	assert.Equal(t, "12.0ms", formatDuration(12*time.Millisecond))
+	assert.Equal(t, "1.23s", formatDuration(1234*time.Millisecond))
+}
+
+func TestSummarizeStatement(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM t WHERE a = 1", summarizeStatement("SELECT *\n  FROM t\n  WHERE a = 1"))
+	assert.Len(t, []rune(summarizeStatement(strings.Repeat("x", 100))), summaryStatementLength)
+}
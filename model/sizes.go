@@ -0,0 +1,197 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rel8/db"
+	"rel8/export"
+)
+
+// byteUnits are the units sizes are shown in, each 1024 times the one before
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB"}
+
+// formatBytes shows a size in the largest unit it reaches, such as 12.5MB
+func formatBytes(size int64) string {
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d%s", size, byteUnits[0])
+	}
+	return fmt.Sprintf("%.1f%s", value, byteUnits[unit])
+}
+
+// parseQuantity reads the number a cell starts with, scaled by a byte unit following it. It reports false for
+// cells not starting with a number, which sort as text
+func parseQuantity(cell string) (float64, bool) {
+	end := 0
+	for end < len(cell) && (cell[end] == '-' || cell[end] == '.' || cell[end] >= '0' && cell[end] <= '9') {
+		end++
+	}
+	value, err := strconv.ParseFloat(cell[:end], 64)
+	if err != nil {
+		return 0, false
+	}
+	unit, _, _ := strings.Cut(cell[end:], " ")
+	if scale := slices.Index(byteUnits, unit); scale > 0 {
+		for range scale {
+			value *= 1024
+		}
+	}
+	return value, true
+}
+
+// redundantIndexes finds the indexes of a table whose columns lead another index, which serves the same lookups.
+// It returns the covering index by redundant index name. Unique indexes are kept unless an index on the same
+// columns enforces the same, and of two equal indexes the one named first is kept
+func redundantIndexes(table db.SchemaTable) map[string]string {
+	redundant := map[string]string{}
+	for _, index := range table.Indexes {
+		if index.Primary || len(index.Columns) == 0 {
+			continue
+		}
+		for _, other := range table.Indexes {
+			if other.Name == index.Name || len(other.Columns) < len(index.Columns) ||
+				!slices.Equal(other.Columns[:len(index.Columns)], index.Columns) {
+				continue
+			}
+			same := len(other.Columns) == len(index.Columns)
+			if index.Unique && !(same && (other.Unique || other.Primary)) {
+				continue
+			}
+			if same && !other.Primary && index.Unique == other.Unique && index.Name < other.Name {
+				continue
+			}
+			redundant[index.Name] = other.Name
+			break
+		}
+	}
+	return redundant
+}
+
+// createStateWithSizes lists the tables with their data, index and free space, estimated rows and the indexes
+// worth a look: those never read and those covered by another index. Tables are sorted by total size, o sorts
+// by the column under the cursor. On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithSizes(ctx context.Context) (State, string) {
+	sizes, err := csm.server.FetchTableSizes(ctx)
+	if err != nil {
+		slog.Error("reading table sizes failed", "error", err)
+		return State{}, fmt.Sprintf("reading table sizes failed: %v", err)
+	}
+
+	unused := map[string][]string{}
+	unusedCount := 0
+	unusedIndexes, unusedErr := csm.server.FetchUnusedIndexes(ctx)
+	if unusedErr != nil {
+		// the sys schema may be missing or not readable, sizes are still worth showing
+		slog.Warn("reading unused indexes failed", "error", unusedErr)
+	}
+	for _, index := range unusedIndexes {
+		unused[index.Table] = append(unused[index.Table], index.Name)
+		unusedCount++
+	}
+
+	duplicates := map[string][]string{}
+	duplicateCount := 0
+	schema, schemaErr := csm.server.FetchSchema(ctx)
+	if schemaErr != nil {
+		slog.Warn("reading indexes failed", "error", schemaErr)
+	}
+	for _, table := range schema {
+		redundant := redundantIndexes(table)
+		for _, index := range table.Indexes {
+			if covering, ok := redundant[index.Name]; ok {
+				duplicates[table.Name] = append(duplicates[table.Name], fmt.Sprintf("%s (%s)", index.Name, covering))
+				duplicateCount++
+			}
+		}
+	}
+
+	headers := []string{"TABLE", "ROWS", "DATA", "INDEX", "TOTAL", "FREE", "UNUSED INDEXES", "DUPLICATE INDEXES"}
+	data := []db.TableData{}
+	var dataBytes, indexBytes int64
+	for _, size := range sizes {
+		free := formatBytes(size.FreeBytes)
+		if size.FreeBytes > 0 {
+			free += fmt.Sprintf(" (%d%%)", 100*size.FreeBytes/(size.DataBytes+size.FreeBytes))
+		}
+		data = append(data, map[string]string{
+			"TABLE": size.Name, "ROWS": strconv.FormatInt(size.Rows, 10),
+			"DATA": formatBytes(size.DataBytes), "INDEX": formatBytes(size.IndexBytes),
+			"TOTAL": formatBytes(size.DataBytes + size.IndexBytes), "FREE": free,
+			"UNUSED INDEXES":    strings.Join(unused[size.Name], ", "),
+			"DUPLICATE INDEXES": strings.Join(duplicates[size.Name], ", "),
+		})
+		dataBytes += size.DataBytes
+		indexBytes += size.IndexBytes
+	}
+
+	newState := newBrowseState(TableSizes, headers, data)
+	sortRows(&newState, slices.Index(headers, "TOTAL"), true)
+	newState.StatusText = fmt.Sprintf("%d tables, %s data, %s indexes, %d unused and %d duplicate indexes, o sorts by the column",
+		len(sizes), formatBytes(dataBytes), formatBytes(indexBytes), unusedCount, duplicateCount)
+	if unusedErr != nil {
+		newState.StatusText += ", unused indexes unknown"
+	}
+	return newState, ""
+}
+
+// sortRows orders the rows of a state by a column, numbers and sizes by their value and other cells as text
+func sortRows(state *State, column int, descending bool) {
+	if column < 0 || column >= len(state.TableHeaders) {
+		return
+	}
+	cell := func(item db.TableData) string {
+		values := export.Values(item, state.TableHeaders)
+		if values[column] == nil {
+			return ""
+		}
+		return *values[column]
+	}
+	sort.SliceStable(state.TableData, func(i, j int) bool {
+		a, b := cell(state.TableData[i]), cell(state.TableData[j])
+		if descending {
+			a, b = b, a
+		}
+		x, okX := parseQuantity(a)
+		y, okY := parseQuantity(b)
+		if okX && okY {
+			return x < y
+		}
+		return a < b
+	})
+	state.sortColumn, state.sortDescending = column, descending
+}
+
+// sortByColumn sorts the rows of the current state by a column, largest first, and reverses the order when
+// sorted by the column already
+func (csm *ContextualStateManager) sortByColumn(ctx context.Context, column int) {
+	current := csm.GetCurrentState()
+	if column < 0 || column >= len(current.TableHeaders) {
+		return
+	}
+	descending := true
+	if current.sortColumn == column {
+		descending = !current.sortDescending
+	}
+	// sort a copy, the rows are shared with the state before
+	current.TableData = append([]db.TableData{}, current.TableData...)
+	sortRows(&current, column, descending)
+	current.SelectedDataIndex = 0
+
+	order := "descending"
+	if !descending {
+		order = "ascending"
+	}
+	current.StatusText = fmt.Sprintf("sorted by %s %s", current.TableHeaders[column], order)
+	csm.ReplaceState(ctx, current)
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0B", formatBytes(0))
+	assert.Equal(t, "1023B", formatBytes(1023))
+	assert.Equal(t, "1.5KB", formatBytes(1536))
+	assert.Equal(t, "48.0MB", formatBytes(48<<20))
+	assert.Equal(t, "2.0TB", formatBytes(2<<40))
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		cell     string
+		expected float64
+		ok       bool
+	}{
+		{cell: "120000", expected: 120000, ok: true},
+		{cell: "1.5KB", expected: 1536, ok: true},
+		{cell: "12.0MB (25%)", expected: 12 << 20, ok: true},
+		{cell: "-3", expected: -3, ok: true},
+		{cell: "orders"},
+		{cell: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			value, ok := parseQuantity(tt.cell)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestRedundantIndexes(t *testing.T) {
+	tests := []struct {
+		name     string
+		indexes  []db.SchemaIndex
+		expected map[string]string
+	}{
+		{
+			name: "leading columns of another index",
+			indexes: []db.SchemaIndex{
+				{Name: "idx_user", Columns: []string{"user_id"}},
+				{Name: "idx_user_date", Columns: []string{"user_id", "created_at"}},
+				{Name: "idx_date", Columns: []string{"created_at"}},
+			},
+			expected: map[string]string{"idx_user": "idx_user_date"},
+		},
+		{
+			name: "the first of two equal indexes is kept",
+			indexes: []db.SchemaIndex{
+				{Name: "b_email", Columns: []string{"email"}},
+				{Name: "a_email", Columns: []string{"email"}},
+			},
+			expected: map[string]string{"b_email": "a_email"},
+		},
+		{
+			name: "unique indexes enforce a constraint",
+			indexes: []db.SchemaIndex{
+				{Name: "uq_email", Columns: []string{"email"}, Unique: true},
+				{Name: "idx_email_name", Columns: []string{"email", "name"}},
+				{Name: "idx_email", Columns: []string{"email"}},
+			},
+			expected: map[string]string{"idx_email": "uq_email"},
+		},
+		{
+			name: "primary key covers an index on its columns",
+			indexes: []db.SchemaIndex{
+				{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+				{Name: "uq_id", Columns: []string{"id"}, Unique: true},
+			},
+			expected: map[string]string{"uq_id": "PRIMARY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redundantIndexes(db.SchemaTable{Name: "t", Indexes: tt.indexes}))
+		})
+	}
+}
+
+func TestHandleEventSizesCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "sizes"})
+
+	sizes := stateManager.GetCurrentState()
+	assert.Equal(t, TableSizes, sizes.TableMode)
+	assert.Equal(t, "3 tables, 50.2MB data, 17.1MB indexes, 1 unused and 0 duplicate indexes, o sorts by the column", sizes.StatusText)
+	// largest first
+	assert.Equal(t, map[string]string{
+		"TABLE": "orders", "ROWS": "120000", "DATA": "48.0MB", "INDEX": "16.0MB", "TOTAL": "64.0MB", "FREE": "12.0MB (20%)",
+		"UNUSED INDEXES": "idx_user_id", "DUPLICATE INDEXES": "",
+	}, sizes.TableData[0])
+	tables := func() []string {
+		var names []string
+		for _, row := range stateManager.GetCurrentState().TableData {
+			names = append(names, row.(map[string]string)["TABLE"])
+		}
+		return names
+	}
+	assert.Equal(t, []string{"orders", "users", "products"}, tables())
+
+	// o sorts by the column under the cursor, again reverses
+	sort := func(column int) {
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone), Row: 2, Column: column})
+	}
+	sort(0)
+	assert.Equal(t, []string{"users", "products", "orders"}, tables())
+	assert.Equal(t, "sorted by TABLE descending", stateManager.GetCurrentState().StatusText)
+	sort(0)
+	assert.Equal(t, []string{"orders", "products", "users"}, tables())
+	sort(5)
+	assert.Equal(t, []string{"orders", "users", "products"}, tables())
+	assert.Equal(t, "sorted by FREE descending", stateManager.GetCurrentState().StatusText)
+
+	// rows are sorted in a copy
+	assert.Equal(t, "orders", sizes.TableData[0].(map[string]string)["TABLE"])
+	assert.Equal(t, "products", sizes.TableData[2].(map[string]string)["TABLE"])
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"log/slog"
 	"rel8/db"
+	"rel8/queries"
+	"strings"
 	"sync"
 	"time"
 )
@@ -25,6 +27,30 @@ type ContextualStateManager struct {
 	syncCallbacks []StateChangeCallback
 	maxHistory    int
 	server        db.DatabaseServer
+	// prefix key waiting for its second key, such as y for copy
+	pendingKey rune
+	// receives copied text, set by the view
+	clipboardWriter func(text string)
+	// runs state changes made by background work on the UI goroutine, set by the view
+	updateQueue func(update func())
+	// receives the progress of background work such as imports, set by the view
+	progressListener func(text string)
+	// where saved queries are stored, the user configuration directory when nil
+	queryLibrary *queries.Library
+	// last values bound to placeholders by name, offered again when a query is rerun
+	bindValues map[string]string
+	// results kept after leaving them, and the id given to the last result
+	pinned       []State
+	lastResultID int
+	// connection strings of other databases by profile name, and how to connect to them
+	profiles    map[string]string
+	openProfile func(ctx context.Context, connStr string) (db.DatabaseServer, error)
+	// result whose query is re-run in the background, nil when not watching
+	watch *watchJob
+	// live view such as the process list refreshed while shown, kept apart from the watch the user started
+	liveWatch *watchJob
+	// lag in seconds from which replication is reported as a problem
+	replicationLag int64
 }
 
 func NewContextualStateManager(server db.DatabaseServer, initialState State, maxHistory int) *ContextualStateManager {
@@ -34,7 +60,76 @@ func NewContextualStateManager(server db.DatabaseServer, initialState State, max
 		syncCallbacks: make([]StateChangeCallback, 0),
 		maxHistory:    maxHistory,
 		server:        server,
+		bindValues:    make(map[string]string),
+		openProfile:   db.Open,
+
+		replicationLag: defaultReplicationLag,
+	}
+}
+
+// SetUpdateQueue sets how background work such as imports applies its state changes.
+// Without a queue updates run on the calling goroutine
+func (csm *ContextualStateManager) SetUpdateQueue(queue func(update func())) {
+	csm.updateQueue = queue
+}
+
+// queueUpdate applies a state change made by background work
+func (csm *ContextualStateManager) queueUpdate(update func()) {
+	if csm.updateQueue == nil {
+		update()
+		return
+	}
+	csm.updateQueue(update)
+}
+
+// SetProgressListener sets the function showing the progress of background work, an empty text once it ended
+func (csm *ContextualStateManager) SetProgressListener(listener func(text string)) {
+	csm.mu.Lock()
+	defer csm.mu.Unlock()
+	csm.progressListener = listener
+}
+
+// reportProgress hands the progress of background work to the listener on the UI goroutine
+func (csm *ContextualStateManager) reportProgress(text string) {
+	csm.mu.RLock()
+	listener := csm.progressListener
+	csm.mu.RUnlock()
+	if listener != nil {
+		csm.queueUpdate(func() { listener(text) })
+	}
+}
+
+// setStatusWhere shows the status text of background work on the state that started it, a state that is not on
+// top keeps the text for when it is back
+func (csm *ContextualStateManager) setStatusWhere(owns func(state State) bool, text string) {
+	csm.queueUpdate(func() {
+		current := csm.GetCurrentState()
+		if owns(current) {
+			current.StatusText = text
+			csm.ReplaceState(context.Background(), current)
+			return
+		}
+
+		csm.mu.Lock()
+		defer csm.mu.Unlock()
+		for i := range csm.stateStack {
+			if owns(csm.stateStack[i]) {
+				csm.stateStack[i].StatusText = text
+			}
+		}
+	})
+}
+
+// pushOrReport shows the state created by a command, or the status telling why it couldn't be created on the
+// current state
+func (csm *ContextualStateManager) pushOrReport(ctx context.Context, state State, status string) {
+	if status != "" {
+		current := csm.GetCurrentState()
+		current.StatusText = status
+		csm.ReplaceState(ctx, current)
+		return
 	}
+	csm.PushState(ctx, state)
 }
 
 func (csm *ContextualStateManager) AddCallback(callback StateChangeCallback) {
@@ -60,12 +155,15 @@ func (csm *ContextualStateManager) PushState(ctx context.Context, newState State
 	}
 
 	oldState := csm.stateStack[len(csm.stateStack)-1]
+	csm.numberResultLocked(&newState)
 	csm.stateStack = append(csm.stateStack, newState)
 
 	// Limit history size
 	if len(csm.stateStack) > csm.maxHistory {
 		csm.stateStack = csm.stateStack[1:]
 	}
+	csm.decorateTopLocked()
+	newState = csm.stateStack[len(csm.stateStack)-1]
 
 	// Notify callbacks
 	transition := StateTransition{From: oldState, To: newState}
@@ -99,6 +197,7 @@ func (csm *ContextualStateManager) PopState(ctx context.Context) (State, error)
 
 	currentState := csm.stateStack[len(csm.stateStack)-1]
 	csm.stateStack = csm.stateStack[:len(csm.stateStack)-1]
+	csm.decorateTopLocked()
 	previousState := csm.stateStack[len(csm.stateStack)-1]
 
 	// Notify callbacks
@@ -117,6 +216,36 @@ func (csm *ContextualStateManager) PopState(ctx context.Context) (State, error)
 	return previousState, nil
 }
 
+// ReplaceState swaps the current state in place, without growing history, and notifies callbacks
+func (csm *ContextualStateManager) ReplaceState(ctx context.Context, newState State) error {
+	csm.mu.Lock()
+	defer csm.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	oldState := csm.stateStack[len(csm.stateStack)-1]
+	csm.numberResultLocked(&newState)
+	csm.stateStack[len(csm.stateStack)-1] = newState
+	csm.decorateTopLocked()
+	newState = csm.stateStack[len(csm.stateStack)-1]
+
+	transition := StateTransition{From: oldState, To: newState}
+
+	for _, callback := range csm.syncCallbacks {
+		callback(transition)
+	}
+
+	for _, callback := range csm.callbacks {
+		go callback(transition)
+	}
+
+	return nil
+}
+
 func (csm *ContextualStateManager) GetCurrentState() State {
 	csm.mu.RLock()
 	defer csm.mu.RUnlock()
@@ -151,18 +280,48 @@ func (csm *ContextualStateManager) HandleEvent(ev *Event) *tcell.EventKey {
 
 	// Handle Escape from any mode to go back to previous state
 	if ev.Event.Key() == tcell.KeyEscape {
+		csm.pendingKey = 0
 		csm.PopState(ctx)
 		return nil
 	}
 
+	// The key following y selects what to copy to clipboard, other keys such as arrows are handled as usual
+	if csm.pendingKey == 'y' {
+		csm.pendingKey = 0
+		if ev.Event.Key() == tcell.KeyRune {
+			csm.copyToClipboard(ctx, ev)
+			return nil
+		}
+	}
+	if ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'y' {
+		switch csm.GetCurrentState().Mode {
+		case Browse, Detail, Tabbed:
+			csm.pendingKey = 'y'
+			return nil
+		}
+	}
+
+	// A form takes all keys but Enter, which submits it
+	if csm.GetCurrentState().Mode == Form {
+		if ev.Event.Key() == tcell.KeyEnter {
+			csm.submitForm(ctx, ev.Values)
+			return nil
+		}
+		return ev.Event
+	}
+
 	// If command bar is visible, handle only Enter else return event
 	if csm.GetCurrentState().Mode == Command {
 		switch ev.Event.Key() {
 		case tcell.KeyEnter:
 			slog.Debug("enter in command mode")
 			command := ev.Text
+			args := strings.Fields(command)
+			if len(args) == 0 {
+				return nil
+			}
 			// Process the command
-			switch command {
+			switch args[0] {
 			case "q", "quit":
 				csm.PushState(ctx, *Quit)
 
@@ -183,6 +342,120 @@ func (csm *ContextualStateManager) HandleEvent(ev *Event) *tcell.EventKey {
 					TableHeaders: headers,
 					TableData:    data,
 				})
+
+			case "views":
+				headers, data := csm.server.FetchViews(ctx)
+				csm.PushState(ctx, newBrowseState(DatabaseView, headers, data))
+
+			case "procs", "procedures":
+				headers, data := csm.server.FetchRoutines(ctx, db.ObjectProcedure)
+				csm.PushState(ctx, newBrowseState(DatabaseProcedure, headers, data))
+
+			case "functions", "funcs":
+				headers, data := csm.server.FetchRoutines(ctx, db.ObjectFunction)
+				csm.PushState(ctx, newBrowseState(DatabaseFunction, headers, data))
+
+			case "triggers":
+				headers, data := csm.server.FetchTriggers(ctx)
+				csm.PushState(ctx, newBrowseState(DatabaseTrigger, headers, data))
+
+			case "events":
+				headers, data := csm.server.FetchEvents(ctx)
+				csm.PushState(ctx, newBrowseState(DatabaseEvent, headers, data))
+
+			case "sequences":
+				headers, data := csm.server.FetchSequences(ctx)
+				csm.PushState(ctx, newBrowseState(DatabaseSequence, headers, data))
+
+			case "export":
+				// leave command mode and report the result on the exported grid
+				csm.PopState(ctx)
+				newState := csm.GetCurrentState()
+				newState.StatusText = csm.exportState(&newState, args[1:])
+				csm.ReplaceState(ctx, newState)
+
+			case "queries":
+				// list saved queries
+				csm.PopState(ctx)
+				state, status := csm.createStateWithSavedQueries()
+				csm.pushOrReport(ctx, state, status)
+
+			case "import":
+				// preview the mapping of a file to a table
+				csm.PopState(ctx)
+				state, status := csm.createStateWithImportPreview(args[1:])
+				csm.pushOrReport(ctx, state, status)
+
+			case "diff":
+				// compare the current result with an earlier one
+				csm.PopState(ctx)
+				csm.diffCommand(ctx, args[1:])
+
+			case "proc":
+				// list sessions of the server, refreshed while shown
+				csm.PopState(ctx)
+				csm.showProcesses(ctx, "")
+
+			case "locks":
+				// show sessions waiting for locks under their blockers
+				csm.PopState(ctx)
+				state, status := csm.createStateWithLocks(ctx)
+				csm.pushOrReport(ctx, state, status)
+
+			case "status", "vars":
+				// list status counters or server variables matching a filter
+				csm.PopState(ctx)
+				create := csm.createStateWithStatus
+				if args[0] == "vars" {
+					create = csm.createStateWithVariables
+				}
+				state, status := create(ctx, args[1:])
+				csm.pushOrReport(ctx, state, status)
+
+			case "sizes":
+				// list tables by size with their unused and duplicate indexes
+				csm.PopState(ctx)
+				state, status := csm.createStateWithSizes(ctx)
+				csm.pushOrReport(ctx, state, status)
+
+			case "top":
+				// list the statements taking the most time
+				csm.PopState(ctx)
+				state, status := csm.createStateWithTop(ctx)
+				csm.pushOrReport(ctx, state, status)
+
+			case "users":
+				// list the accounts of the server
+				csm.PopState(ctx)
+				state, status := csm.createStateWithUsers(ctx)
+				csm.pushOrReport(ctx, state, status)
+
+			case "access":
+				// list who can access a table, the browsed one by default
+				csm.PopState(ctx)
+				table, ok := accessTable(csm.GetCurrentState(), args[1:])
+				status := "usage: access <table>"
+				var accessState State
+				if ok {
+					accessState, status = csm.createStateWithTableAccess(ctx, table)
+				}
+				csm.pushOrReport(ctx, accessState, status)
+
+			case "replication":
+				// list the replication channels, refreshed while shown
+				csm.PopState(ctx)
+				state, status := csm.createStateWithReplication(ctx)
+				csm.pushOrReport(ctx, state, status)
+
+			case "watch":
+				// re-run the query of the result below the command bar, or stop watching
+				csm.PopState(ctx)
+				csm.watchCommand(ctx, args[1:])
+
+			case "schemadiff":
+				// compare the schema with another profile in the background
+				csm.PopState(ctx)
+				csm.startSchemaDiff(ctx, args[1:])
 			}
 
 			return nil
@@ -200,8 +473,14 @@ func (csm *ContextualStateManager) HandleEvent(ev *Event) *tcell.EventKey {
 			slog.Debug("enter in SQL mode")
 			SQL := ev.Text
 			slog.Debug("executing SQL query", "query", SQL)
-			newState := csm.createStateWithSqlRows(ctx, SQL)
-			csm.PushState(ctx, newState)
+			csm.bindAndRun(ctx, SQL, func(ctx context.Context, SQL string, args []any) {
+				csm.runInBackground(ctx, "running "+summarizeStatement(SQL), func(ctx context.Context) State {
+					return csm.createStateWithSqlRows(ctx, SQL, args...)
+				})
+			})
+			return nil
+		case tcell.KeyF6, tcell.KeyF30:
+			csm.explain(ctx, ev.Text, isAnalyzeKey(ev.Event))
 			return nil
 		default:
 			// Let command bar handle other keys for SQL input
@@ -210,15 +489,40 @@ func (csm *ContextualStateManager) HandleEvent(ev *Event) *tcell.EventKey {
 		}
 	}
 
-	// If Editor mode is active, handle F5 to execute SQL
+	// If Editor mode is active, F5 executes the statement under the cursor, Ctrl-F5 all statements,
+	// F6 explains the statement under the cursor and Ctrl-S saves the text as a query
 	if csm.GetCurrentState().Mode == Editor {
+		// returning to the editor keeps the text it had
+		csm.updateCurrentEditorText(ev.Text)
 		switch ev.Event.Key() {
-		case tcell.KeyF5:
-			slog.Debug("F5 in editor mode")
-			SQL := ev.Text
-			slog.Debug("executing SQL query from editor", "query", SQL)
-			newState := csm.createStateWithSqlRows(ctx, SQL)
-			csm.PushState(ctx, newState)
+		case tcell.KeyCtrlS:
+			if strings.TrimSpace(ev.Text) != "" {
+				csm.askToSaveQuery(ctx, ev.Text)
+			}
+			return nil
+		case tcell.KeyF5, tcell.KeyF29:
+			statements := db.SplitStatements(ev.Text)
+
+			// terminals report Ctrl-F5 either as a modifier or as F29
+			if ev.Event.Key() == tcell.KeyF29 || ev.Event.Modifiers()&tcell.ModCtrl != 0 {
+				slog.Debug("executing all statements from editor", "count", len(statements))
+				if len(statements) > 0 {
+					csm.runStatements(ctx, statements)
+				}
+				return nil
+			}
+
+			statement, ok := db.StatementAt(statements, ev.Cursor)
+			if !ok {
+				return nil
+			}
+			slog.Debug("executing SQL statement from editor", "query", statement.Text)
+			csm.runStatements(ctx, []db.Statement{statement})
+			return nil
+		case tcell.KeyF6, tcell.KeyF30:
+			if statement, ok := db.StatementAt(db.SplitStatements(ev.Text), ev.Cursor); ok {
+				csm.explain(ctx, statement.Text, isAnalyzeKey(ev.Event))
+			}
 			return nil
 		default:
 			// Let editor handle other keys
@@ -227,8 +531,40 @@ func (csm *ContextualStateManager) HandleEvent(ev *Event) *tcell.EventKey {
 		}
 	}
 
+	// Tab and Shift-Tab switch between tabs in tabbed mode
+	if csm.GetCurrentState().Mode == Tabbed {
+		switch ev.Event.Key() {
+		case tcell.KeyTab:
+			csm.selectTab(ctx, 1)
+			return nil
+		case tcell.KeyBacktab:
+			csm.selectTab(ctx, -1)
+			return nil
+		}
+	}
+
 	// action on row in browse
 	if csm.GetCurrentState().Mode == Browse {
+		// ] and [ switch between query results, p pins one and | shows another beside it
+		if isResult(csm.GetCurrentState()) && ev.Event.Key() == tcell.KeyRune {
+			switch ev.Event.Rune() {
+			case ']', '[':
+				csm.updateCurrentStateSelection(ev.Row - 1)
+				offset := 1
+				if ev.Event.Rune() == '[' {
+					offset = -1
+				}
+				csm.switchResult(ctx, offset)
+				return nil
+			case 'p':
+				csm.togglePin(ctx)
+				return nil
+			case '|':
+				csm.toggleSplit(ctx)
+				return nil
+			}
+		}
+
 		if csm.GetCurrentState().TableMode == DatabaseTable {
 			switch ev.Event.Key() {
 			case tcell.KeyEnter:
@@ -254,9 +590,108 @@ func (csm *ContextualStateManager) HandleEvent(ev *Event) *tcell.EventKey {
 					newState := csm.createStateWithTableDescr(ctx, ev)
 					csm.PushState(ctx, newState)
 					return nil
+				case 'a':
+					// who can access the selected table
+					csm.updateCurrentStateSelection(ev.Row - 1)
+					tableName, err := extractNameFromSelection(csm.GetCurrentState(), ev.Row-1)
+					if err != nil {
+						return nil
+					}
+					state, status := csm.createStateWithTableAccess(ctx, tableName)
+					csm.pushOrReport(ctx, state, status)
+					return nil
+				}
+			}
+		}
+
+		if csm.GetCurrentState().TableMode == SavedQueries {
+			if query, ok := selectedQuery(csm.GetCurrentState(), ev.Row); ok {
+				switch {
+				case ev.Event.Key() == tcell.KeyEnter:
+					csm.updateCurrentStateSelection(ev.Row - 1)
+					csm.runSavedQuery(ctx, query)
+					return nil
+				case ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'e':
+					csm.updateCurrentStateSelection(ev.Row - 1)
+					csm.PushState(ctx, State{Mode: Editor, EditorText: query.SQL})
+					return nil
+				}
+			}
+		}
+
+		if csm.GetCurrentState().TableMode == Processes {
+			if process, ok := selectedProcess(csm.GetCurrentState(), ev.Row); ok {
+				switch {
+				case ev.Event.Key() == tcell.KeyEnter || (ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'd'):
+					csm.updateCurrentStateSelection(ev.Row - 1)
+					csm.PushState(ctx, State{Mode: Detail, DetailText: describeProcess(process)})
+					return nil
+				case ev.Event.Key() == tcell.KeyCtrlK:
+					csm.updateCurrentStateSelection(ev.Row - 1)
+					csm.askToKillProcess(ctx, process)
+					return nil
+				}
+			}
+		}
+
+		if csm.GetCurrentState().TableMode == TopStatements {
+			if digest, ok := selectedDigest(csm.GetCurrentState(), ev.Row); ok {
+				switch {
+				case ev.Event.Key() == tcell.KeyEnter || (ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'd'):
+					csm.updateCurrentStateSelection(ev.Row - 1)
+					csm.PushState(ctx, State{Mode: Detail, DetailText: describeDigest(digest)})
+					return nil
+				case ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'x':
+					csm.updateCurrentStateSelection(ev.Row - 1)
+					csm.explainDigest(ctx, digest)
+					return nil
 				}
 			}
 		}
+
+		if csm.GetCurrentState().TableMode == Users && ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'd' {
+			csm.showGrants(ctx, ev.Row)
+			return nil
+		}
+
+		if csm.GetCurrentState().TableMode == Locks && ev.Event.Key() == tcell.KeyEnter {
+			csm.showBlocker(ctx, ev.Row)
+			return nil
+		}
+
+		if csm.GetCurrentState().TableMode == TableSizes && ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'o' {
+			csm.sortByColumn(ctx, ev.Column)
+			return nil
+		}
+
+		if csm.GetCurrentState().TableMode == SchemaDiff && ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'e' {
+			if script := csm.GetCurrentState().alterScript; script != "" {
+				csm.PushState(ctx, State{Mode: Editor, EditorText: script})
+			}
+			return nil
+		}
+
+		if csm.GetCurrentState().TableMode == ImportPreview && ev.Event.Key() == tcell.KeyEnter {
+			csm.startImport(ctx)
+			return nil
+		}
+
+		if kind, ok := objectKinds[csm.GetCurrentState().TableMode]; ok && len(csm.GetCurrentState().TableData) > 0 {
+			isView := csm.GetCurrentState().TableMode == DatabaseView
+			switch {
+			case isView && (ev.Event.Key() == tcell.KeyEnter || (ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'q')):
+				// views are browsed like tables
+				csm.updateCurrentStateSelection(ev.Row - 1)
+				newState := csm.createStateWithTableRows(ctx, ev)
+				csm.PushState(ctx, newState)
+				return nil
+			case ev.Event.Key() == tcell.KeyEnter || (ev.Event.Key() == tcell.KeyRune && ev.Event.Rune() == 'd'):
+				csm.updateCurrentStateSelection(ev.Row - 1)
+				newState := csm.createStateWithObjectDefinition(ctx, ev, kind)
+				csm.PushState(ctx, newState)
+				return nil
+			}
+		}
 	}
 
 	// Normal key bindings when command bar is not visible
@@ -296,51 +731,113 @@ func (csm *ContextualStateManager) HandleEvent(ev *Event) *tcell.EventKey {
 	}
 }
 
+// newStateForSelection starts the state opened from the selected row of a list. It is built anew rather than
+// copied from the list, so that nothing of the list such as its status carries over
+func newStateForSelection(mode Mode, tableMode TableMode, ev *Event) State {
+	return State{Mode: mode, TableMode: tableMode, SelectedDataIndex: ev.Row - 1}
+}
+
 func (csm *ContextualStateManager) createStateWithTableRows(ctx context.Context, ev *Event) State {
 	slog.Debug("row", "row", ev.Row)
 
-	// this creates a shallow copy
-	newState := csm.GetCurrentState()
-
-	newState.SelectedDataIndex = ev.Row - 1
+	newState := newStateForSelection(Browse, TableRow, ev)
 	tableName, _ := extractNameFromSelection(csm.GetCurrentState(), ev.Row-1)
 	// Fetch table rows using the extracted table name
 	headers, data := csm.server.FetchTableRows(ctx, tableName)
-	newState.TableMode = TableRow
 	newState.TableHeaders = headers
 	newState.TableData = data
+	newState.Query = fmt.Sprintf("SELECT * FROM `%s`", tableName)
+	newState.SourceTable = tableName
 
 	return newState
 }
 
-func (csm *ContextualStateManager) createStateWithSqlRows(ctx context.Context, SQL string) State {
+func (csm *ContextualStateManager) createStateWithSqlRows(ctx context.Context, SQL string, args ...any) State {
 	newState := State{
 		Mode: Browse,
 	}
 
 	// Fetch SQL rows using the extracted table name
-	headers, data := csm.server.FetchSqlRows(ctx, SQL)
+	headers, data := csm.server.FetchSqlRows(ctx, SQL, args...)
 	newState.TableMode = TableRow
 	newState.TableHeaders = headers
 	newState.TableData = data
+	newState.Query = SQL
+	newState.QueryArgs = args
 
 	return newState
 }
 
 func (csm *ContextualStateManager) createStateWithTableDescr(ctx context.Context, ev *Event) State {
 	slog.Debug("row", "row", ev.Row)
-	// this creates a shallow copy
-	newState := csm.GetCurrentState()
-
-	newState.SelectedDataIndex = ev.Row - 1
-	tableName, _ := extractNameFromSelection(csm.GetCurrentState(), ev.Row-1)
-	// Fetch table description using the extracted table name
-	newState.Mode = Detail
+	current := csm.GetCurrentState()
+	newState := newStateForSelection(Tabbed, current.TableMode, ev)
+	tableName, _ := extractNameFromSelection(current, ev.Row-1)
+	// Fetch structured table description using the extracted table name
 	newState.DetailText = csm.server.FetchTableDescr(ctx, tableName)
 
+	schemaFetchers := []struct {
+		title string
+		fetch func(ctx context.Context, name string) ([]string, []db.TableData)
+	}{
+		{"Columns", csm.server.FetchTableColumns},
+		{"Indexes", csm.server.FetchTableIndexes},
+		{"Foreign Keys", csm.server.FetchTableForeignKeys},
+		{"Checks", csm.server.FetchTableChecks},
+		{"Triggers", csm.server.FetchTableTriggers},
+	}
+	for _, fetcher := range schemaFetchers {
+		headers, data := fetcher.fetch(ctx, tableName)
+		newState.Tabs = append(newState.Tabs, Tab{Title: fetcher.title, TableHeaders: headers, TableData: data})
+	}
+	newState.Tabs = append(newState.Tabs, Tab{Title: "DDL", DetailText: newState.DetailText})
+
 	return newState
 }
 
+func (csm *ContextualStateManager) createStateWithObjectDefinition(ctx context.Context, ev *Event, kind string) State {
+	current := csm.GetCurrentState()
+	newState := newStateForSelection(Detail, current.TableMode, ev)
+	objectName, _ := extractNameFromSelection(current, ev.Row-1)
+	newState.DetailText = csm.server.FetchObjectDefinition(ctx, kind, objectName)
+	// views, and routines created on one line, come back as a single long line
+	if !strings.Contains(newState.DetailText, "\n") {
+		newState.DetailText = db.PrettyPrintSQL(newState.DetailText, db.FormatOptions{})
+	}
+
+	return newState
+}
+
+// newBrowseState creates a browse state listing rows of the given table mode
+func newBrowseState(tableMode TableMode, headers []string, data []db.TableData) State {
+	return State{
+		Mode:         Browse,
+		TableMode:    tableMode,
+		TableHeaders: headers,
+		TableData:    data,
+	}
+}
+
+// selectTab moves tab selection of the current state by delta, wrapping around
+func (csm *ContextualStateManager) selectTab(ctx context.Context, delta int) {
+	newState := csm.GetCurrentState()
+	if len(newState.Tabs) == 0 {
+		return
+	}
+	newState.SelectedTab = (newState.SelectedTab + delta + len(newState.Tabs)) % len(newState.Tabs)
+	csm.ReplaceState(ctx, newState)
+}
+
+// updateCurrentEditorText keeps the editor text in the current state without a transition
+func (csm *ContextualStateManager) updateCurrentEditorText(text string) {
+	csm.mu.Lock()
+	defer csm.mu.Unlock()
+
+	if len(csm.stateStack) > 0 {
+		csm.stateStack[len(csm.stateStack)-1].EditorText = text
+	}
+}
+
 func (csm *ContextualStateManager) updateCurrentStateSelection(selectedIndex int) {
 	csm.mu.Lock()
 	defer csm.mu.Unlock()
@@ -353,11 +850,23 @@ func (csm *ContextualStateManager) updateCurrentStateSelection(selectedIndex int
 
 // attempt to extract object name such as table name from selected row in table data
 func extractNameFromSelection(state State, selected int) (string, error) {
-	selectedTable := state.TableData[selected]
-
-	if mysqlTable, ok := selectedTable.(db.MysqlTable); ok {
-		return mysqlTable.Name, nil
-	} else {
-		return "", errors.New("failed to cast selected row to db.MysqlTable")
+	if selected < 0 || selected >= len(state.TableData) {
+		return "", errors.New("no row selected")
+	}
+	switch selectedObject := state.TableData[selected].(type) {
+	case db.MysqlTable:
+		return selectedObject.Name, nil
+	case db.MysqlView:
+		return selectedObject.Name, nil
+	case db.MysqlRoutine:
+		return selectedObject.Name, nil
+	case db.MysqlTriggerSummary:
+		return selectedObject.Name, nil
+	case db.MysqlEvent:
+		return selectedObject.Name, nil
+	case db.MysqlSequence:
+		return selectedObject.Name, nil
+	default:
+		return "", errors.New("failed to extract object name from selected row")
 	}
 }
@@ -0,0 +1,261 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"rel8/db"
+)
+
+const (
+	// statusRefreshInterval is how often the status counters are read again in delta mode
+	statusRefreshInterval = 2 * time.Second
+	// metricsInterval is how often the header metrics are read
+	metricsInterval = 5 * time.Second
+)
+
+const statusUsage = "usage: status [--delta] [filter]"
+
+// ServerMetrics are the rates of the server between two reads of its status, shown in the header
+type ServerMetrics struct {
+	// QPS counts statements a second, transactions a second on Postgres
+	QPS float64
+	// ThreadsRunning counts the sessions running a statement
+	ThreadsRunning string
+	// BufferHitRate is the percentage of page reads served from the buffer pool, -1 when nothing was read
+	BufferHitRate float64
+}
+
+// describe sums up the metrics for a status text
+func (m ServerMetrics) describe() string {
+	text := fmt.Sprintf("QPS %.1f, %s threads running", m.QPS, m.ThreadsRunning)
+	if m.BufferHitRate >= 0 {
+		text += fmt.Sprintf(", buffer pool hit rate %.1f%%", m.BufferHitRate)
+	}
+	return text
+}
+
+// metricCounters name the status counters the metrics are computed from
+type metricCounters struct {
+	queries []string
+	running string
+	// reads missed the buffer pool out of all requests
+	reads    string
+	requests []string
+}
+
+var statusMetrics = map[string]metricCounters{
+	db.DialectMysql: {
+		queries: []string{"Queries"}, running: "Threads_running",
+		reads: "Innodb_buffer_pool_reads", requests: []string{"Innodb_buffer_pool_read_requests"},
+	},
+	db.DialectPostgres: {
+		queries: []string{"xact_commit", "xact_rollback"}, running: "active_sessions",
+		reads: "blks_read", requests: []string{"blks_hit", "blks_read"},
+	},
+}
+
+// statusSample is the status of the server read at a time
+type statusSample struct {
+	values []db.ServerValue
+	byName map[string]string
+	at     time.Time
+}
+
+// statusView is how a status grid is shown and the status its rows were read from
+type statusView struct {
+	filter string
+	delta  bool
+	sample statusSample
+}
+
+// newStatusSample indexes status values by name
+func newStatusSample(values []db.ServerValue, at time.Time) statusSample {
+	byName := make(map[string]string, len(values))
+	for _, value := range values {
+		byName[value.Name] = value.Value
+	}
+	return statusSample{values: values, byName: byName, at: at}
+}
+
+// counter sums counters of a sample, it reports false when one is missing or no number
+func (s statusSample) counter(names ...string) (float64, bool) {
+	sum := 0.0
+	for _, name := range names {
+		value, err := strconv.ParseFloat(s.byName[name], 64)
+		if err != nil {
+			return 0, false
+		}
+		sum += value
+	}
+	return sum, true
+}
+
+// metricsBetween computes the rates between two samples, it reports false when the dialect has no metrics
+// or the samples can't be compared
+func metricsBetween(dialect string, before statusSample, after statusSample) (ServerMetrics, bool) {
+	counters, ok := statusMetrics[dialect]
+	seconds := after.at.Sub(before.at).Seconds()
+	if !ok || seconds <= 0 {
+		return ServerMetrics{}, false
+	}
+	queriesBefore, ok1 := before.counter(counters.queries...)
+	queriesAfter, ok2 := after.counter(counters.queries...)
+	if !ok1 || !ok2 {
+		return ServerMetrics{}, false
+	}
+
+	metrics := ServerMetrics{QPS: (queriesAfter - queriesBefore) / seconds, ThreadsRunning: after.byName[counters.running], BufferHitRate: -1}
+	readsBefore, ok1 := before.counter(counters.reads)
+	readsAfter, ok2 := after.counter(counters.reads)
+	requestsBefore, ok3 := before.counter(counters.requests...)
+	requestsAfter, ok4 := after.counter(counters.requests...)
+	if ok1 && ok2 && ok3 && ok4 && requestsAfter > requestsBefore {
+		metrics.BufferHitRate = 100 * (1 - (readsAfter-readsBefore)/(requestsAfter-requestsBefore))
+	}
+	return metrics, true
+}
+
+// matchesFilter reports whether a name contains the filter, ignoring case
+func matchesFilter(name string, filter string) bool {
+	return strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
+
+// statusRows lists the counters of a sample matching the filter. In delta mode the change since the sample
+// before and the change per second follow, blank for the first sample and for values that are no number
+func statusRows(before *statusSample, after statusSample, filter string, delta bool) ([]string, []db.TableData) {
+	headers := []string{"NAME", "VALUE"}
+	if delta {
+		headers = append(headers, "DELTA", "PER SEC")
+	}
+	data := []db.TableData{}
+	for _, value := range after.values {
+		if !matchesFilter(value.Name, filter) {
+			continue
+		}
+		row := map[string]string{"NAME": value.Name, "VALUE": value.Value}
+		if delta && before != nil {
+			old, ok1 := before.counter(value.Name)
+			now, ok2 := after.counter(value.Name)
+			if seconds := after.at.Sub(before.at).Seconds(); ok1 && ok2 && seconds > 0 {
+				row["DELTA"] = strconv.FormatFloat(now-old, 'f', -1, 64)
+				row["PER SEC"] = strconv.FormatFloat((now-old)/seconds, 'f', 1, 64)
+			}
+		}
+		data = append(data, row)
+	}
+	return headers, data
+}
+
+// createStateWithStatus lists the global status counters matching a filter. With --delta the list refreshes
+// and shows how much each counter grew per second, the grid title sums up the main rates.
+// On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithStatus(ctx context.Context, args []string) (State, string) {
+	view := &statusView{}
+	for _, arg := range args {
+		switch {
+		case arg == "--delta":
+			view.delta = true
+		case strings.HasPrefix(arg, "--") || view.filter != "":
+			return State{}, statusUsage
+		default:
+			view.filter = arg
+		}
+	}
+
+	values, err := csm.server.FetchStatus(ctx)
+	if err != nil {
+		slog.Error("reading status failed", "error", err)
+		return State{}, fmt.Sprintf("reading status failed: %v", err)
+	}
+	view.sample = newStatusSample(values, time.Now())
+
+	headers, data := statusRows(nil, view.sample, view.filter, view.delta)
+	newState := newBrowseState(ServerStatus, headers, data)
+	newState.status = view
+	newState.StatusText = fmt.Sprintf("%d status counters", len(data))
+	if view.filter != "" {
+		newState.StatusText += " matching " + view.filter
+	}
+	if view.delta {
+		newState.WatchInterval = statusRefreshInterval
+		newState.StatusText += fmt.Sprintf(", rates follow every %s", statusRefreshInterval)
+		csm.startLiveWatch(newState, statusRefreshInterval)
+	}
+	return newState, ""
+}
+
+// createStateWithVariables lists the server variables matching a filter, on failure it returns
+// a status text instead
+func (csm *ContextualStateManager) createStateWithVariables(ctx context.Context, args []string) (State, string) {
+	if len(args) > 1 {
+		return State{}, "usage: vars [filter]"
+	}
+	filter := ""
+	if len(args) == 1 {
+		filter = args[0]
+	}
+
+	values, err := csm.server.FetchVariables(ctx)
+	if err != nil {
+		slog.Error("reading variables failed", "error", err)
+		return State{}, fmt.Sprintf("reading variables failed: %v", err)
+	}
+	data := []db.TableData{}
+	for _, value := range values {
+		if matchesFilter(value.Name, filter) {
+			data = append(data, map[string]string{"NAME": value.Name, "VALUE": value.Value})
+		}
+	}
+
+	newState := newBrowseState(ServerVariables, []string{"NAME", "VALUE"}, data)
+	newState.StatusText = fmt.Sprintf("%d variables", len(data))
+	if filter != "" {
+		newState.StatusText += " matching " + filter
+	}
+	return newState, ""
+}
+
+// MonitorMetrics reads the status of the server every few seconds and hands the rates since the read before
+// to listener on the UI goroutine, until stop is called. Databases without metrics, such as SQLite, are not read
+func (csm *ContextualStateManager) MonitorMetrics(listener func(metrics ServerMetrics)) (stop func()) {
+	done := make(chan struct{})
+	stop = func() { close(done) }
+	dialect := csm.server.Dialect()
+	if _, ok := statusMetrics[dialect]; !ok {
+		return stop
+	}
+
+	go func() {
+		ticker := time.NewTicker(metricsInterval)
+		defer ticker.Stop()
+		var before *statusSample
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), metricsInterval)
+			values, err := csm.server.FetchStatus(ctx)
+			cancel()
+			if err != nil {
+				slog.Debug("reading metrics failed", "error", err)
+			} else {
+				after := newStatusSample(values, time.Now())
+				if before != nil {
+					if metrics, ok := metricsBetween(dialect, *before, after); ok {
+						csm.queueUpdate(func() { listener(metrics) })
+					}
+				}
+				before = &after
+			}
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return stop
+}
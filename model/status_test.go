@@ -0,0 +1,184 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+// statusServer counts 10 more queries on every read of the status
+type statusServer struct {
+	db.MysqlMock
+	reads int
+	err   error
+}
+
+func (s *statusServer) FetchStatus(ctx context.Context) ([]db.ServerValue, error) {
+	s.reads++
+	return []db.ServerValue{
+		{Name: "Queries", Value: fmt.Sprint(s.reads * 10)},
+		{Name: "Threads_running", Value: "2"},
+		{Name: "Uptime_since_flush_status", Value: "ON"},
+	}, s.err
+}
+
+func TestMetricsBetween(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sample := func(at time.Duration, values ...string) statusSample {
+		var status []db.ServerValue
+		for i := 0; i < len(values); i += 2 {
+			status = append(status, db.ServerValue{Name: values[i], Value: values[i+1]})
+		}
+		return newStatusSample(status, start.Add(at))
+	}
+
+	tests := []struct {
+		name     string
+		dialect  string
+		before   statusSample
+		after    statusSample
+		expected ServerMetrics
+		ok       bool
+	}{
+		{
+			name:    "mysql",
+			dialect: db.DialectMysql,
+			before:  sample(0, "Queries", "100", "Innodb_buffer_pool_read_requests", "1000", "Innodb_buffer_pool_reads", "10"),
+			after: sample(2*time.Second, "Queries", "150", "Threads_running", "4",
+				"Innodb_buffer_pool_read_requests", "1400", "Innodb_buffer_pool_reads", "14"),
+			expected: ServerMetrics{QPS: 25, ThreadsRunning: "4", BufferHitRate: 99},
+			ok:       true,
+		},
+		{
+			name:    "postgres counts transactions and hits with reads",
+			dialect: db.DialectPostgres,
+			before:  sample(0, "xact_commit", "10", "xact_rollback", "0", "blks_hit", "90", "blks_read", "10"),
+			after: sample(time.Second, "xact_commit", "18", "xact_rollback", "2", "active_sessions", "1",
+				"blks_hit", "170", "blks_read", "30"),
+			expected: ServerMetrics{QPS: 10, ThreadsRunning: "1", BufferHitRate: 80},
+			ok:       true,
+		},
+		{
+			name:     "nothing read from the buffer pool",
+			dialect:  db.DialectMysql,
+			before:   sample(0, "Queries", "1"),
+			after:    sample(time.Second, "Queries", "2", "Threads_running", "1"),
+			expected: ServerMetrics{QPS: 1, ThreadsRunning: "1", BufferHitRate: -1},
+			ok:       true,
+		},
+		{
+			name:    "sqlite has no metrics",
+			dialect: db.DialectSqlite,
+			before:  sample(0, "page_count", "1"),
+			after:   sample(time.Second, "page_count", "2"),
+		},
+		{
+			name:    "samples at the same time",
+			dialect: db.DialectMysql,
+			before:  sample(0, "Queries", "1"),
+			after:   sample(0, "Queries", "2"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, ok := metricsBetween(tt.dialect, tt.before, tt.after)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected.QPS, metrics.QPS)
+				assert.Equal(t, tt.expected.ThreadsRunning, metrics.ThreadsRunning)
+				assert.InDelta(t, tt.expected.BufferHitRate, metrics.BufferHitRate, 0.001)
+			}
+		})
+	}
+}
+
+func TestStatusRows(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := newStatusSample([]db.ServerValue{{Name: "Queries", Value: "100"}, {Name: "Ssl_version", Value: ""}}, start)
+	after := newStatusSample([]db.ServerValue{
+		{Name: "Queries", Value: "130"}, {Name: "Ssl_version", Value: "TLSv1.3"}, {Name: "Threads_running", Value: "2"},
+	}, start.Add(2*time.Second))
+
+	headers, data := statusRows(&before, after, "", true)
+	assert.Equal(t, []string{"NAME", "VALUE", "DELTA", "PER SEC"}, headers)
+	assert.Equal(t, []db.TableData{
+		map[string]string{"NAME": "Queries", "VALUE": "130", "DELTA": "30", "PER SEC": "15.0"},
+		map[string]string{"NAME": "Ssl_version", "VALUE": "TLSv1.3"},
+		map[string]string{"NAME": "Threads_running", "VALUE": "2"},
+	}, data)
+
+	headers, data = statusRows(nil, after, "QUER", false)
+	assert.Equal(t, []string{"NAME", "VALUE"}, headers)
+	assert.Equal(t, []db.TableData{map[string]string{"NAME": "Queries", "VALUE": "130"}}, data)
+}
+
+func TestHandleEventStatusCommand(t *testing.T) {
+	server := &statusServer{}
+	stateManager := NewContextualStateManager(server, *Initial, 10)
+	ctx := context.Background()
+	command := func(text string) State {
+		stateManager.PushState(ctx, State{Mode: Command})
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: text})
+		return stateManager.GetCurrentState()
+	}
+
+	assert.Equal(t, statusUsage, command("status --rates").StatusText)
+	assert.Equal(t, statusUsage, command("status threads queries").StatusText)
+
+	filtered := command("status threads")
+	assert.Equal(t, ServerStatus, filtered.TableMode)
+	assert.Equal(t, []db.TableData{map[string]string{"NAME": "Threads_running", "VALUE": "2"}}, filtered.TableData)
+	assert.Equal(t, "1 status counters matching threads", filtered.StatusText)
+	assert.Zero(t, filtered.WatchInterval)
+
+	// delta mode refreshes with the change since the read before
+	delta := command("status --delta queries")
+	assert.Equal(t, []string{"NAME", "VALUE", "DELTA", "PER SEC"}, delta.TableHeaders)
+	assert.Equal(t, statusRefreshInterval, delta.WatchInterval)
+	assert.Equal(t, "1 status counters matching queries, rates follow every 2s", delta.StatusText)
+	job := stateManager.liveWatch
+	assert.True(t, stateManager.refreshWatched(job))
+	refreshed := stateManager.GetCurrentState()
+	assert.Equal(t, "30", refreshed.TableData[0].(map[string]string)["VALUE"])
+	assert.Equal(t, "10", refreshed.TableData[0].(map[string]string)["DELTA"])
+	assert.Regexp(t, `^QPS \d+\.\d, 2 threads running, watching every 2s, refreshed `, refreshed.StatusText)
+	stateManager.stopLiveWatch()
+
+	server.err = errors.New("access denied")
+	stateManager.PopState(ctx)
+	assert.Equal(t, "reading status failed: access denied", command("status").StatusText)
+}
+
+func TestHandleEventVarsCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	command := func(text string) State {
+		stateManager.PushState(ctx, State{Mode: Command})
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: text})
+		return stateManager.GetCurrentState()
+	}
+
+	all := command("vars")
+	assert.Equal(t, ServerVariables, all.TableMode)
+	assert.Equal(t, "4 variables", all.StatusText)
+
+	filtered := command("vars MAX_")
+	assert.Equal(t, []db.TableData{map[string]string{"NAME": "max_connections", "VALUE": "151"}}, filtered.TableData)
+	assert.Equal(t, "1 variables matching MAX_", filtered.StatusText)
+	assert.Equal(t, "usage: vars [filter]", command("vars a b").StatusText)
+}
+
+func TestMonitorMetricsWithoutMetrics(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.Mysql8{Mysql: db.Mysql{DriverName: "sqlite3"}}, *Initial, 10)
+	stop := stateManager.MonitorMetrics(func(metrics ServerMetrics) {
+		t.Error("sqlite has no metrics")
+	})
+	stop()
+}
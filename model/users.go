@@ -0,0 +1,102 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"rel8/db"
+)
+
+// createStateWithUsers lists the accounts of the server, on failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithUsers(ctx context.Context) (State, string) {
+	users, err := csm.server.FetchUsers(ctx)
+	if err != nil {
+		slog.Error("reading users failed", "error", err)
+		return State{}, fmt.Sprintf("reading users failed: %v", err)
+	}
+
+	data := make([]db.TableData, 0, len(users))
+	for _, user := range users {
+		data = append(data, user)
+	}
+	newState := newBrowseState(Users, []string{"USER", "HOST", "ATTRIBUTES"}, data)
+	newState.StatusText = fmt.Sprintf("%s, d shows the grants", pluralize(len(users), "user"))
+	return newState, ""
+}
+
+// showGrants shows the grants of the user of a grid row
+func (csm *ContextualStateManager) showGrants(ctx context.Context, row int) {
+	current := csm.GetCurrentState()
+	if row < 1 || row > len(current.TableData) {
+		return
+	}
+	user, ok := current.TableData[row-1].(db.DatabaseUser)
+	if !ok {
+		return
+	}
+	csm.updateCurrentStateSelection(row - 1)
+
+	grants, err := csm.server.FetchGrants(ctx, user)
+	if err != nil {
+		slog.Error("reading grants failed", "user", user.Name, "error", err)
+		current = csm.GetCurrentState()
+		current.StatusText = fmt.Sprintf("reading grants failed: %v", err)
+		csm.ReplaceState(ctx, current)
+		return
+	}
+
+	name := user.Name
+	if user.Host != "" {
+		name += "@" + user.Host
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "grants of %s\n\n", name)
+	if len(grants) == 0 {
+		b.WriteString("no grants\n")
+	}
+	for _, grant := range grants {
+		b.WriteString(grant + ";\n")
+	}
+	csm.PushState(ctx, State{Mode: Detail, DetailText: b.String()})
+}
+
+// createStateWithTableAccess lists who holds privileges on a table, one row per grantee and where the
+// privileges come from. On failure it returns a status text instead
+func (csm *ContextualStateManager) createStateWithTableAccess(ctx context.Context, table string) (State, string) {
+	access, err := csm.server.FetchTableAccess(ctx, table)
+	if err != nil {
+		slog.Error("reading table access failed", "table", table, "error", err)
+		return State{}, fmt.Sprintf("reading access to %s failed: %v", table, err)
+	}
+
+	data := []db.TableData{}
+	grantees := map[string]bool{}
+	var last map[string]string
+	for _, privilege := range access {
+		grantees[privilege.Grantee] = true
+		// privileges are sorted by grantee and where they come from
+		if last != nil && last["GRANTEE"] == privilege.Grantee && last["VIA"] == privilege.Via {
+			last["PRIVILEGES"] += ", " + privilege.Privilege
+			continue
+		}
+		last = map[string]string{"GRANTEE": privilege.Grantee, "PRIVILEGES": privilege.Privilege, "VIA": privilege.Via}
+		data = append(data, last)
+	}
+
+	newState := newBrowseState(TableAccess, []string{"GRANTEE", "PRIVILEGES", "VIA"}, data)
+	newState.StatusText = fmt.Sprintf("%s can access %s", pluralize(len(grantees), "grantee"), table)
+	return newState, ""
+}
+
+// accessTable names the table of :access, the argument or the browsed table
+func accessTable(state State, args []string) (string, bool) {
+	switch {
+	case len(args) == 1:
+		return args[0], true
+	case len(args) == 0 && state.SourceTable != "":
+		return state.SourceTable, true
+	}
+	return "", false
+}
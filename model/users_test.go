@@ -0,0 +1,70 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestHandleEventUsersCommand(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	stateManager.PushState(ctx, State{Mode: Command})
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: "users"})
+
+	users := stateManager.GetCurrentState()
+	assert.Equal(t, Users, users.TableMode)
+	assert.Equal(t, []string{"USER", "HOST", "ATTRIBUTES"}, users.TableHeaders)
+	assert.Equal(t, db.DatabaseUser{Name: "report", Host: "10.0.0.%", Attributes: "LOCKED"}, users.TableData[1])
+	assert.Equal(t, "3 users, d shows the grants", users.StatusText)
+
+	// d shows the grants of the selected user
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone), Row: 2})
+	grants := stateManager.GetCurrentState()
+	assert.Equal(t, Detail, grants.Mode)
+	assert.Equal(t, "grants of report@10.0.0.%\n\nGRANT USAGE ON *.* TO `report`@`10.0.0.%`;\n"+
+		"GRANT SELECT ON `shop`.`orders` TO `report`@`10.0.0.%`;\n", grants.DetailText)
+	stateManager.PopState(ctx)
+	assert.Equal(t, 1, stateManager.GetCurrentState().SelectedDataIndex)
+}
+
+func TestHandleEventTableAccess(t *testing.T) {
+	stateManager := NewContextualStateManager(&db.MysqlMock{}, *Initial, 10)
+	ctx := context.Background()
+	command := func(text string) State {
+		stateManager.PushState(ctx, State{Mode: Command})
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: text})
+		return stateManager.GetCurrentState()
+	}
+
+	assert.Equal(t, "usage: access <table>", command("access").StatusText)
+	assert.Equal(t, "2 grantees can access users", command("access users").StatusText)
+	stateManager.PopState(ctx)
+
+	// a in the table list looks up the selected table
+	stateManager.PushState(ctx, newBrowseState(DatabaseTable, []string{"NAME", "TYPE", "ENGINE", "ROWS", "SIZE"},
+		[]db.TableData{db.MysqlTable{Name: "users"}, db.MysqlTable{Name: "orders"}}))
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), Row: 2})
+
+	access := stateManager.GetCurrentState()
+	assert.Equal(t, TableAccess, access.TableMode)
+	assert.Equal(t, "3 grantees can access orders", access.StatusText)
+	assert.Equal(t, []db.TableData{
+		map[string]string{"GRANTEE": "'app'@'%'", "PRIVILEGES": "DELETE, INSERT, SELECT, UPDATE", "VIA": "database"},
+		map[string]string{"GRANTEE": "'report'@'10.0.0.%'", "PRIVILEGES": "SELECT", "VIA": "table"},
+		map[string]string{"GRANTEE": "'root'@'localhost'", "PRIVILEGES": "ALTER, DELETE, DROP, INSERT, SELECT, UPDATE", "VIA": "global"},
+	}, access.TableData)
+
+	// an empty table list has nothing to look up
+	empty := newBrowseState(DatabaseTable, []string{"NAME", "TYPE", "ENGINE", "ROWS", "SIZE"}, nil)
+	stateManager.PushState(ctx, empty)
+	stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), Row: 1})
+	assert.Equal(t, DatabaseTable, stateManager.GetCurrentState().TableMode)
+
+	// the browsed table is the default
+	stateManager.PushState(ctx, State{Mode: Browse, TableMode: TableRow, SourceTable: "orders", Query: "SELECT * FROM `orders`"})
+	assert.Equal(t, "3 grantees can access orders", command("access").StatusText)
+}
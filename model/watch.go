@@ -0,0 +1,262 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"rel8/db"
+)
+
+const (
+	// minWatchInterval keeps a watch from running a query back to back
+	minWatchInterval = time.Second
+	// watchTimeout bounds a single run of a watched query
+	watchTimeout = 30 * time.Second
+)
+
+const watchUsage = "usage: watch <interval such as 5s, at least 1s>|off"
+
+// watchJob re-reads the rows of a state in the background until stopped
+type watchJob struct {
+	interval time.Duration
+	stop     chan struct{}
+	// watches reports whether a state is the watched one
+	watches func(state State) bool
+	// fetch reads the rows of the watched state again
+	fetch func(ctx context.Context, state State) ([]string, []db.TableData)
+	// describe sums up the last refresh for the grid title, nil when there is nothing to add
+	describe func() string
+}
+
+// newWatchJob watches a query result or a live list such as the process list,
+// it reports false for other states
+func (csm *ContextualStateManager) newWatchJob(state State, interval time.Duration) (*watchJob, bool) {
+	job := &watchJob{interval: interval, stop: make(chan struct{})}
+	switch {
+	case isResult(state):
+		resultID := state.resultID
+		job.watches = func(state State) bool { return state.resultID == resultID }
+		job.fetch = func(ctx context.Context, state State) ([]string, []db.TableData) {
+			return csm.server.FetchSqlRows(ctx, state.Query, state.QueryArgs...)
+		}
+	case state.Mode == Browse && state.TableMode == Processes:
+		job.watches = func(state State) bool { return state.Mode == Browse && state.TableMode == Processes }
+		job.fetch = func(ctx context.Context, state State) ([]string, []db.TableData) {
+			return csm.server.FetchProcesses(ctx)
+		}
+	case state.Mode == Browse && state.TableMode == Replication:
+		job.watches = func(state State) bool { return state.Mode == Browse && state.TableMode == Replication }
+		job.fetch = func(ctx context.Context, state State) ([]string, []db.TableData) {
+			links, err := csm.server.FetchReplication(ctx)
+			if err != nil {
+				slog.Error("reading replication failed", "error", err)
+				return state.TableHeaders, state.TableData
+			}
+			return replicationRows(links)
+		}
+	case state.Mode == Browse && state.TableMode == ServerStatus && state.status != nil:
+		view := *state.status
+		var metrics string
+		job.watches = func(state State) bool { return state.Mode == Browse && state.TableMode == ServerStatus }
+		job.fetch = func(ctx context.Context, state State) ([]string, []db.TableData) {
+			values, err := csm.server.FetchStatus(ctx)
+			if err != nil {
+				slog.Error("reading status failed", "error", err)
+				return state.TableHeaders, state.TableData
+			}
+			before := view.sample
+			view.sample = newStatusSample(values, time.Now())
+			if rates, ok := metricsBetween(csm.server.Dialect(), before, view.sample); ok {
+				metrics = rates.describe()
+			}
+			return statusRows(&before, view.sample, view.filter, view.delta)
+		}
+		job.describe = func() string { return metrics }
+	default:
+		return nil, false
+	}
+	return job, true
+}
+
+// watchCommand starts re-reading the rows of the current result every interval, replacing another watch,
+// or stops watching with off
+func (csm *ContextualStateManager) watchCommand(ctx context.Context, args []string) {
+	current := csm.GetCurrentState()
+	switch {
+	case len(args) == 1 && args[0] == "off":
+		stopped := csm.stopWatch()
+		if csm.liveWatches(current) {
+			stopped = csm.stopLiveWatch()
+		}
+		if stopped == nil {
+			current.StatusText = "not watching"
+		} else {
+			current.StatusText = "stopped watching"
+		}
+		current.WatchInterval = 0
+	case len(args) != 1:
+		current.StatusText = watchUsage
+	default:
+		interval, err := parseInterval(args[0])
+		if err != nil || interval < minWatchInterval {
+			current.StatusText = watchUsage
+			break
+		}
+		job, ok := csm.newWatchJob(current, interval)
+		if !ok {
+			current.StatusText = "watch re-runs query results, the process list and the status, run a query first"
+			break
+		}
+		// the interval of the user replaces the refresh of a live view
+		if csm.liveWatches(current) {
+			csm.stopLiveWatch()
+		}
+		csm.startWatch(job)
+		current.WatchInterval = interval
+		current.StatusText = fmt.Sprintf("watching every %s", interval)
+	}
+	csm.ReplaceState(ctx, current)
+}
+
+// startWatch runs the watch the user started in the background, stopping the one before
+func (csm *ContextualStateManager) startWatch(job *watchJob) {
+	csm.stopWatch()
+	csm.mu.Lock()
+	csm.watch = job
+	csm.mu.Unlock()
+	go csm.runWatch(job)
+}
+
+// startLiveWatch refreshes a live view such as the process list every interval while it is shown, replacing the
+// refresh of the live view before. The watch of the user keeps running, and wins when it watches the same view
+func (csm *ContextualStateManager) startLiveWatch(state State, interval time.Duration) {
+	job, ok := csm.newWatchJob(state, interval)
+	if !ok {
+		return
+	}
+	csm.stopLiveWatch()
+	csm.mu.Lock()
+	defer csm.mu.Unlock()
+	if csm.watch != nil && csm.watch.watches(state) {
+		return
+	}
+	csm.liveWatch = job
+	go csm.runWatch(job)
+}
+
+// liveWatches reports whether the live view refresh watches a state
+func (csm *ContextualStateManager) liveWatches(state State) bool {
+	csm.mu.RLock()
+	defer csm.mu.RUnlock()
+	return csm.liveWatch != nil && csm.liveWatch.watches(state)
+}
+
+// parseInterval reads a duration such as 5s or 1m, a plain number is seconds
+func parseInterval(text string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(text); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(text)
+}
+
+// stopWatch stops the watch the user started and clears the interval of the watched result, returning the watch
+func (csm *ContextualStateManager) stopWatch() *watchJob {
+	csm.mu.Lock()
+	defer csm.mu.Unlock()
+	return csm.stopWatchJob(&csm.watch)
+}
+
+// stopLiveWatch stops refreshing the live view, returning its watch
+func (csm *ContextualStateManager) stopLiveWatch() *watchJob {
+	csm.mu.Lock()
+	defer csm.mu.Unlock()
+	return csm.stopWatchJob(&csm.liveWatch)
+}
+
+// stopWatchJob stops the watch held by slot and clears the interval of the states it watched,
+// the caller holds the lock
+func (csm *ContextualStateManager) stopWatchJob(slot **watchJob) *watchJob {
+	job := *slot
+	if job == nil {
+		return nil
+	}
+	close(job.stop)
+	*slot = nil
+	for i := range csm.stateStack {
+		if job.watches(csm.stateStack[i]) {
+			csm.stateStack[i].WatchInterval = 0
+		}
+	}
+	return job
+}
+
+// runWatch refreshes the watched state every interval until the watch is stopped or the state is gone
+func (csm *ContextualStateManager) runWatch(job *watchJob) {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-job.stop:
+			return
+		case <-ticker.C:
+			if !csm.refreshWatched(job) {
+				return
+			}
+		}
+	}
+}
+
+// refreshWatched reads the rows of the watched state again and replaces them in place. A state that is
+// not shown, such as while a command or statement is typed above it, is left alone until it is back.
+// It reports false once the state is gone from the history and the pinned results
+func (csm *ContextualStateManager) refreshWatched(job *watchJob) bool {
+	csm.mu.Lock()
+	kept := false
+	for _, state := range append(append([]State{}, csm.pinned...), csm.stateStack...) {
+		kept = kept || job.watches(state)
+	}
+	if !kept && csm.watch == job {
+		csm.watch = nil
+	}
+	if !kept && csm.liveWatch == job {
+		csm.liveWatch = nil
+	}
+	csm.mu.Unlock()
+	if !kept {
+		slog.Debug("watched state is gone, stopping")
+		return false
+	}
+
+	current := csm.GetCurrentState()
+	if !job.watches(current) {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), watchTimeout)
+	defer cancel()
+	headers, data := job.fetch(ctx, current)
+	status := fmt.Sprintf("watching every %s, refreshed %s", job.interval, time.Now().Format(time.TimeOnly))
+	if job.describe != nil {
+		if text := job.describe(); text != "" {
+			status = text + ", " + status
+		}
+	}
+
+	csm.queueUpdate(func() {
+		current := csm.GetCurrentState()
+		csm.mu.RLock()
+		watching := csm.watch == job || csm.liveWatch == job
+		csm.mu.RUnlock()
+		// the state may have been left or the watch stopped while the query ran
+		if !watching || !job.watches(current) {
+			return
+		}
+		current.TableHeaders, current.TableData = headers, data
+		current.StatusText = status
+		csm.ReplaceState(context.Background(), current)
+	})
+	return true
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+// jobsServer returns one more finished job on every query
+type jobsServer struct {
+	db.MysqlMock
+	runs int
+}
+
+func (s *jobsServer) FetchSqlRows(ctx context.Context, sqlQuery string, args ...any) ([]string, []db.TableData) {
+	s.runs++
+	return []string{"done"}, []db.TableData{map[string]string{"done": fmt.Sprint(s.runs)}}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected time.Duration
+		err      bool
+	}{
+		{text: "5", expected: 5 * time.Second},
+		{text: "5s", expected: 5 * time.Second},
+		{text: "1m30s", expected: 90 * time.Second},
+		{text: "soon", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			interval, err := parseInterval(tt.text)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, interval)
+		})
+	}
+}
+
+func TestHandleEventWatchCommand(t *testing.T) {
+	server := &jobsServer{}
+	stateManager := NewContextualStateManager(server, *Initial, 10)
+	ctx := context.Background()
+	command := func(text string) State {
+		stateManager.PushState(ctx, State{Mode: Command})
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: text})
+		return stateManager.GetCurrentState()
+	}
+
+	assert.Equal(t, "watch re-runs query results, the process list and the status, run a query first", command("watch 5s").StatusText)
+	assert.Equal(t, "not watching", command("watch off").StatusText)
+
+	stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT COUNT(*) done FROM jobs"))
+	assert.Equal(t, watchUsage, command("watch").StatusText)
+	assert.Equal(t, watchUsage, command("watch 10ms").StatusText)
+
+	watched := command("watch 1m")
+	assert.Equal(t, "watching every 1m0s", watched.StatusText)
+	assert.Equal(t, time.Minute, watched.WatchInterval)
+	job := stateManager.watch
+
+	// a refresh replaces the rows in place
+	assert.True(t, stateManager.refreshWatched(job))
+	refreshed := stateManager.GetCurrentState()
+	assert.Equal(t, watched.resultID, refreshed.resultID)
+	assert.Equal(t, []db.TableData{map[string]string{"done": "2"}}, refreshed.TableData)
+	assert.Contains(t, refreshed.StatusText, "watching every 1m0s, refreshed ")
+
+	// paused while typing a command
+	stateManager.PushState(ctx, State{Mode: Command})
+	assert.True(t, stateManager.refreshWatched(job))
+	assert.Equal(t, 2, server.runs)
+	stateManager.PopState(ctx)
+
+	assert.Equal(t, "stopped watching", command("watch off").StatusText)
+	assert.Zero(t, stateManager.GetCurrentState().WatchInterval)
+	assert.Nil(t, stateManager.watch)
+
+	// leaving the result ends the watch
+	command("watch 1m")
+	job = stateManager.watch
+	stateManager.PopState(ctx)
+	assert.False(t, stateManager.refreshWatched(job))
+	assert.Nil(t, stateManager.watch)
+	close(job.stop)
+}
+
+func TestLiveViewKeepsWatch(t *testing.T) {
+	server := &jobsServer{}
+	stateManager := NewContextualStateManager(server, *Initial, 10)
+	ctx := context.Background()
+	command := func(text string) State {
+		stateManager.PushState(ctx, State{Mode: Command})
+		stateManager.HandleEvent(&Event{Event: tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), Text: text})
+		return stateManager.GetCurrentState()
+	}
+
+	stateManager.PushState(ctx, stateManager.createStateWithSqlRows(ctx, "SELECT COUNT(*) done FROM jobs"))
+	command("watch 1m")
+	job := stateManager.watch
+
+	// the process list refreshes on its own while the result stays watched
+	command("proc")
+	live := stateManager.liveWatch
+	assert.NotNil(t, live)
+	assert.Same(t, job, stateManager.watch)
+	assert.True(t, stateManager.refreshWatched(live))
+	assert.Contains(t, stateManager.GetCurrentState().StatusText, "watching every 2s, refreshed ")
+
+	// a watch of the user on the process list replaces its refresh
+	watched := command("watch 5s")
+	assert.Equal(t, 5*time.Second, watched.WatchInterval)
+	assert.Nil(t, stateManager.liveWatch)
+	assert.NotSame(t, job, stateManager.watch)
+
+	// a process list opened while the user watches one is left to that watch
+	command("proc")
+	assert.Nil(t, stateManager.liveWatch)
+
+	// off stops refreshing the live view as well
+	stateManager.stopWatch()
+	command("proc")
+	assert.NotNil(t, stateManager.liveWatch)
+	assert.Equal(t, "stopped watching", command("watch off").StatusText)
+	assert.Nil(t, stateManager.liveWatch)
+}
@@ -0,0 +1,189 @@
+package queries
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rel8/db"
+)
+
+// Query is a named SQL snippet stored as a .sql file
+type Query struct {
+	Name string
+	// connection the query is kept for, empty when shared by all connections
+	Connection string
+	Path       string
+	SQL        string
+}
+
+// Library stores queries as .sql files in Dir, the queries of a single connection
+// in a subdirectory named after the connection
+type Library struct {
+	Dir string
+}
+
+var (
+	// paramPattern matches ${name} placeholders
+	paramPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// unsafeChars are replaced in directory names made from connection names
+	unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// DefaultDir returns the queries directory under the user configuration directory
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rel8", "queries"), nil
+}
+
+// List returns the shared queries and those of the connection, sorted by name.
+// A missing directory holds no queries
+func (l Library) List(connection string) ([]Query, error) {
+	queries, err := readDir(l.Dir, "")
+	if err != nil {
+		return nil, err
+	}
+	if connection != "" {
+		connectionQueries, err := readDir(l.connectionDir(connection), connection)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, connectionQueries...)
+	}
+
+	sort.SliceStable(queries, func(i, j int) bool {
+		return strings.ToLower(queries[i].Name) < strings.ToLower(queries[j].Name)
+	})
+	return queries, nil
+}
+
+// Save stores sql as a new query, kept for the connection unless it is empty.
+// An existing query of the same name is not overwritten
+func (l Library) Save(name string, connection string, sql string) (Query, error) {
+	name = strings.TrimSpace(strings.TrimSuffix(name, ".sql"))
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return Query{}, fmt.Errorf("invalid query name %q", name)
+	}
+
+	dir := l.Dir
+	if connection != "" {
+		dir = l.connectionDir(connection)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Query{}, err
+	}
+
+	path := filepath.Join(dir, name+".sql")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return Query{}, fmt.Errorf("query %s already exists", name)
+	}
+	if err != nil {
+		return Query{}, err
+	}
+	if _, err := file.WriteString(sql); err != nil {
+		file.Close()
+		return Query{}, err
+	}
+	if err := file.Close(); err != nil {
+		return Query{}, err
+	}
+
+	return Query{Name: name, Connection: connection, Path: path, SQL: sql}, nil
+}
+
+// connectionDir returns the directory of the queries kept for a connection
+func (l Library) connectionDir(connection string) string {
+	return filepath.Join(l.Dir, unsafeChars.ReplaceAllString(connection, "_"))
+}
+
+// readDir reads the .sql files of a directory
+func readDir(dir string, connection string) ([]Query, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []Query
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, Query{
+			Name:       strings.TrimSuffix(entry.Name(), ".sql"),
+			Connection: connection,
+			Path:       path,
+			SQL:        string(content),
+		})
+	}
+	return queries, nil
+}
+
+// Params returns the names of the ${name} placeholders of sql in order of first use, those in string literals,
+// quoted identifiers and comments are text
+func Params(sql string) []string {
+	var params []string
+	seen := map[string]bool{}
+	for _, code := range codeRuns(sql) {
+		if !code.code {
+			continue
+		}
+		for _, match := range paramPattern.FindAllStringSubmatch(code.text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				params = append(params, match[1])
+			}
+		}
+	}
+	return params
+}
+
+// NamedPlaceholders rewrites ${name} placeholders into :name bind parameters, so their values are bound by the
+// driver rather than pasted into the statement. String literals, quoted identifiers and comments are kept as written
+func NamedPlaceholders(sql string) string {
+	var rewritten strings.Builder
+	for _, run := range codeRuns(sql) {
+		if run.code {
+			rewritten.WriteString(paramPattern.ReplaceAllString(run.text, ":$1"))
+		} else {
+			rewritten.WriteString(run.text)
+		}
+	}
+	return rewritten.String()
+}
+
+// textRun is a stretch of SQL text, code or a string literal, quoted identifier or comment
+type textRun struct {
+	text string
+	code bool
+}
+
+// codeRuns splits sql into runs of code and of literal text, a ${name} placeholder spans several code tokens
+func codeRuns(sql string) []textRun {
+	var runs []textRun
+	for _, token := range db.Tokenize(sql) {
+		code := token.Kind != db.TokenString && token.Kind != db.TokenQuotedIdentifier && token.Kind != db.TokenComment
+		if len(runs) > 0 && code && runs[len(runs)-1].code {
+			runs[len(runs)-1].text += token.Text
+			continue
+		}
+		runs = append(runs, textRun{text: token.Text, code: code})
+	}
+	return runs
+}
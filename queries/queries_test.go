@@ -0,0 +1,60 @@
+package queries
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibrary(t *testing.T) {
+	library := Library{Dir: filepath.Join(t.TempDir(), "queries")}
+
+	// a library without a directory is empty
+	queries, err := library.List("localhost:3306/shop")
+	assert.NoError(t, err)
+	assert.Empty(t, queries)
+
+	_, err = library.Save("slow", "", "SELECT * FROM slow_log")
+	assert.NoError(t, err)
+	saved, err := library.Save("big tables.sql", "localhost:3306/shop", "SELECT 1")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(library.Dir, "localhost_3306_shop", "big tables.sql"), saved.Path)
+	_, err = library.Save("other", "localhost:3306/crm", "SELECT 2")
+	assert.NoError(t, err)
+
+	// files other than .sql are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(library.Dir, "notes.txt"), []byte("x"), 0o644))
+
+	queries, err = library.List("localhost:3306/shop")
+	assert.NoError(t, err)
+	assert.Equal(t, []Query{
+		{Name: "big tables", Connection: "localhost:3306/shop", Path: saved.Path, SQL: "SELECT 1"},
+		{Name: "slow", Path: filepath.Join(library.Dir, "slow.sql"), SQL: "SELECT * FROM slow_log"},
+	}, queries)
+
+	_, err = library.Save("slow", "", "SELECT 3")
+	assert.EqualError(t, err, "query slow already exists")
+	_, err = library.Save("../escape", "", "SELECT 3")
+	assert.Error(t, err)
+	_, err = library.Save(" ", "", "SELECT 3")
+	assert.Error(t, err)
+}
+
+func TestParams(t *testing.T) {
+	sql := "SELECT * FROM orders WHERE user_id = ${user_id} AND status = ${status} OR user_id = ${user_id} -- $1 ${1x}"
+	assert.Equal(t, []string{"user_id", "status"}, Params(sql))
+	// placeholders in strings and comments are text
+	assert.Equal(t, []string{"id"}, Params("SELECT '${note}' FROM users /* ${old} */ WHERE id = ${id} -- ${other}"))
+	assert.Nil(t, Params("SELECT 1"))
+}
+
+func TestNamedPlaceholders(t *testing.T) {
+	sql := "SELECT * FROM users WHERE id = ${id} AND note = ${note} OR id = ${id} -- ${1x}"
+	assert.Equal(t, "SELECT * FROM users WHERE id = :id AND note = :note OR id = :id -- ${1x}", NamedPlaceholders(sql))
+
+	// strings, quoted identifiers and comments are kept as written
+	sql = "SELECT '${note}', `${col}` FROM users /* ${old} */ WHERE id = ${id} -- ${other}"
+	assert.Equal(t, "SELECT '${note}', `${col}` FROM users /* ${old} */ WHERE id = :id -- ${other}", NamedPlaceholders(sql))
+}
@@ -19,10 +19,38 @@ type AppColors struct {
 	// Text colors - tview color tags
 	KeyColor        string // For key bindings
 	TextDefault     string // Default text color
-	HeaderLabel     string // For header labels (Context:, CPU:, etc.)
+	HeaderLabel     string // For header labels (Context:, QPS:, etc.)
 	HeaderValue     string // For header values
 	HeaderHighlight string // For highlighted header values
 	HeaderSecondary string // For secondary header text
+	HeaderAlert     string // Background of header badges such as replication lag
+	TabActive       string // For the selected tab title
+	TabInactive     string // For other tab titles
+
+	// Editor colors - tcell colors
+	SyntaxKeyword    tcell.Color
+	SyntaxDataType   tcell.Color
+	SyntaxFunction   tcell.Color
+	SyntaxString     tcell.Color
+	SyntaxIdentifier tcell.Color // For quoted identifiers
+	SyntaxNumber     tcell.Color
+	SyntaxComment    tcell.Color
+	LineNumber       tcell.Color
+	LineNumberActive tcell.Color // For the line number of the cursor line
+	BracketMatch     tcell.Color // Background of matching brackets
+
+	// Plan colors - tcell colors
+	PlanStep     tcell.Color
+	PlanFullScan tcell.Color // For steps reading every row of a table
+
+	// Diff colors - tcell colors
+	DiffAdded       tcell.Color
+	DiffRemoved     tcell.Color
+	DiffChanged     tcell.Color
+	DiffChangedCell tcell.Color // Background of the cells that changed
+
+	// Watch colors - tcell colors
+	WatchFlash tcell.Color // Background of cells changed by the last refresh
 }
 
 // DefaultColors returns the default color scheme
@@ -45,8 +73,36 @@ func DefaultColors() *AppColors {
 		TextDefault:     "white",   // Default white text
 		HeaderLabel:     "orange",  // Orange for labels like "Context:"
 		HeaderValue:     "aqua",    // Aqua for values like "dev"
-		HeaderHighlight: "lime",    // Lime for highlighted values like CPU/MEM percentages
+		HeaderHighlight: "lime",    // Lime for highlighted values like queries a second
 		HeaderSecondary: "silver",  // Silver for secondary text
+		HeaderAlert:     "red",     // Red badges for replication problems
+		TabActive:       "aqua",    // Aqua for the selected tab
+		TabInactive:     "silver",  // Silver for other tabs
+
+		// Editor colors - same palette as highlighted SQL text
+		SyntaxKeyword:    tcell.ColorLightBlue,
+		SyntaxDataType:   tcell.ColorLightGreen,
+		SyntaxFunction:   tcell.ColorYellow,
+		SyntaxString:     tcell.ColorRed,
+		SyntaxIdentifier: tcell.ColorAqua,
+		SyntaxNumber:     tcell.ColorFuchsia,
+		SyntaxComment:    tcell.ColorGray,
+		LineNumber:       tcell.ColorGray,
+		LineNumberActive: tcell.ColorWhite,
+		BracketMatch:     tcell.ColorDarkSlateGray,
+
+		// Plan colors
+		PlanStep:     tcell.ColorWhite,
+		PlanFullScan: tcell.ColorRed,
+
+		// Diff colors
+		DiffAdded:       tcell.ColorLightGreen,
+		DiffRemoved:     tcell.ColorRed,
+		DiffChanged:     tcell.ColorYellow,
+		DiffChangedCell: tcell.ColorDarkSlateGray,
+
+		// Watch colors
+		WatchFlash: tcell.ColorDarkGoldenrod,
 	}
 }
 
@@ -1,14 +1,22 @@
 package view
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/rivo/uniseg"
 	"rel8/db"
 )
 
-// Editor wraps a TextArea with editor-specific functionality
+// Editor wraps a TextArea with SQL syntax highlighting, line numbers, bracket matching and auto-indent
 type Editor struct {
 	*tview.TextArea
+	// tokens of tokenText, refreshed when the text changes
+	tokenText string
+	tokens    []db.Token
 }
 
 // NewEditor creates a new editor view with proper configuration
@@ -16,11 +24,8 @@ func NewEditor(text string) *Editor {
 	textArea := tview.NewTextArea().
 		SetWrap(false)
 
-	// Apply SQL syntax highlighting if the text looks like SQL
-	// (typically CREATE TABLE statements from FetchTableDescr)
-	highlightedText := db.HighlightSQL(text)
-
-	textArea.SetText(highlightedText, false)
+	// the text is kept as typed, highlighting is applied while drawing
+	textArea.SetText(text, false)
 	textArea.SetBackgroundColor(Colors.BackgroundDefault)
 
 	// Add same border styling as table
@@ -35,11 +40,248 @@ func NewEmptyEditor() *Editor {
 	return NewEditor("")
 }
 
-// UpdateText updates the editor view with new text
+// SetClipboardWriter sends text copied with Ctrl-Q or cut with Ctrl-X to write,
+// Ctrl-V pastes the last copied text
+func (e *Editor) SetClipboardWriter(write func(text string)) {
+	var clipboard string
+	e.SetClipboard(func(text string) {
+		clipboard = text
+		write(text)
+	}, func() string {
+		return clipboard
+	})
+}
+
+// UpdateText replaces the editor text
 func (e *Editor) UpdateText(text string) {
-	// Apply SQL syntax highlighting
-	highlightedText := db.HighlightSQL(text)
-	e.SetText(highlightedText, false)
+	e.SetText(text, false)
+}
+
+// ReplaceText replaces the whole text and moves the cursor to the top, Ctrl-Z undoes it
+func (e *Editor) ReplaceText(text string) {
+	e.Replace(0, len(e.GetText()), text)
+	e.Select(0, 0)
+	e.SetOffset(0, 0)
+}
+
+// SetStatus shows a short status text in the editor border, empty text clears it
+func (e *Editor) SetStatus(text string) {
+	if text == "" {
+		e.SetTitle("")
+		return
+	}
+	e.SetTitle(" " + text + " ")
+}
+
+// Format pretty prints the editor text
+func (e *Editor) Format(options db.FormatOptions) {
+	text := e.GetText()
+	if formatted := db.PrettyPrintScript(text, options); formatted != text {
+		e.ReplaceText(formatted)
+	}
+}
+
+// InputHandler keeps the indentation of the current line on Enter, one level deeper after an opening bracket.
+// Ctrl-T formats the text, Alt-T also upper cases keywords
+func (e *Editor) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	handler := e.TextArea.InputHandler()
+	return func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if event.Key() == tcell.KeyCtrlT {
+			e.Format(db.FormatOptions{})
+			return
+		}
+		if event.Key() == tcell.KeyRune && event.Rune() == 't' && event.Modifiers()&tcell.ModAlt != 0 {
+			e.Format(db.FormatOptions{UppercaseKeywords: true})
+			return
+		}
+		if event.Key() != tcell.KeyEnter || event.Modifiers() != tcell.ModNone {
+			handler(event, setFocus)
+			return
+		}
+
+		_, start, _ := e.GetSelection()
+		indent := indentAfter(e.GetText()[:start])
+		handler(event, setFocus)
+
+		// typed rather than inserted so the text area keeps the cursor in view
+		for _, r := range indent {
+			if r == '\t' {
+				handler(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone), setFocus)
+			} else {
+				handler(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), setFocus)
+			}
+		}
+	}
+}
+
+// Draw draws the text area with a line number gutter, then colors tokens and matching brackets over it
+func (e *Editor) Draw(screen tcell.Screen) {
+	text := e.GetText()
+	digits := len(strconv.Itoa(strings.Count(text, "\n") + 1))
+	e.SetBorderPadding(0, 0, digits+2, 1)
+	e.TextArea.Draw(screen)
+
+	x, y, width, height := e.GetInnerRect()
+	if width <= 0 || height <= 0 {
+		return
+	}
+	if e.tokens == nil || text != e.tokenText {
+		e.tokenText, e.tokens = text, db.Tokenize(text)
+	}
+
+	rowOffset, columnOffset := e.GetOffset()
+	_, _, cursorRow, _ := e.GetCursor()
+	_, selectionStart, selectionEnd := e.GetSelection()
+	openBracket, closeBracket := -1, -1
+	if selectionStart == selectionEnd {
+		openBracket, closeBracket = matchBracket(e.tokens, selectionEnd)
+	}
+
+	lineStart := 0
+	for line := 0; line < rowOffset && lineStart >= 0; line++ {
+		lineStart = nextLineStart(text, lineStart)
+	}
+
+	token := 0
+	for row := 0; row < height && lineStart >= 0; row++ {
+		line := rowOffset + row
+		numberStyle := tcell.StyleDefault.Background(Colors.BackgroundDefault).Foreground(Colors.LineNumber)
+		if line == cursorRow {
+			numberStyle = numberStyle.Foreground(Colors.LineNumberActive)
+		}
+		for i, r := range fmt.Sprintf("%*d", digits, line+1) {
+			screen.SetContent(x-digits-1+i, y+row, r, nil, numberStyle)
+		}
+
+		lineEnd := len(text)
+		if next := nextLineStart(text, lineStart); next >= 0 {
+			lineEnd = next - 1
+		}
+
+		column := 0
+		for offset := lineStart; offset < lineEnd && column-columnOffset < width; {
+			cluster, _, clusterWidth, _ := uniseg.FirstGraphemeClusterInString(text[offset:lineEnd], -1)
+			if cluster == "\t" {
+				clusterWidth = tview.TabSize
+			}
+			for token < len(e.tokens)-1 && e.tokens[token].End <= offset {
+				token++
+			}
+
+			screenColumn := column - columnOffset
+			selected := offset >= selectionStart && offset < selectionEnd
+			if screenColumn >= 0 && clusterWidth > 0 && !selected {
+				mainc, combc, style, _ := screen.GetContent(x+screenColumn, y+row)
+				if color, ok := syntaxColor(e.tokens[token].Kind); ok {
+					style = style.Foreground(color)
+				}
+				if offset == openBracket || offset == closeBracket {
+					style = style.Background(Colors.BracketMatch)
+				}
+				screen.SetContent(x+screenColumn, y+row, mainc, combc, style)
+			}
+
+			column += clusterWidth
+			offset += len(cluster)
+		}
+
+		lineStart = nextLineStart(text, lineStart)
+	}
+}
+
+// syntaxColor returns the color of a token kind, plain identifiers and operators keep the text color
+func syntaxColor(kind db.TokenKind) (tcell.Color, bool) {
+	switch kind {
+	case db.TokenKeyword:
+		return Colors.SyntaxKeyword, true
+	case db.TokenDataType:
+		return Colors.SyntaxDataType, true
+	case db.TokenFunction:
+		return Colors.SyntaxFunction, true
+	case db.TokenString:
+		return Colors.SyntaxString, true
+	case db.TokenQuotedIdentifier:
+		return Colors.SyntaxIdentifier, true
+	case db.TokenNumber:
+		return Colors.SyntaxNumber, true
+	case db.TokenComment:
+		return Colors.SyntaxComment, true
+	default:
+		return tcell.ColorDefault, false
+	}
+}
+
+// matchBracket finds the bracket at the cursor, or just before it, and its counterpart.
+// It returns the offsets of the opening and closing bracket, or -1 when there is no match
+func matchBracket(tokens []db.Token, cursor int) (int, int) {
+	pairs := map[string]string{"(": ")", "[": "]", "{": "}"}
+	reverse := map[string]string{")": "(", "]": "[", "}": "{"}
+
+	for _, offset := range []int{cursor, cursor - 1} {
+		for i, token := range tokens {
+			if token.Start != offset || token.Kind != db.TokenPunctuation {
+				continue
+			}
+			if closing, ok := pairs[token.Text]; ok {
+				depth := 0
+				for _, next := range tokens[i:] {
+					if next.Kind != db.TokenPunctuation {
+						continue
+					}
+					switch next.Text {
+					case token.Text:
+						depth++
+					case closing:
+						depth--
+						if depth == 0 {
+							return token.Start, next.Start
+						}
+					}
+				}
+			}
+			if opening, ok := reverse[token.Text]; ok {
+				depth := 0
+				for j := i; j >= 0; j-- {
+					if tokens[j].Kind != db.TokenPunctuation {
+						continue
+					}
+					switch tokens[j].Text {
+					case token.Text:
+						depth++
+					case opening:
+						depth--
+						if depth == 0 {
+							return tokens[j].Start, token.Start
+						}
+					}
+				}
+			}
+		}
+	}
+	return -1, -1
+}
+
+// indentAfter returns the indentation for a new line following text,
+// the leading whitespace of its last line plus two spaces after an opening bracket
+func indentAfter(text string) string {
+	line := text[strings.LastIndexByte(text, '\n')+1:]
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	if strings.HasSuffix(strings.TrimRight(line, " \t"), "(") {
+		indent += "  "
+	}
+	return indent
+}
+
+// nextLineStart returns the offset of the line following the one starting at start, or -1 on the last line
+func nextLineStart(text string, start int) int {
+	if start < 0 {
+		return -1
+	}
+	next := strings.IndexByte(text[start:], '\n')
+	if next < 0 {
+		return -1
+	}
+	return start + next + 1
 }
 
 // WrapEditor wraps editor with padding (only left/right, NO top/bottom)
@@ -47,6 +289,6 @@ func WrapEditor(editor *Editor) *tview.Flex {
 	return tview.NewFlex().
 		SetDirection(tview.FlexColumn).
 		AddItem(nil, 0, 0, false). // Left padding
-		AddItem(editor.TextArea, 0, 1, true).
+		AddItem(editor, 0, 1, true).
 		AddItem(nil, 0, 0, false) // Right padding
 }
@@ -0,0 +1,141 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+// drawEditor draws the editor focused on a simulation screen
+func drawEditor(t *testing.T, editor *Editor) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("")
+	assert.NoError(t, screen.Init())
+	t.Cleanup(screen.Fini)
+	screen.SetSize(40, 6)
+
+	editor.SetRect(0, 0, 40, 6)
+	editor.Focus(func(p tview.Primitive) {})
+	editor.Draw(screen)
+	return screen
+}
+
+// cellAt returns the rune and foreground color drawn at a position
+func cellAt(screen tcell.SimulationScreen, x, y int) (rune, tcell.Color, tcell.Color) {
+	mainc, _, style, _ := screen.GetContent(x, y)
+	fg, bg, _ := style.Decompose()
+	return mainc, fg, bg
+}
+
+func TestEditorKeepsTextAsTyped(t *testing.T) {
+	editor := NewEditor("SELECT 1")
+	assert.Equal(t, "SELECT 1", editor.GetText())
+
+	editor.UpdateText("SELECT name FROM users")
+	assert.Equal(t, "SELECT name FROM users", editor.GetText())
+}
+
+func TestEditorDraw(t *testing.T) {
+	editor := NewEditor("SELECT 'a'\n-- note")
+	editor.Select(0, 0)
+	screen := drawEditor(t, editor)
+
+	// border, a space, one digit and a space precede the text
+	r, fg, _ := cellAt(screen, 2, 1)
+	assert.Equal(t, '1', r)
+	assert.Equal(t, Colors.LineNumberActive, fg)
+	r, fg, _ = cellAt(screen, 2, 2)
+	assert.Equal(t, '2', r)
+	assert.Equal(t, Colors.LineNumber, fg)
+
+	r, fg, _ = cellAt(screen, 4, 1)
+	assert.Equal(t, 'S', r)
+	assert.Equal(t, Colors.SyntaxKeyword, fg)
+	r, fg, _ = cellAt(screen, 12, 1)
+	assert.Equal(t, 'a', r)
+	assert.Equal(t, Colors.SyntaxString, fg)
+	r, fg, _ = cellAt(screen, 4, 2)
+	assert.Equal(t, '-', r)
+	assert.Equal(t, Colors.SyntaxComment, fg)
+}
+
+func TestEditorDrawMatchingBrackets(t *testing.T) {
+	editor := NewEditor("SELECT COUNT(id)")
+	editor.Select(12, 12)
+	screen := drawEditor(t, editor)
+
+	_, fg, _ := cellAt(screen, 4+7, 1)
+	assert.Equal(t, Colors.SyntaxFunction, fg)
+	_, _, bg := cellAt(screen, 4+12, 1)
+	assert.Equal(t, Colors.BracketMatch, bg)
+	_, _, bg = cellAt(screen, 4+15, 1)
+	assert.Equal(t, Colors.BracketMatch, bg)
+	_, _, bg = cellAt(screen, 4+13, 1)
+	assert.NotEqual(t, Colors.BracketMatch, bg)
+}
+
+func TestMatchBracket(t *testing.T) {
+	tokens := db.Tokenize("f(a, (b), ')') [x]")
+
+	tests := []struct {
+		name          string
+		cursor        int
+		expectedOpen  int
+		expectedClose int
+	}{
+		{"on opening bracket", 1, 1, 13},
+		{"after closing bracket", 14, 1, 13},
+		{"nested bracket", 5, 5, 7},
+		{"square brackets", 15, 15, 17},
+		{"bracket in string is ignored", 11, -1, -1},
+		{"no bracket", 3, -1, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, closing := matchBracket(tokens, tt.cursor)
+			assert.Equal(t, tt.expectedOpen, open)
+			assert.Equal(t, tt.expectedClose, closing)
+		})
+	}
+}
+
+func TestEditorAutoIndent(t *testing.T) {
+	editor := NewEditor("SELECT *\n  FROM t WHERE id IN (")
+	editor.SetRect(0, 0, 40, 6)
+	editor.Select(len(editor.GetText()), len(editor.GetText()))
+
+	handler := editor.InputHandler()
+	enter := tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+	handler(enter, func(p tview.Primitive) {})
+	handler(tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModNone), func(p tview.Primitive) {})
+
+	assert.Equal(t, "SELECT *\n  FROM t WHERE id IN (\n    1", editor.GetText())
+}
+
+func TestEditorFormat(t *testing.T) {
+	editor := NewEditor("select id, name from users where id = 1")
+	editor.SetRect(0, 0, 40, 8)
+	handler := editor.InputHandler()
+
+	handler(tcell.NewEventKey(tcell.KeyCtrlT, 0, tcell.ModCtrl), func(p tview.Primitive) {})
+	assert.Equal(t, "select\n  id,\n  name\nfrom users\nwhere id = 1", editor.GetText())
+	_, start, end := editor.GetSelection()
+	assert.Equal(t, 0, start)
+	assert.Equal(t, 0, end)
+
+	handler(tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModAlt), func(p tview.Primitive) {})
+	assert.Equal(t, "SELECT\n  id,\n  name\nFROM users\nWHERE id = 1", editor.GetText())
+
+	// formatting can be undone
+	handler(tcell.NewEventKey(tcell.KeyCtrlZ, 0, tcell.ModCtrl), func(p tview.Primitive) {})
+	assert.Equal(t, "select\n  id,\n  name\nfrom users\nwhere id = 1", editor.GetText())
+}
+
+func TestIndentAfter(t *testing.T) {
+	assert.Equal(t, "", indentAfter("SELECT"))
+	assert.Equal(t, "  ", indentAfter("SELECT\n  a,"))
+	assert.Equal(t, "\t  ", indentAfter("\tVALUES ( "))
+}
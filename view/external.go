@@ -0,0 +1,99 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"rel8/db"
+	"rel8/model"
+)
+
+// editorCommand returns the command line of the user's editor, $VISUAL before $EDITOR and vi by default
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// editInExternalEditor writes text to a temporary file, runs the user's editor on it
+// in the terminal and returns the text saved by the editor
+func editInExternalEditor(text string) (string, error) {
+	file, err := os.CreateTemp("", "rel8-*.sql")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	command := editorCommand()
+	cmd := exec.Command(command[0], append(command[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w", command[0], err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
+
+// isExternalEditKey reports whether event opens the editor text in $EDITOR, Ctrl-O edits and Alt-O also runs it
+func isExternalEditKey(event *tcell.EventKey) (edit bool, run bool) {
+	if event.Key() == tcell.KeyCtrlO {
+		return true, false
+	}
+	if event.Key() == tcell.KeyRune && event.Rune() == 'o' && event.Modifiers()&tcell.ModAlt != 0 {
+		return true, true
+	}
+	return false, false
+}
+
+// editExternally suspends the application while the user's editor edits the editor text and loads the result.
+// With run the script is executed right away, as with F5 for one statement and Ctrl-F5 for several
+func (v *View) editExternally(run bool) {
+	text := v.editor.GetText()
+	var edited string
+	err := errors.New("screen is not ready")
+	v.App.Suspend(func() {
+		edited, err = editInExternalEditor(text)
+	})
+	if err != nil {
+		slog.Error("external editor failed", "error", err)
+		v.editor.SetStatus(fmt.Sprintf("external editor failed: %v", err))
+		return
+	}
+
+	v.editor.SetStatus("")
+	if edited != text {
+		v.editor.ReplaceText(edited)
+	}
+	if !run {
+		return
+	}
+
+	key := tcell.KeyF5
+	if len(db.SplitStatements(edited)) > 1 {
+		key = tcell.KeyF29
+	}
+	v.stateManager.HandleEvent(&model.Event{
+		Event: tcell.NewEventKey(key, 0, tcell.ModNone),
+		Text:  edited,
+	})
+}
@@ -0,0 +1,67 @@
+package view
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+	"rel8/model"
+)
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	assert.Equal(t, []string{"vi"}, editorCommand())
+
+	t.Setenv("EDITOR", "nano -w")
+	assert.Equal(t, []string{"nano", "-w"}, editorCommand())
+
+	t.Setenv("VISUAL", "code --wait")
+	assert.Equal(t, []string{"code", "--wait"}, editorCommand())
+}
+
+func TestIsExternalEditKey(t *testing.T) {
+	edit, run := isExternalEditKey(tcell.NewEventKey(tcell.KeyCtrlO, 0, tcell.ModCtrl))
+	assert.True(t, edit)
+	assert.False(t, run)
+
+	edit, run = isExternalEditKey(tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModAlt))
+	assert.True(t, edit)
+	assert.True(t, run)
+
+	edit, _ = isExternalEditKey(tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone))
+	assert.False(t, edit)
+}
+
+func TestViewEditExternally(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "sed -i s/users/orders/")
+
+	stateManager := model.NewContextualStateManager(&db.MysqlMock{}, model.State{Mode: model.Editor}, 10)
+	view := NewView(stateManager)
+	screen := tcell.NewSimulationScreen("")
+	view.SetScreen(screen)
+	defer screen.Fini()
+	// the application is not running, background work updates the state right away
+	stateManager.SetUpdateQueue(func(update func()) { update() })
+
+	view.editor.SetText("SELECT * FROM users", false)
+	view.editExternally(false)
+	assert.Equal(t, "SELECT * FROM orders", view.editor.GetText())
+	assert.Equal(t, model.Editor, stateManager.GetCurrentState().Mode)
+
+	// running executes the edited statement
+	view.editor.SetText("SELECT * FROM users", false)
+	view.editExternally(true)
+	assert.Eventually(t, func() bool { return stateManager.GetCurrentState().Mode == model.Browse }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "SELECT * FROM orders", stateManager.GetCurrentState().Query)
+
+	// a failing editor leaves the text unchanged
+	t.Setenv("EDITOR", "false")
+	view.editor.SetText("SELECT 1", false)
+	view.editExternally(false)
+	assert.Equal(t, "SELECT 1", view.editor.GetText())
+	assert.Contains(t, view.editor.GetTitle(), "external editor failed")
+}
@@ -0,0 +1,67 @@
+package view
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"rel8/model"
+)
+
+// Form wraps a tview Form asking for the values of a form state
+type Form struct {
+	*tview.Form
+}
+
+// NewForm creates a new empty form
+func NewForm() *Form {
+	form := tview.NewForm()
+	form.SetBackgroundColor(Colors.BackgroundDefault)
+	form.SetLabelColor(Colors.TextLightSkyBlue)
+	form.SetFieldBackgroundColor(tcell.ColorDarkSlateGray)
+	form.SetFieldTextColor(Colors.TextWhite)
+
+	// Add same border styling as table
+	form.SetBorder(true).SetBorderPadding(0, 0, 1, 1).SetBorderColor(Colors.BorderDefault)
+	form.SetBorderAttributes(tcell.AttrNone)
+
+	return &Form{Form: form}
+}
+
+// Populate replaces the fields with those of a form state, the hint shows while a field is empty
+func (f *Form) Populate(title string, fields []model.FormField) {
+	f.Clear(true)
+	for _, field := range fields {
+		input := tview.NewInputField().
+			SetLabel(field.Label + ": ").
+			SetText(field.Value).
+			SetPlaceholder(field.Hint).
+			SetPlaceholderTextColor(Colors.LineNumber)
+		f.AddFormItem(input)
+	}
+	f.SetFocus(0)
+
+	if title == "" {
+		f.SetTitle("")
+	} else {
+		f.SetTitle(" " + title + ", enter to submit ")
+	}
+}
+
+// Values returns the entered values in field order
+func (f *Form) Values() []string {
+	values := make([]string, f.GetFormItemCount())
+	for i := range values {
+		if input, ok := f.GetFormItem(i).(*tview.InputField); ok {
+			values[i] = input.GetText()
+		}
+	}
+	return values
+}
+
+// WrapForm wraps form with padding (only left/right, NO top/bottom)
+func WrapForm(form *Form) *tview.Flex {
+	return tview.NewFlex().
+		SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 0, false). // Left padding
+		AddItem(form, 0, 1, true).
+		AddItem(nil, 0, 0, false) // Right padding
+}
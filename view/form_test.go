@@ -0,0 +1,37 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"rel8/model"
+)
+
+func TestFormPopulate(t *testing.T) {
+	form := NewForm()
+	assert.Empty(t, form.Values())
+
+	form.Populate("save query", []model.FormField{
+		{Label: "Name", Hint: "file name without .sql"},
+		{Label: "Connection only", Value: "n"},
+	})
+	assert.Equal(t, " save query, enter to submit ", form.GetTitle())
+	assert.Equal(t, []string{"", "n"}, form.Values())
+
+	name := form.GetFormItem(0).(*tview.InputField)
+	assert.Equal(t, "Name: ", name.GetLabel())
+	name.SetText("slow")
+	assert.Equal(t, []string{"slow", "n"}, form.Values())
+
+	// populating again replaces the fields
+	form.Populate("", []model.FormField{{Label: "user_id"}})
+	assert.Equal(t, "", form.GetTitle())
+	assert.Equal(t, []string{""}, form.Values())
+}
+
+func TestWrapForm(t *testing.T) {
+	wrapped := WrapForm(NewForm())
+	assert.IsType(t, &tview.Flex{}, wrapped)
+	assert.Equal(t, 3, wrapped.GetItemCount())
+}
@@ -3,6 +3,7 @@ package view
 import (
 	"reflect"
 	"rel8/db"
+	"rel8/model"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -11,12 +12,21 @@ import (
 // Grid wraps a Table with grid-specific functionality
 type Grid struct {
 	*tview.Table
+	// column cursor moved with left and right, marked by an underlined header
+	column int
+	// cells flashed by the last refresh with their background before it
+	flashed []flashedCell
+}
+
+// flashedCell is a cell changed by a refresh
+type flashedCell struct {
+	row, column int
+	background  tcell.Color
 }
 
 // NewGrid creates a new grid with proper configuration
 func NewGrid(headers []string, data []db.TableData) *Grid {
-	table := configureTable()
-	grid := &Grid{Table: table}
+	grid := NewEmptyGrid()
 	grid.Populate(headers, data)
 	return grid
 }
@@ -24,12 +34,48 @@ func NewGrid(headers []string, data []db.TableData) *Grid {
 // NewEmptyGrid creates a new empty grid with proper configuration
 func NewEmptyGrid() *Grid {
 	table := configureTable()
-	return &Grid{Table: table}
+	grid := &Grid{Table: table}
+
+	// Left and right move the column cursor and still scroll the table
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyLeft:
+			grid.SelectColumn(grid.column - 1)
+		case tcell.KeyRight:
+			grid.SelectColumn(grid.column + 1)
+		}
+		return event
+	})
+
+	return grid
+}
+
+// SelectedColumn returns the column under the column cursor
+func (g *Grid) SelectedColumn() int {
+	return g.column
+}
+
+// SelectColumn moves the column cursor, ignoring columns out of range
+func (g *Grid) SelectColumn(column int) {
+	if column < 0 || column >= g.GetColumnCount() {
+		return
+	}
+	g.column = column
+	for col := 0; col < g.GetColumnCount(); col++ {
+		attributes := tcell.AttrBold
+		if col == column {
+			attributes |= tcell.AttrUnderline
+		}
+		if cell := g.GetCell(0, col); cell != nil {
+			cell.SetAttributes(attributes)
+		}
+	}
 }
 
 // Populate fills the grid with headers and data
 func (g *Grid) Populate(headers []string, data []db.TableData) {
 	g.Clear()
+	g.flashed = nil
 
 	// add headers
 	for col, header := range headers {
@@ -58,6 +104,68 @@ func (g *Grid) Populate(headers []string, data []db.TableData) {
 	// Start selection at first data row, not header, and scroll to top
 	g.Select(1, 0)
 	g.ScrollToBeginning()
+
+	// Keep the column cursor when the new data has that column
+	if g.column >= len(headers) {
+		g.column = 0
+	}
+	g.SelectColumn(g.column)
+}
+
+// Refresh repopulates the grid with new rows of the same query, keeping the selection and scroll position,
+// and flashes the cells whose text changed. It reports whether any cell changed
+func (g *Grid) Refresh(headers []string, data []db.TableData) bool {
+	old := map[[2]int]string{}
+	for row := 1; row < g.GetRowCount(); row++ {
+		for col := 0; col < g.GetColumnCount(); col++ {
+			old[[2]int{row, col}] = g.GetCell(row, col).Text
+		}
+	}
+	selectedRow, selectedColumn := g.GetSelection()
+	rowOffset, columnOffset := g.GetOffset()
+
+	g.Populate(headers, data)
+	g.Select(min(selectedRow, max(g.GetRowCount()-1, 1)), selectedColumn)
+	g.SetOffset(rowOffset, columnOffset)
+
+	for row := 1; row < g.GetRowCount(); row++ {
+		for col := 0; col < g.GetColumnCount(); col++ {
+			cell := g.GetCell(row, col)
+			if text, ok := old[[2]int{row, col}]; ok && text == cell.Text {
+				continue
+			}
+			_, background, _ := cell.Style.Decompose()
+			g.flashed = append(g.flashed, flashedCell{row: row, column: col, background: background})
+			cell.SetBackgroundColor(Colors.WatchFlash)
+		}
+	}
+	return len(g.flashed) > 0
+}
+
+// ClearFlash restores the background of the cells flashed by the last refresh
+func (g *Grid) ClearFlash() {
+	for _, flashed := range g.flashed {
+		g.GetCell(flashed.row, flashed.column).SetBackgroundColor(flashed.background)
+	}
+	g.flashed = nil
+}
+
+// MarkDiff colors the rows of a result diff by how they differ and highlights the cells that changed
+func (g *Grid) MarkDiff(headers []string, diff []model.RowDiff) {
+	colors := map[model.DiffKind]tcell.Color{
+		model.DiffAdded:   Colors.DiffAdded,
+		model.DiffRemoved: Colors.DiffRemoved,
+		model.DiffChanged: Colors.DiffChanged,
+	}
+	for row, marks := range diff {
+		for col, header := range headers {
+			cell := g.GetCell(row+1, col)
+			cell.SetTextColor(colors[marks.Kind])
+			if marks.Changed[header] {
+				cell.SetBackgroundColor(Colors.DiffChangedCell)
+			}
+		}
+	}
 }
 
 // RestoreSelection restores the selected row if valid
@@ -67,6 +175,15 @@ func (g *Grid) RestoreSelection(selectedIndex int, dataLen int) {
 	}
 }
 
+// SetStatus shows a short status text in the grid border, empty text clears it
+func (g *Grid) SetStatus(text string) {
+	if text == "" {
+		g.SetTitle("")
+		return
+	}
+	g.SetTitle(" " + text + " ")
+}
+
 // configureTable creates and configures a new table
 func configureTable() *tview.Table {
 	// Force single line borders by setting tview.Borders to use light characters for focus
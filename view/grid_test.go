@@ -3,9 +3,11 @@ package view
 import (
 	"testing"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
 	"rel8/db"
+	"rel8/model"
 )
 
 func TestNewEmptyGrid(t *testing.T) {
@@ -140,3 +142,111 @@ func TestGridRestoreSelection(t *testing.T) {
 	grid.RestoreSelection(5, 3)
 	// Should not crash or change selection inappropriately
 }
+
+func TestGridSetStatus(t *testing.T) {
+	grid := NewEmptyGrid()
+
+	grid.SetStatus("exported 10 rows to out.csv")
+	assert.Equal(t, " exported 10 rows to out.csv ", grid.GetTitle())
+
+	grid.SetStatus("")
+	assert.Equal(t, "", grid.GetTitle())
+}
+
+func TestGridColumnCursor(t *testing.T) {
+	grid := NewGrid([]string{"ID", "Name", "Status"}, []db.TableData{
+		map[string]string{"ID": "1", "Name": "John", "Status": "Active"},
+	})
+	assert.Equal(t, 0, grid.SelectedColumn())
+
+	grid.SelectColumn(2)
+	assert.Equal(t, 2, grid.SelectedColumn())
+	assert.True(t, headerUnderlined(grid, 2))
+	assert.False(t, headerUnderlined(grid, 0))
+
+	// out of range columns are ignored
+	grid.SelectColumn(3)
+	assert.Equal(t, 2, grid.SelectedColumn())
+
+	// left and right move the cursor
+	grid.GetInputCapture()(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone))
+	assert.Equal(t, 1, grid.SelectedColumn())
+
+	// repopulating with fewer columns resets the cursor
+	grid.Populate([]string{"ID"}, nil)
+	assert.Equal(t, 0, grid.SelectedColumn())
+}
+
+// headerUnderlined reports whether the header cell of a column is underlined
+func headerUnderlined(grid *Grid, column int) bool {
+	cell := grid.GetCell(0, column)
+	_, _, attributes := cell.Style.Decompose()
+	return (cell.Attributes|attributes)&tcell.AttrUnderline != 0
+}
+
+func TestGridMarkDiff(t *testing.T) {
+	headers := []string{"DIFF", "id", "name"}
+	grid := NewGrid(headers, []db.TableData{
+		map[string]string{"DIFF": "~", "id": "2", "name": "bob → rob"},
+		map[string]string{"DIFF": "+", "id": "4", "name": "dee"},
+		map[string]string{"DIFF": "-", "id": "3", "name": "cy"},
+	})
+
+	grid.MarkDiff(headers, []model.RowDiff{
+		{Kind: model.DiffChanged, Changed: map[string]bool{"name": true}},
+		{Kind: model.DiffAdded},
+		{Kind: model.DiffRemoved},
+	})
+
+	textColor := func(row, col int) tcell.Color {
+		color, _, _ := grid.GetCell(row, col).Style.Decompose()
+		return color
+	}
+	background := func(row, col int) tcell.Color {
+		_, color, _ := grid.GetCell(row, col).Style.Decompose()
+		return color
+	}
+	assert.Equal(t, Colors.DiffChanged, textColor(1, 1))
+	assert.Equal(t, Colors.DiffChangedCell, background(1, 2))
+	assert.NotEqual(t, Colors.DiffChangedCell, background(1, 1))
+	assert.Equal(t, Colors.DiffAdded, textColor(2, 0))
+	assert.Equal(t, Colors.DiffRemoved, textColor(3, 2))
+	assert.Equal(t, Colors.TextWhite, textColor(0, 0))
+}
+
+func TestGridRefresh(t *testing.T) {
+	headers := []string{"id", "status"}
+	grid := NewGrid(headers, []db.TableData{
+		map[string]string{"id": "1", "status": "queued"},
+		map[string]string{"id": "2", "status": "queued"},
+	})
+	grid.Select(2, 0)
+	grid.SelectColumn(1)
+
+	changed := grid.Refresh(headers, []db.TableData{
+		map[string]string{"id": "1", "status": "queued"},
+		map[string]string{"id": "2", "status": "running"},
+		map[string]string{"id": "3", "status": "queued"},
+	})
+
+	background := func(row, col int) tcell.Color {
+		_, color, _ := grid.GetCell(row, col).Style.Decompose()
+		return color
+	}
+	assert.True(t, changed)
+	row, _ := grid.GetSelection()
+	assert.Equal(t, 2, row)
+	assert.Equal(t, 1, grid.SelectedColumn())
+	assert.Equal(t, Colors.WatchFlash, background(2, 1))
+	assert.Equal(t, Colors.WatchFlash, background(3, 0))
+	assert.NotEqual(t, Colors.WatchFlash, background(1, 1))
+	assert.NotEqual(t, Colors.WatchFlash, background(2, 0))
+
+	grid.ClearFlash()
+	assert.NotEqual(t, Colors.WatchFlash, background(2, 1))
+
+	// fewer rows keep the selection in range, nothing changed
+	assert.False(t, grid.Refresh(headers, []db.TableData{map[string]string{"id": "1", "status": "queued"}}))
+	row, _ = grid.GetSelection()
+	assert.Equal(t, 1, row)
+}
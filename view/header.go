@@ -1,8 +1,12 @@
 package view
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/rivo/tview"
 	"rel8/config"
+	"rel8/model"
 )
 
 // Header wraps a Flex with header-specific functionality
@@ -11,6 +15,13 @@ type Header struct {
 	leftHeader  *tview.TextView
 	keys        *Keys
 	rightHeader *tview.TextView
+	// context lines and server metrics lines of the left header
+	contextText string
+	metricsText string
+	// badge shown after the first line, such as replication lag
+	badge string
+	// progress of background work shown after the second line, such as an import
+	progress string
 }
 
 // NewHeader creates a new header with proper configuration
@@ -18,16 +29,6 @@ func NewHeader() *Header {
 	leftHeader := tview.NewTextView().
 		SetDynamicColors(true).
 		SetWrap(false)
-
-	headerText := ` [` + Colors.HeaderLabel + `]Context: [` + Colors.HeaderValue + `]dev[-]
- [` + Colors.HeaderLabel + `]Cluster: [` + Colors.HeaderValue + `]arn:aws:eks:us-east-1:897436064625:cluster/dev[-]
- [` + Colors.HeaderLabel + `]Svc: [` + Colors.HeaderValue + `]arn:aws:eks:us-east-1:897436064625:cluster/dev[-]
- [` + Colors.HeaderLabel + `]K9s Rev: [` + Colors.HeaderValue + `]v0.40.10 [` + Colors.HeaderSecondary + `](v0.50.9)[-]
- [` + Colors.HeaderLabel + `]K8s Rev: [` + Colors.HeaderValue + `]v1.28.15-eks-6096722[-]
- [` + Colors.HeaderLabel + `]CPU: [` + Colors.HeaderHighlight + `]8%[-]
- [` + Colors.HeaderLabel + `]MEM: [` + Colors.HeaderHighlight + `]8%[-]`
-
-	leftHeader.SetText(headerText)
 	leftHeader.SetBackgroundColor(Colors.BackgroundDefault)
 
 	rightHeader := tview.NewTextView().
@@ -49,25 +50,75 @@ func NewHeader() *Header {
 		AddItem(rightHeader, 0, 1, false).
 		AddItem(nil, 1, 0, false)
 
-	return &Header{
+	header := &Header{
 		Flex:        headerFlex,
 		leftHeader:  leftHeader,
 		keys:        keys,
 		rightHeader: rightHeader,
 	}
+	header.UpdateContext("dev", "arn:aws:eks:us-east-1:897436064625:cluster/dev",
+		"arn:aws:eks:us-east-1:897436064625:cluster/dev", "v0.40.10 ["+Colors.HeaderSecondary+"](v0.50.9)", "v1.28.15-eks-6096722")
+	// metrics show once the server status was read twice
+	header.metricsText = formatMetrics("-", "-")
+	header.render()
+	return header
 }
 
 // UpdateContext updates the context information in the header
-func (h *Header) UpdateContext(context, cluster, service, k9sRev, k8sRev, cpu, mem string) {
-	headerText := ` [` + Colors.HeaderLabel + `]Context: [` + Colors.HeaderValue + `]` + context + `[-]
+func (h *Header) UpdateContext(context, cluster, service, k9sRev, k8sRev string) {
+	h.contextText = ` [` + Colors.HeaderLabel + `]Context: [` + Colors.HeaderValue + `]` + context + `[-]
  [` + Colors.HeaderLabel + `]Cluster: [` + Colors.HeaderValue + `]` + cluster + `[-]
  [` + Colors.HeaderLabel + `]Svc: [` + Colors.HeaderValue + `]` + service + `[-]
  [` + Colors.HeaderLabel + `]K9s Rev: [` + Colors.HeaderValue + `]` + k9sRev + `[-]
- [` + Colors.HeaderLabel + `]K8s Rev: [` + Colors.HeaderValue + `]` + k8sRev + `[-]
- [` + Colors.HeaderLabel + `]CPU: [` + Colors.HeaderHighlight + `]` + cpu + `[-]
- [` + Colors.HeaderLabel + `]MEM: [` + Colors.HeaderHighlight + `]` + mem + `[-]`
+ [` + Colors.HeaderLabel + `]K8s Rev: [` + Colors.HeaderValue + `]` + k8sRev + `[-]`
+	h.render()
+}
 
-	h.leftHeader.SetText(headerText)
+// UpdateMetrics shows the rates of the server, queries a second with the running threads and the buffer pool hit rate
+func (h *Header) UpdateMetrics(metrics model.ServerMetrics) {
+	queries := fmt.Sprintf("%.1f [%s](%s running)", metrics.QPS, Colors.HeaderSecondary, metrics.ThreadsRunning)
+	hitRate := "-"
+	if metrics.BufferHitRate >= 0 {
+		hitRate = fmt.Sprintf("%.1f%%", metrics.BufferHitRate)
+	}
+	h.metricsText = formatMetrics(queries, hitRate)
+	h.render()
+}
+
+// UpdateReplication shows a replication problem as a red badge, an empty problem removes it
+func (h *Header) UpdateReplication(problem string) {
+	h.badge = ""
+	if problem != "" {
+		h.badge = "[white:" + Colors.HeaderAlert + ":b] " + strings.ToUpper(problem) + " [-:-:-]"
+	}
+	h.render()
+}
+
+// UpdateProgress shows the progress of background work such as an import, an empty text removes it
+func (h *Header) UpdateProgress(text string) {
+	h.progress = ""
+	if text != "" {
+		h.progress = "[" + Colors.HeaderHighlight + "]" + text + "[-]"
+	}
+	h.render()
+}
+
+// formatMetrics lays out the metrics lines of the header
+func formatMetrics(queries string, hitRate string) string {
+	return ` [` + Colors.HeaderLabel + `]QPS: [` + Colors.HeaderHighlight + `]` + queries + `[-]
+ [` + Colors.HeaderLabel + `]Buffer hit: [` + Colors.HeaderHighlight + `]` + hitRate + `[-]`
+}
+
+// render shows the context and metrics lines with the badge after the first line and the progress after the second
+func (h *Header) render() {
+	lines := strings.Split(h.contextText+"\n"+h.metricsText, "\n")
+	if h.badge != "" {
+		lines[0] += "  " + h.badge
+	}
+	if h.progress != "" && len(lines) > 1 {
+		lines[1] += "  " + h.progress
+	}
+	h.leftHeader.SetText(strings.Join(lines, "\n"))
 }
 
 // UpdateKeys updates the keys display in the middle section
@@ -1,10 +1,12 @@
 package view
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
+	"rel8/model"
 )
 
 func TestNewHeader(t *testing.T) {
@@ -32,8 +34,6 @@ func TestHeaderUpdateContext(t *testing.T) {
 		"test-service",
 		"v1.0.0",
 		"v1.28.0",
-		"15%",
-		"25%",
 	)
 
 	// We can't easily test the internal text content due to tview's private fields,
@@ -41,6 +41,43 @@ func TestHeaderUpdateContext(t *testing.T) {
 	assert.NotNil(t, header.leftHeader)
 }
 
+func TestHeaderUpdateMetrics(t *testing.T) {
+	header := NewHeader()
+	assert.Contains(t, header.leftHeader.GetText(true), "QPS: -\n Buffer hit: -")
+
+	header.UpdateMetrics(model.ServerMetrics{QPS: 12, ThreadsRunning: "3", BufferHitRate: 99.75})
+	text := header.leftHeader.GetText(true)
+	assert.Contains(t, text, "Context: dev")
+	assert.Contains(t, text, "QPS: 12.0 (3 running)\n Buffer hit: 99.8%")
+
+	// nothing read from the buffer pool
+	header.UpdateMetrics(model.ServerMetrics{QPS: 0.5, ThreadsRunning: "1", BufferHitRate: -1})
+	assert.Contains(t, header.leftHeader.GetText(true), "QPS: 0.5 (1 running)\n Buffer hit: -")
+}
+
+func TestHeaderUpdateReplication(t *testing.T) {
+	header := NewHeader()
+	header.UpdateReplication("replica lag 12s")
+	first, _, _ := strings.Cut(header.leftHeader.GetText(true), "\n")
+	assert.Contains(t, first, "Context: dev")
+	assert.Contains(t, first, "REPLICA LAG 12S")
+
+	// the badge goes once replication is healthy again
+	header.UpdateReplication("")
+	assert.NotContains(t, header.leftHeader.GetText(true), "REPLICA LAG")
+}
+
+func TestHeaderUpdateProgress(t *testing.T) {
+	header := NewHeader()
+	header.UpdateProgress("importing 500/2000 rows into people")
+	lines := strings.Split(header.leftHeader.GetText(true), "\n")
+	assert.Contains(t, lines[1], "Cluster:")
+	assert.Contains(t, lines[1], "importing 500/2000 rows into people")
+
+	header.UpdateProgress("")
+	assert.NotContains(t, header.leftHeader.GetText(true), "importing")
+}
+
 func TestHeaderUpdateArt(t *testing.T) {
 	header := NewHeader()
 
@@ -0,0 +1,93 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"rel8/db"
+)
+
+// PlanTree shows a query plan as a tree, Enter collapses and expands a step
+type PlanTree struct {
+	*tview.TreeView
+}
+
+// NewPlanTree creates a new empty plan tree
+func NewPlanTree() *PlanTree {
+	tree := tview.NewTreeView().SetGraphicsColor(Colors.BorderDefault)
+	tree.SetBackgroundColor(Colors.BackgroundDefault)
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	// Add same border styling as table
+	tree.SetBorder(true).SetBorderPadding(0, 0, 1, 1).SetBorderColor(Colors.BorderDefault)
+	tree.SetBorderAttributes(tcell.AttrNone)
+
+	return &PlanTree{TreeView: tree}
+}
+
+// Populate replaces the tree with the steps of plan, all expanded, and shows the status in the border
+func (p *PlanTree) Populate(plan *db.PlanNode, status string) {
+	if status == "" {
+		p.SetTitle("")
+	} else {
+		p.SetTitle(" " + status + ", enter collapses ")
+	}
+	if plan == nil {
+		p.SetRoot(nil).SetCurrentNode(nil)
+		return
+	}
+	root := newPlanTreeNode(*plan)
+	p.SetRoot(root).SetCurrentNode(root)
+}
+
+// newPlanTreeNode creates the tree node of a plan step and its children, full table scans stand out
+func newPlanTreeNode(step db.PlanNode) *tview.TreeNode {
+	node := tview.NewTreeNode(formatPlanStep(step)).
+		SetColor(Colors.PlanStep).
+		SetSelectable(true).
+		SetExpanded(true)
+	if step.FullScan {
+		node.SetColor(Colors.PlanFullScan)
+	}
+	for _, child := range step.Children {
+		node.AddChild(newPlanTreeNode(child))
+	}
+	return node
+}
+
+// formatPlanStep formats a plan step on one line, such as table users  access=ALL  rows=50  cost=5.25
+func formatPlanStep(step db.PlanNode) string {
+	parts := []string{step.Operation}
+	if step.Table != "" && !strings.Contains(step.Operation, step.Table) {
+		parts[0] += " " + step.Table
+	}
+	for _, field := range []struct{ name, value string }{
+		{"access", step.Access},
+		{"index", step.Index},
+		{"rows", step.Rows},
+		{"cost", step.Cost},
+	} {
+		if field.value != "" {
+			parts = append(parts, field.name+"="+field.value)
+		}
+	}
+	if step.FullScan {
+		parts = append(parts, "FULL SCAN")
+	}
+	if step.Detail != "" {
+		parts = append(parts, "("+step.Detail+")")
+	}
+	return strings.Join(parts, "  ")
+}
+
+// WrapPlanTree wraps plan tree with padding (only left/right, NO top/bottom)
+func WrapPlanTree(tree *PlanTree) *tview.Flex {
+	return tview.NewFlex().
+		SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 0, false). // Left padding
+		AddItem(tree, 0, 1, true).
+		AddItem(nil, 0, 0, false) // Right padding
+}
@@ -0,0 +1,79 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+)
+
+func TestPlanTreePopulate(t *testing.T) {
+	tree := NewPlanTree()
+	plan := &db.PlanNode{
+		Operation: "query block #1",
+		Cost:      "12.50",
+		Children: []db.PlanNode{
+			{Operation: "table", Table: "users", Access: "ALL", Rows: "50", FullScan: true},
+			{Operation: "table", Table: "orders", Access: "ref", Index: "idx_user_id"},
+		},
+	}
+
+	tree.Populate(plan, "plan of SELECT 1, 1 full table scan")
+	assert.Equal(t, " plan of SELECT 1, 1 full table scan, enter collapses ", tree.GetTitle())
+
+	root := tree.GetRoot()
+	assert.Equal(t, "query block #1  cost=12.50", root.GetText())
+	assert.Equal(t, root, tree.GetCurrentNode())
+	children := root.GetChildren()
+	assert.Len(t, children, 2)
+	assert.Equal(t, Colors.PlanFullScan, children[0].GetColor())
+	assert.Equal(t, Colors.PlanStep, children[1].GetColor())
+
+	// enter collapses and expands the current step
+	enter := tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+	tree.InputHandler()(enter, func(tview.Primitive) {})
+	assert.False(t, root.IsExpanded())
+	tree.InputHandler()(enter, func(tview.Primitive) {})
+	assert.True(t, root.IsExpanded())
+
+	tree.Populate(nil, "explain failed: boom")
+	assert.Nil(t, tree.GetRoot())
+}
+
+func TestFormatPlanStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     db.PlanNode
+		expected string
+	}{
+		{
+			name:     "full scan",
+			step:     db.PlanNode{Operation: "table", Table: "users", Access: "ALL", Rows: "50", Cost: "5.25", FullScan: true, Detail: "(active = 1)"},
+			expected: "table users  access=ALL  rows=50  cost=5.25  FULL SCAN  ((active = 1))",
+		},
+		{
+			name:     "table named in the operation",
+			step:     db.PlanNode{Operation: "SEARCH u USING INDEX idx (id=?)", Table: "u", Access: "SEARCH", Index: "idx"},
+			expected: "SEARCH u USING INDEX idx (id=?)  access=SEARCH  index=idx",
+		},
+		{
+			name:     "operation only",
+			step:     db.PlanNode{Operation: "nested loop"},
+			expected: "nested loop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatPlanStep(tt.step))
+		})
+	}
+}
+
+func TestWrapPlanTree(t *testing.T) {
+	wrapped := WrapPlanTree(NewPlanTree())
+	assert.IsType(t, &tview.Flex{}, wrapped)
+	assert.Equal(t, 3, wrapped.GetItemCount())
+}
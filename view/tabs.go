@@ -0,0 +1,107 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+	"rel8/model"
+)
+
+// Tabs wraps a Flex with a tab strip above the pages of a tabbed state
+type Tabs struct {
+	*tview.Flex
+	strip *tview.TextView
+	pages *tview.Pages
+	items []tview.Primitive
+	// grid of each tab showing table data, nil for text tabs
+	grids []*Grid
+}
+
+// NewTabs creates a new empty tabbed view
+func NewTabs() *Tabs {
+	strip := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	strip.SetBackgroundColor(Colors.BackgroundDefault)
+
+	pages := tview.NewPages()
+	pages.SetBackgroundColor(Colors.BackgroundDefault)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(strip, 1, 0, false).
+		AddItem(pages, 0, 1, true)
+
+	return &Tabs{Flex: flex, strip: strip, pages: pages}
+}
+
+// Populate rebuilds tabs from the state, showing the selected one
+func (t *Tabs) Populate(tabs []model.Tab, selected int) {
+	for _, name := range t.pages.GetPageNames(false) {
+		t.pages.RemovePage(name)
+	}
+	t.items = nil
+	t.grids = nil
+
+	for i, tab := range tabs {
+		var item tview.Primitive
+		var grid *Grid
+		if tab.TableHeaders != nil {
+			grid = NewGrid(tab.TableHeaders, tab.TableData)
+			item = grid.Table
+		} else {
+			item = NewDetail(tab.DetailText).TextView
+		}
+		t.items = append(t.items, item)
+		t.grids = append(t.grids, grid)
+		t.pages.AddPage(fmt.Sprintf("%d", i), item, true, i == selected)
+	}
+
+	t.strip.SetText(formatTabStrip(tabs, selected))
+}
+
+// Current returns the primitive of the visible tab or nil when there are no tabs
+func (t *Tabs) Current() tview.Primitive {
+	name, _ := t.pages.GetFrontPage()
+	for i, item := range t.items {
+		if fmt.Sprintf("%d", i) == name {
+			return item
+		}
+	}
+	return nil
+}
+
+// Selection returns the selected row and column of the visible tab, 0 and 0 for a text tab
+func (t *Tabs) Selection() (int, int) {
+	name, _ := t.pages.GetFrontPage()
+	for i, grid := range t.grids {
+		if fmt.Sprintf("%d", i) == name && grid != nil {
+			row, _ := grid.GetSelection()
+			return row, grid.SelectedColumn()
+		}
+	}
+	return 0, 0
+}
+
+// formatTabStrip renders tab titles, highlighting the selected one
+func formatTabStrip(tabs []model.Tab, selected int) string {
+	var titles []string
+	for i, tab := range tabs {
+		if i == selected {
+			titles = append(titles, fmt.Sprintf("[%s::b]<%s>[-::-]", Colors.TabActive, tab.Title))
+		} else {
+			titles = append(titles, fmt.Sprintf("[%s] %s [-]", Colors.TabInactive, tab.Title))
+		}
+	}
+	return " " + strings.Join(titles, " ")
+}
+
+// WrapTabs wraps tabs with padding (only left/right, NO top/bottom)
+func WrapTabs(tabs *Tabs) *tview.Flex {
+	return tview.NewFlex().
+		SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 0, false). // Left padding
+		AddItem(tabs.Flex, 0, 1, true).
+		AddItem(nil, 0, 0, false) // Right padding
+}
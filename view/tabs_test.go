@@ -0,0 +1,46 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"rel8/db"
+	"rel8/model"
+)
+
+func TestTabsPopulate(t *testing.T) {
+	tabs := NewTabs()
+	assert.Nil(t, tabs.Current())
+
+	tabs.Populate([]model.Tab{
+		{
+			Title:        "Columns",
+			TableHeaders: []string{"NAME", "TYPE"},
+			TableData:    []db.TableData{db.MysqlColumn{Name: "id", Type: "int"}},
+		},
+		{Title: "DDL", DetailText: "CREATE TABLE t (id INT)"},
+	}, 1)
+
+	current := tabs.Current()
+	assert.IsType(t, &tview.TextView{}, current)
+	assert.Contains(t, current.(*tview.TextView).GetText(true), "CREATE TABLE t")
+
+	tabs.Populate([]model.Tab{
+		{Title: "Columns", TableHeaders: []string{"NAME"}, TableData: []db.TableData{}},
+	}, 0)
+	assert.IsType(t, &tview.Table{}, tabs.Current())
+}
+
+func TestFormatTabStrip(t *testing.T) {
+	strip := formatTabStrip([]model.Tab{{Title: "Columns"}, {Title: "DDL"}}, 1)
+
+	assert.Contains(t, strip, "<DDL>")
+	assert.NotContains(t, strip, "<Columns>")
+	assert.Contains(t, strip, " Columns ")
+}
+
+func TestWrapTabs(t *testing.T) {
+	wrapped := WrapTabs(NewTabs())
+	assert.IsType(t, &tview.Flex{}, wrapped)
+}
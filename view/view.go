@@ -5,17 +5,27 @@ import (
 	"github.com/rivo/tview"
 	"log/slog"
 	"rel8/model"
+	"time"
 )
 
+// flashDuration is how long cells changed by a watch refresh stay highlighted
+const flashDuration = 700 * time.Millisecond
+
 type View struct {
 	stateManager *model.ContextualStateManager
 	model        *model.State
 	App          *tview.Application
+	screen       tcell.Screen
 	flex         *tview.Flex
 	header       *Header
 	grid         *Grid
+	splitGrid    *Grid
+	resultStrip  *tview.TextView
 	details      *Detail
 	editor       *Editor
+	tabs         *Tabs
+	form         *Form
+	planTree     *PlanTree
 	commandBar   *CommandBar
 }
 
@@ -26,8 +36,16 @@ func NewView(stateManager *model.ContextualStateManager) *View {
 	header := NewHeader()
 	details := NewEmptyDetail()
 	editor := NewEmptyEditor()
+	tabs := NewTabs()
+	form := NewForm()
+	planTree := NewPlanTree()
 
 	grid := NewEmptyGrid()
+	splitGrid := NewEmptyGrid()
+	resultStrip := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	resultStrip.SetBackgroundColor(Colors.BackgroundDefault)
 
 	// Create command bar (initially hidden)
 	commandBar := NewCommandBar()
@@ -45,14 +63,59 @@ func NewView(stateManager *model.ContextualStateManager) *View {
 		flex:         flex,
 		header:       header,
 		grid:         grid,
+		splitGrid:    splitGrid,
+		resultStrip:  resultStrip,
 		details:      details,
 		editor:       editor,
+		tabs:         tabs,
+		form:         form,
+		planTree:     planTree,
 		commandBar:   commandBar,
 	}
 
+	stateManager.SetClipboardWriter(view.copyToClipboard)
+	stateManager.SetProgressListener(view.header.UpdateProgress)
+	stateManager.SetUpdateQueue(func(update func()) {
+		app.QueueUpdateDraw(update)
+	})
+	editor.SetClipboardWriter(view.copyToClipboard)
+
 	return view
 }
 
+// SetScreen sets the terminal screen used by the application, tests pass a simulation screen
+func (v *View) SetScreen(screen tcell.Screen) {
+	v.screen = screen
+	v.App.SetScreen(screen)
+}
+
+// copyToClipboard posts text to the terminal clipboard using the OSC 52 escape sequence,
+// which works over SSH without a local clipboard tool
+func (v *View) copyToClipboard(text string) {
+	if v.screen == nil {
+		slog.Warn("copy to clipboard without a screen")
+		return
+	}
+	v.screen.SetClipboard([]byte(text))
+}
+
+// UpdateMetrics shows the rates of the server in the header
+func (v *View) UpdateMetrics(metrics model.ServerMetrics) {
+	v.header.UpdateMetrics(metrics)
+}
+
+// UpdateReplication shows a replication problem as a badge in the header, on every screen
+func (v *View) UpdateReplication(problem string) {
+	v.header.UpdateReplication(problem)
+}
+
+// isWatchRefresh reports whether a transition updates the rows of a watched result shown in the grid
+func isWatchRefresh(transition model.StateTransition) bool {
+	from, to := transition.From, transition.To
+	return from.Mode == model.Browse && from.WatchInterval > 0 && to.WatchInterval > 0 &&
+		from.TableMode == to.TableMode && from.Query == to.Query
+}
+
 // New Notify process events - inspect model and redraw
 func (v *View) OnStateTransition(transition model.StateTransition) {
 	//todo take address?
@@ -63,15 +126,41 @@ func (v *View) OnStateTransition(transition model.StateTransition) {
 	}
 
 	if transition.To.Mode == model.Browse {
-		// repopulate grid without recreating it
-		v.grid.Populate(transition.To.TableHeaders, transition.To.TableData)
+		if isWatchRefresh(transition) {
+			// rows of a watched query replaced in place, changed cells flash briefly
+			if v.grid.Refresh(transition.To.TableHeaders, transition.To.TableData) {
+				time.AfterFunc(flashDuration, func() {
+					v.App.QueueUpdateDraw(v.grid.ClearFlash)
+				})
+			}
+		} else {
+			// repopulate grid without recreating it
+			v.grid.Populate(transition.To.TableHeaders, transition.To.TableData)
+			v.grid.MarkDiff(transition.To.TableHeaders, transition.To.Diff)
 
-		// Restore the selected row if one was saved
-		v.grid.RestoreSelection(transition.To.SelectedDataIndex, len(transition.To.TableData))
+			// Restore the selected row if one was saved
+			v.grid.RestoreSelection(transition.To.SelectedDataIndex, len(transition.To.TableData))
+		}
+		v.grid.SetStatus(transition.To.StatusText)
 
 		v.flex.Clear()
 		v.flex.AddItem(WrapHeader(v.header), 7, 0, false)
-		v.flex.AddItem(WrapGrid(v.grid), 0, 1, true)
+		// strip of query results to switch between
+		if len(transition.To.ResultTabs) > 0 {
+			v.resultStrip.SetText(formatTabStrip(transition.To.ResultTabs, transition.To.SelectedResult))
+			v.flex.AddItem(v.resultStrip, 1, 0, false)
+		}
+		// another result side by side
+		if split := transition.To.SplitResult; split != nil {
+			v.splitGrid.Populate(split.TableHeaders, split.TableData)
+			v.splitGrid.SetStatus(split.Title)
+			v.flex.AddItem(tview.NewFlex().
+				SetDirection(tview.FlexColumn).
+				AddItem(WrapGrid(v.grid), 0, 1, true).
+				AddItem(WrapGrid(v.splitGrid), 0, 1, false), 0, 1, true)
+		} else {
+			v.flex.AddItem(WrapGrid(v.grid), 0, 1, true)
+		}
 		v.App.SetFocus(v.grid)
 	}
 
@@ -83,6 +172,16 @@ func (v *View) OnStateTransition(transition model.StateTransition) {
 		v.App.SetFocus(v.details)
 	}
 
+	if transition.To.Mode == model.Tabbed {
+		v.tabs.Populate(transition.To.Tabs, transition.To.SelectedTab)
+		v.flex.Clear()
+		v.flex.AddItem(WrapHeader(v.header), 7, 0, false)
+		v.flex.AddItem(WrapTabs(v.tabs), 0, 1, true)
+		if current := v.tabs.Current(); current != nil {
+			v.App.SetFocus(current)
+		}
+	}
+
 	if transition.To.Mode == model.Command {
 		// Show command bar between header and table
 		v.commandBar.Show()
@@ -103,7 +202,28 @@ func (v *View) OnStateTransition(transition model.StateTransition) {
 		v.App.SetFocus(v.commandBar)
 	}
 
+	if transition.To.Mode == model.Form {
+		v.form.Populate(transition.To.StatusText, transition.To.FormFields)
+		v.flex.Clear()
+		v.flex.AddItem(WrapHeader(v.header), 7, 0, false)
+		v.flex.AddItem(WrapForm(v.form), 0, 1, true)
+		v.App.SetFocus(v.form)
+	}
+
+	if transition.To.Mode == model.Plan {
+		v.planTree.Populate(transition.To.Plan, transition.To.StatusText)
+		v.flex.Clear()
+		v.flex.AddItem(WrapHeader(v.header), 7, 0, false)
+		v.flex.AddItem(WrapPlanTree(v.planTree), 0, 1, true)
+		v.App.SetFocus(v.planTree)
+	}
+
 	if transition.To.Mode == model.Editor {
+		// load text such as a saved query, returning to the editor finds its text unchanged
+		if text := transition.To.EditorText; text != "" && text != v.editor.GetText() {
+			v.editor.ReplaceText(text)
+		}
+		v.editor.SetStatus(transition.To.StatusText)
 		v.flex.Clear()
 		v.flex.AddItem(WrapHeader(v.header), 7, 0, false)
 		v.flex.AddItem(WrapEditor(v.editor), 0, 1, true)
@@ -129,9 +249,27 @@ func (v *View) Run() {
 		if currentState.Mode == model.SQL {
 			e.Text = v.commandBar.GetCommand()
 		}
-		// if in editor mode also send editor text
+		// in editor mode Ctrl-O and Alt-O hand the text to $EDITOR, otherwise also send editor text
 		if currentState.Mode == model.Editor {
+			if edit, run := isExternalEditKey(event); edit {
+				v.editExternally(run)
+				return nil
+			}
 			e.Text = v.editor.GetText()
+			_, e.Cursor, _ = v.editor.GetSelection()
+		}
+		// if in form mode also send the entered values
+		if currentState.Mode == model.Form {
+			e.Values = v.form.Values()
+		}
+		// with a split Tab moves the focus to the other result
+		if currentState.Mode == model.Browse && currentState.SplitResult != nil && event.Key() == tcell.KeyTab {
+			if v.grid.HasFocus() {
+				v.App.SetFocus(v.splitGrid)
+			} else {
+				v.App.SetFocus(v.grid)
+			}
+			return nil
 		}
 		// if in browse mode also send current row
 		if currentState.Mode == model.Browse {
@@ -139,12 +277,27 @@ func (v *View) Run() {
 			row, _ := v.grid.GetSelection()
 			slog.Info("sending row:", "row", row)
 			e.Row = row
+			e.Column = v.grid.SelectedColumn()
+		}
+		// in tabbed mode send the row and column of the visible tab
+		if currentState.Mode == model.Tabbed {
+			e.Row, e.Column = v.tabs.Selection()
 		}
 
 		//todo this is a single place that requires state manager. Replace with a function
 		return v.stateManager.HandleEvent(e)
 	})
 
+	// Create the screen up front so clipboard writes can reach it
+	if v.screen == nil {
+		screen, err := tcell.NewScreen()
+		if err != nil {
+			slog.Error("Error creating screen", "error", err)
+			return
+		}
+		v.SetScreen(screen)
+	}
+
 	// Run the application
 	if err := v.App.SetRoot(v.flex, true).SetFocus(v.grid).Run(); err != nil {
 		slog.Error("Error running tview app", "error", err)
@@ -2,8 +2,11 @@ package view
 
 import (
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
 	"rel8/db"
 	"rel8/model"
@@ -169,3 +172,115 @@ func TestViewOnStateTransitionDetailMode(t *testing.T) {
 	assert.Equal(t, model.Detail, view.model.Mode)
 	assert.Equal(t, "CREATE TABLE test (id INT)", view.model.DetailText)
 }
+
+func TestViewOnStateTransitionEditorText(t *testing.T) {
+	stateManager := model.NewContextualStateManager(&db.MysqlMock{}, model.State{Mode: model.Browse}, 10)
+	view := NewView(stateManager)
+
+	view.OnStateTransition(model.StateTransition{To: model.State{Mode: model.Editor, EditorText: "SELECT 1"}})
+	assert.Equal(t, "SELECT 1", view.editor.GetText())
+
+	// typed text is kept when the state has none
+	view.editor.SetText("SELECT 2", true)
+	view.OnStateTransition(model.StateTransition{To: model.State{Mode: model.Editor, StatusText: "saved query two"}})
+	assert.Equal(t, "SELECT 2", view.editor.GetText())
+	assert.Equal(t, " saved query two ", view.editor.GetTitle())
+}
+
+func TestViewOnStateTransitionForm(t *testing.T) {
+	stateManager := model.NewContextualStateManager(&db.MysqlMock{}, model.State{Mode: model.Browse}, 10)
+	view := NewView(stateManager)
+
+	view.OnStateTransition(model.StateTransition{To: model.State{
+		Mode:       model.Form,
+		StatusText: "parameters of orders",
+		FormFields: []model.FormField{{Label: "user_id", Value: "1"}},
+	}})
+	assert.Equal(t, []string{"1"}, view.form.Values())
+	assert.Equal(t, " parameters of orders, enter to submit ", view.form.GetTitle())
+}
+
+func TestViewOnStateTransitionResults(t *testing.T) {
+	stateManager := model.NewContextualStateManager(&db.MysqlMock{}, model.State{Mode: model.Browse}, 10)
+	view := NewView(stateManager)
+
+	result := model.State{
+		Mode:         model.Browse,
+		TableMode:    model.TableRow,
+		TableHeaders: []string{"id"},
+		TableData:    []db.TableData{map[string]string{"id": "1"}},
+	}
+	view.OnStateTransition(model.StateTransition{To: result})
+	assert.Equal(t, 2, view.flex.GetItemCount())
+
+	result.ResultTabs = []model.Tab{{Title: "1 SELECT 1"}, {Title: "2 SELECT 2"}}
+	result.SelectedResult = 1
+	result.SplitResult = &model.Tab{
+		Title:        "1 SELECT 1",
+		TableHeaders: []string{"name"},
+		TableData:    []db.TableData{map[string]string{"name": "a"}, map[string]string{"name": "b"}},
+	}
+	view.OnStateTransition(model.StateTransition{To: result})
+	assert.Equal(t, 3, view.flex.GetItemCount())
+	assert.Contains(t, view.resultStrip.GetText(true), "<2 SELECT 2>")
+	assert.Equal(t, 3, view.splitGrid.GetRowCount())
+	assert.Equal(t, " 1 SELECT 1 ", view.splitGrid.GetTitle())
+}
+
+func TestViewOnStateTransitionWatchRefresh(t *testing.T) {
+	stateManager := model.NewContextualStateManager(&db.MysqlMock{}, model.State{Mode: model.Browse}, 10)
+	view := NewView(stateManager)
+
+	watched := model.State{
+		Mode:          model.Browse,
+		TableMode:     model.TableRow,
+		TableHeaders:  []string{"id", "status"},
+		TableData:     []db.TableData{map[string]string{"id": "1", "status": "queued"}, map[string]string{"id": "2", "status": "queued"}},
+		Query:         "SELECT * FROM jobs",
+		WatchInterval: time.Second,
+	}
+	view.OnStateTransition(model.StateTransition{To: watched})
+	view.grid.Select(2, 0)
+
+	refreshed := watched
+	refreshed.TableData = []db.TableData{map[string]string{"id": "1", "status": "done"}, map[string]string{"id": "2", "status": "queued"}}
+	view.OnStateTransition(model.StateTransition{From: watched, To: refreshed})
+
+	// the selection stays on the row instead of the saved index
+	row, _ := view.grid.GetSelection()
+	assert.Equal(t, 2, row)
+	assert.Equal(t, "done", view.grid.GetCell(1, 1).Text)
+	_, background, _ := view.grid.GetCell(1, 1).Style.Decompose()
+	assert.Equal(t, Colors.WatchFlash, background)
+}
+
+func TestViewCopyToClipboard(t *testing.T) {
+	initialState := model.State{
+		Mode:         model.Browse,
+		TableMode:    model.TableRow,
+		TableHeaders: []string{"id", "name"},
+		TableData: []db.TableData{
+			map[string]string{"id": "1", "name": "alice"},
+		},
+	}
+	stateManager := model.NewContextualStateManager(&db.MysqlMock{}, initialState, 10)
+	view := NewView(stateManager)
+	stateManager.AddSyncCallback(view.OnStateTransition)
+
+	screen := tcell.NewSimulationScreen("")
+	view.SetScreen(screen)
+	defer screen.Fini()
+
+	for _, r := range []rune{'y', 'j'} {
+		stateManager.HandleEvent(&model.Event{Event: tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), Row: 1})
+	}
+
+	assert.Equal(t, `{"id": "1", "name": "alice"}`, string(screen.GetClipboardData()))
+	assert.Equal(t, " copied row as JSON to clipboard ", view.grid.GetTitle())
+
+	// editor copies its selection through the same clipboard
+	view.editor.SetText("SELECT 1", false)
+	view.editor.Select(0, 6)
+	view.editor.InputHandler()(tcell.NewEventKey(tcell.KeyCtrlQ, 0, tcell.ModCtrl), func(p tview.Primitive) {})
+	assert.Equal(t, "SELECT", string(screen.GetClipboardData()))
+}